@@ -0,0 +1,98 @@
+package searchx
+
+// AggregationType identifies the kind of a numeric aggregation.
+type AggregationType string
+
+const (
+	// AggStats computes the count, min, max, average and sum of a field.
+	AggStats AggregationType = "stats"
+	// AggHistogram counts values in fixed-width buckets.
+	AggHistogram AggregationType = "histogram"
+	// AggRange counts values in caller-defined ranges.
+	AggRange AggregationType = "range"
+)
+
+// AggregationRequest describes a numeric aggregation computed over the matching documents.
+type AggregationRequest struct {
+	// Type is the kind of aggregation.
+	Type AggregationType
+	// Field is the name of the numeric field to aggregate.
+	Field string
+	// Interval is the bucket width of a histogram.
+	Interval float64
+	// Ranges are the buckets of a range aggregation.
+	Ranges []AggregationRange
+}
+
+// AggregationRange is a bucket of a range aggregation, covering values from
+// From inclusive to To exclusive. A nil bound leaves that end open, as in Range.
+type AggregationRange struct {
+	// Key identifies the bucket in the results. It defaults to "from-to",
+	// with "*" standing for an open end.
+	Key string
+	// From is the inclusive lower bound, or nil for no lower bound.
+	From interface{}
+	// To is the exclusive upper bound, or nil for no upper bound.
+	To interface{}
+}
+
+// Aggregation holds the aggregations computed for a field.
+// Only the parts that were requested are set.
+type Aggregation struct {
+	// Stats is set by WithStats.
+	Stats *Stats
+	// Histogram is set by WithHistogram. It contains the non-empty buckets in ascending order.
+	Histogram []Bucket
+	// Ranges is set by WithRangeBuckets. It contains a bucket per requested range, in order.
+	Ranges []Bucket
+}
+
+// Stats summarizes the numeric values of a field.
+type Stats struct {
+	// Count is the number of values.
+	Count int64
+	// Min is the smallest value.
+	Min float64
+	// Max is the largest value.
+	Max float64
+	// Avg is the mean of the values.
+	Avg float64
+	// Sum is the sum of the values.
+	Sum float64
+}
+
+// Bucket is a histogram or range bucket.
+type Bucket struct {
+	// Key identifies the bucket.
+	Key string
+	// From is the inclusive lower bound, or nil for an open range.
+	From interface{}
+	// To is the exclusive upper bound, or nil for an open range.
+	To interface{}
+	// Count is the number of values in the bucket.
+	Count int64
+}
+
+// WithStats requests the count, min, max, average and sum of a numeric field,
+// e.g. to bound a price slider by the current result set.
+func WithStats(field string) SearchOption {
+	return optionFunc(func(cfg *SearchConfig) {
+		cfg.Aggregations = append(cfg.Aggregations, AggregationRequest{Type: AggStats, Field: field})
+	})
+}
+
+// WithHistogram requests counts of a numeric field in buckets of the given width.
+// The bucket of a value v starts at floor(v/interval)*interval.
+func WithHistogram(field string, interval float64) SearchOption {
+	return optionFunc(func(cfg *SearchConfig) {
+		cfg.Aggregations = append(cfg.Aggregations, AggregationRequest{Type: AggHistogram, Field: field, Interval: interval})
+	})
+}
+
+// WithRangeBuckets requests counts of a numeric field in the given ranges.
+// Ranges may overlap, in which case a value is counted in each of them.
+func WithRangeBuckets(field string, ranges ...AggregationRange) SearchOption {
+	return optionFunc(func(cfg *SearchConfig) {
+		cfg.Aggregations = append(cfg.Aggregations, AggregationRequest{Type: AggRange, Field: field, Ranges: ranges})
+	})
+}
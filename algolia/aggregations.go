@@ -0,0 +1,95 @@
+package algolia
+
+import (
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// checkAggregations rejects aggregations Algolia cannot compute.
+// Stats are computed from facets_stats, see facetFields. Histograms and range
+// buckets have no Algolia equivalent and return searchx.ErrNotImplemented.
+func checkAggregations(requests []searchx.AggregationRequest) error {
+	for _, req := range requests {
+		if req.Type != searchx.AggStats {
+			return errors.Wrapf(searchx.ErrNotImplemented, "Algolia does not support %s aggregations", req.Type)
+		}
+	}
+	return nil
+}
+
+// maxValuesPerFacet is the number of values requested for each facet, the
+// most Algolia returns. Facets with more values are truncated to the most
+// frequent ones.
+const maxValuesPerFacet = 1000
+
+// facetFields returns the fields to request as facets. Algolia returns
+// facets_stats for numeric facets, which provides the stats aggregations, and
+// facet counts on the distinct field provide the group counts. The fields must
+// be declared in attributesForFaceting.
+func facetFields(cfg *searchx.SearchConfig) []string {
+	var fields []string
+	add := func(field string) {
+		if !containsField(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+
+	for _, req := range cfg.Aggregations {
+		if req.Type == searchx.AggStats {
+			add(req.Field)
+		}
+	}
+	if cfg.Distinct != nil {
+		add(cfg.Distinct.Field)
+	}
+	return fields
+}
+
+// convertAggregations converts Algolia facet statistics to aggregations.
+// Algolia does not return a count with facets_stats, so it is derived from
+// the facet counts of the field. When the field has more than
+// maxValuesPerFacet values, the facet counts are truncated and the count is
+// nbHits instead, which also counts the matches without a value.
+func convertAggregations(requests []searchx.AggregationRequest, res search.QueryRes) map[string]*searchx.Aggregation {
+	aggregations := make(map[string]*searchx.Aggregation, len(requests))
+	for _, req := range requests {
+		stats := &searchx.Stats{}
+		if facetStats, ok := res.FacetsStats[req.Field]; ok {
+			stats.Min = facetStats.Min
+			stats.Max = facetStats.Max
+			stats.Avg = facetStats.Avg
+			stats.Sum = facetStats.Sum
+		}
+		if facet := res.Facets[req.Field]; len(facet) < maxValuesPerFacet {
+			for _, count := range facet {
+				stats.Count += int64(count)
+			}
+		} else {
+			stats.Count = int64(res.NbHits)
+		}
+
+		aggregations[req.Field] = &searchx.Aggregation{Stats: stats}
+	}
+	return aggregations
+}
+
+// containsField reports whether fields contains field.
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// convertGroupCounts converts the facet counts of the distinct field to group
+// counts. Only the maxValuesPerFacet most frequent groups are counted.
+func convertGroupCounts(distinct *searchx.Distinct, res search.QueryRes) map[string]int64 {
+	counts := make(map[string]int64, len(res.Facets[distinct.Field]))
+	for value, count := range res.Facets[distinct.Field] {
+		counts[value] = int64(count)
+	}
+	return counts
+}
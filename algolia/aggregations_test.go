@@ -0,0 +1,108 @@
+package algolia
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestCheckAggregations(t *testing.T) {
+	tests := map[string]struct {
+		opts        []searchx.SearchOption
+		expectedErr error
+	}{
+		"none":  {},
+		"stats": {opts: []searchx.SearchOption{searchx.WithStats("price")}},
+		"histogram": {
+			opts:        []searchx.SearchOption{searchx.WithHistogram("price", 1000)},
+			expectedErr: searchx.ErrNotImplemented,
+		},
+		"range_buckets": {
+			opts:        []searchx.SearchOption{searchx.WithStats("price"), searchx.WithRangeBuckets("price", searchx.AggregationRange{To: 1000})},
+			expectedErr: searchx.ErrNotImplemented,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &searchx.SearchConfig{}
+			for _, o := range tc.opts {
+				o.Apply(cfg)
+			}
+
+			err := checkAggregations(cfg.Aggregations)
+			if tc.expectedErr == nil && err != nil {
+				t.Fatalf("checkAggregations failed: %v", err)
+			}
+			if tc.expectedErr != nil && !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("Expected %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestFacetFields(t *testing.T) {
+	cfg := &searchx.SearchConfig{}
+	for _, o := range []searchx.SearchOption{
+		searchx.WithStats("price"),
+		searchx.WithStats("year"),
+		searchx.WithStats("price"),
+		searchx.WithDistinct("model", 1),
+	} {
+		o.Apply(cfg)
+	}
+
+	expected := []string{"price", "year", "model"}
+	if got := facetFields(cfg); !equalStrings(got, expected) {
+		t.Errorf("Expected facets %q, got %q", expected, got)
+	}
+}
+
+func TestConvertAggregations(t *testing.T) {
+	res := search.QueryRes{
+		Facets: map[string]map[string]int{
+			"price": {"18000": 2, "24500": 1},
+		},
+		FacetsStats: map[string]search.FacetStat{
+			"price": {Min: 18000, Max: 24500, Avg: 20166.67, Sum: 60500},
+		},
+	}
+
+	aggregations := convertAggregations([]searchx.AggregationRequest{
+		{Type: searchx.AggStats, Field: "price"},
+		{Type: searchx.AggStats, Field: "year"},
+	}, res)
+
+	expected := searchx.Stats{Count: 3, Min: 18000, Max: 24500, Avg: 20166.67, Sum: 60500}
+	if got := aggregations["price"].Stats; got == nil || *got != expected {
+		t.Errorf("Expected price stats %+v, got %+v", expected, got)
+	}
+	if got := aggregations["year"].Stats; got == nil || *got != (searchx.Stats{}) {
+		t.Errorf("Expected empty year stats, got %+v", got)
+	}
+
+	// Truncated facets count all the matches
+	truncated := make(map[string]int, maxValuesPerFacet)
+	for i := 0; i < maxValuesPerFacet; i++ {
+		truncated[fmt.Sprint(i)] = 1
+	}
+	res = search.QueryRes{NbHits: 5000, Facets: map[string]map[string]int{"mileage": truncated}}
+	aggregations = convertAggregations([]searchx.AggregationRequest{{Type: searchx.AggStats, Field: "mileage"}}, res)
+	if got := aggregations["mileage"].Stats.Count; got != 5000 {
+		t.Errorf("Expected mileage count 5000, got %d", got)
+	}
+}
+
+func TestSearchWithUnsupportedAggregation(t *testing.T) {
+	client := NewClient(StaticSecrets("test-app", "test-key"))
+	searcher := NewSearcher(client, "test-index")
+
+	_, err := searcher.Search(context.Background(), "toyota", searchx.WithHistogram("price", 1000))
+	if !errors.Is(err, searchx.ErrNotImplemented) {
+		t.Errorf("Expected ErrNotImplemented, got: %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
+	"github.com/letmevibethatforyou/searchx"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -56,6 +57,18 @@ func EnvSecrets() FetchSecrets {
 	}
 }
 
+// ObjectWriter writes objects to the indexes of an Algolia application. It is
+// implemented by Client and by inmemory.Store, which stands in for Algolia in
+// integration tests and local development.
+type ObjectWriter interface {
+	SaveObject(ctx context.Context, indexName string, object map[string]interface{}) error
+	DeleteObject(ctx context.Context, indexName string, objectID string) error
+	BatchSaveObjects(ctx context.Context, indexName string, objects []map[string]interface{}) error
+	BatchDeleteObjects(ctx context.Context, indexName string, objectIDs []string) error
+}
+
+var _ ObjectWriter = (*Client)(nil)
+
 type Client struct {
 	getClient func() (*search.Client, error)
 	tracer    trace.Tracer
@@ -111,7 +124,7 @@ func (c *Client) SaveObject(ctx context.Context, indexName string, object map[st
 
 	index := client.InitIndex(indexName)
 
-	_, err = index.SaveObject(object)
+	_, err = index.SaveObject(indexTimes(object))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, fmt.Sprintf("failed to save object to index %s", indexName))
@@ -173,7 +186,12 @@ func (c *Client) BatchSaveObjects(ctx context.Context, indexName string, objects
 
 	index := client.InitIndex(indexName)
 
-	_, err = index.SaveObjects(objects)
+	converted := make([]map[string]interface{}, len(objects))
+	for i, object := range objects {
+		converted[i] = indexTimes(object)
+	}
+
+	_, err = index.SaveObjects(converted)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, fmt.Sprintf("failed to batch save %d objects to index %s", len(objects), indexName))
@@ -216,3 +234,109 @@ func (c *Client) BatchDeleteObjects(ctx context.Context, indexName string, objec
 	span.SetStatus(codes.Ok, fmt.Sprintf("batch deleted %d objects successfully", len(objectIDs)))
 	return nil
 }
+
+func (c *Client) SaveSettings(ctx context.Context, indexName string, settings search.Settings) error {
+	ctx, span := c.tracer.Start(ctx, "algolia.save_settings",
+		trace.WithAttributes(
+			attribute.String("algolia.index_name", indexName),
+		),
+	)
+	defer span.End()
+
+	client, err := c.getClient()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get Algolia client")
+		return err
+	}
+
+	index := client.InitIndex(indexName)
+
+	_, err = index.SetSettings(settings)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to save settings for index %s", indexName))
+		return fmt.Errorf("failed to save settings for Algolia index %s: %w", indexName, err)
+	}
+
+	span.SetStatus(codes.Ok, "settings saved successfully")
+	return nil
+}
+
+func (c *Client) SaveSynonyms(ctx context.Context, indexName string, synonyms []searchx.Synonym) error {
+	if len(synonyms) == 0 {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "algolia.save_synonyms",
+		trace.WithAttributes(
+			attribute.String("algolia.index_name", indexName),
+			attribute.Int("algolia.synonym_count", len(synonyms)),
+		),
+	)
+	defer span.End()
+
+	converted, err := convertSynonyms(synonyms)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to convert synonyms")
+		return err
+	}
+
+	client, err := c.getClient()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get Algolia client")
+		return err
+	}
+
+	index := client.InitIndex(indexName)
+
+	_, err = index.SaveSynonyms(converted)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to save %d synonyms to index %s", len(synonyms), indexName))
+		return fmt.Errorf("failed to save synonyms to Algolia index %s: %w", indexName, err)
+	}
+
+	span.SetStatus(codes.Ok, fmt.Sprintf("saved %d synonyms successfully", len(synonyms)))
+	return nil
+}
+
+// SyncRules replaces the query rules of an index with the given rules,
+// so that rules removed from the list are deleted from Algolia.
+func (c *Client) SyncRules(ctx context.Context, indexName string, rules []searchx.Rule) error {
+	ctx, span := c.tracer.Start(ctx, "algolia.sync_rules",
+		trace.WithAttributes(
+			attribute.String("algolia.index_name", indexName),
+			attribute.Int("algolia.rule_count", len(rules)),
+		),
+	)
+	defer span.End()
+
+	converted, err := convertRules(rules)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to convert rules")
+		return err
+	}
+
+	client, err := c.getClient()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to get Algolia client")
+		return err
+	}
+
+	index := client.InitIndex(indexName)
+
+	_, err = index.ReplaceAllRules(converted)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to sync %d rules to index %s", len(rules), indexName))
+		return fmt.Errorf("failed to sync rules to Algolia index %s: %w", indexName, err)
+	}
+
+	span.SetStatus(codes.Ok, fmt.Sprintf("synced %d rules successfully", len(rules)))
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/letmevibethatforyou/searchx"
 )
 
 func TestStaticSecrets(t *testing.T) {
@@ -352,6 +354,24 @@ func TestClientMethodsWithInitializationErrors(t *testing.T) {
 			if err == nil {
 				t.Error("Expected BatchDeleteObjects to return initialization error")
 			}
+
+			// Test SaveSettings
+			err = client.SaveSettings(ctx, "test-index", RankingSettings(nil, searchx.WithBoostBy("popularity")))
+			if err == nil {
+				t.Error("Expected SaveSettings to return initialization error")
+			}
+
+			// Test SaveSynonyms
+			err = client.SaveSynonyms(ctx, "test-index", []searchx.Synonym{searchx.TwoWaySynonym("chevy", "chevy", "chevrolet")})
+			if err == nil {
+				t.Error("Expected SaveSynonyms to return initialization error")
+			}
+
+			// Test SyncRules
+			err = client.SyncRules(ctx, "test-index", []searchx.Rule{searchx.PinRule("sponsored", "electric", "dealer-1")})
+			if err == nil {
+				t.Error("Expected SyncRules to return initialization error")
+			}
 		})
 	}
 }
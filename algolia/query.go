@@ -0,0 +1,119 @@
+package algolia
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/opt"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// convertedQuery is a parsed query translated to Algolia search parameters.
+type convertedQuery struct {
+	// text is the query text sent to Algolia.
+	text string
+	// filters contains the clauses on fields that are not searched, to combine
+	// with the other filters, and optionalFilters the optional ones.
+	filters         []searchx.Expression
+	optionalFilters []searchx.Expression
+	// params contains the query-specific search parameters.
+	params []interface{}
+}
+
+// convertQuery translates a parsed query to Algolia's query syntax.
+//
+// Clauses form the query text. Phrases and excluded words enable
+// advancedSyntax, which understands "exact phrase" and -word. Algolia can only
+// exclude single words, so an excluded phrase excludes each of its words.
+// Algolia requires every word by default and has no operator for required
+// words, so when the query has required clauses the optional words are listed
+// in optionalWords instead. Optional phrases stay required.
+//
+// Field-scoped clauses match words within their field, as with the inmemory
+// searcher, through restrictSearchableAttributes. Algolia restricts the whole
+// query rather than single words, so the restriction only applies when every
+// clause is scoped, to the union of their fields; in queries mixing scoped and
+// unscoped clauses, scoped words match in any searchable attribute.
+//
+// Clauses on fields the schema declares but not searchable, such as numbers,
+// become facet filters instead: required ones are returned as equality
+// filters, excluded ones as not-equal filters and optional ones as optional
+// filters, see optionalFilterParam. The fields must be declared in
+// attributesForFaceting. schema may be nil.
+func convertQuery(q searchx.Query, schema *searchx.Schema) convertedQuery {
+	var converted convertedQuery
+	var words, optionalWords, scopedFields []string
+	advanced, unscoped := false, false
+
+	for _, clause := range q.Clauses {
+		if clause.Field != "" && !searchableField(schema, clause.Field) {
+			switch clause.Occur {
+			case searchx.OccurMust:
+				converted.filters = append(converted.filters, searchx.Eq(clause.Field, clause.Text))
+			case searchx.OccurMustNot:
+				converted.filters = append(converted.filters, searchx.Ne(clause.Field, clause.Text))
+			default:
+				converted.optionalFilters = append(converted.optionalFilters, searchx.Eq(clause.Field, clause.Text))
+			}
+			continue
+		}
+
+		if clause.Field == "" {
+			unscoped = true
+		} else if !slices.Contains(scopedFields, clause.Field) {
+			scopedFields = append(scopedFields, clause.Field)
+		}
+
+		switch {
+		case clause.Occur == searchx.OccurMustNot:
+			advanced = true
+			for _, word := range strings.Fields(clause.Text) {
+				words = append(words, "-"+word)
+			}
+		case clause.Phrase:
+			advanced = true
+			words = append(words, `"`+clause.Text+`"`)
+		default:
+			words = append(words, clause.Text)
+			if clause.Occur == searchx.OccurShould {
+				optionalWords = append(optionalWords, clause.Text)
+			}
+		}
+	}
+
+	converted.text = strings.Join(words, " ")
+	if len(scopedFields) > 0 && !unscoped {
+		converted.params = append(converted.params, opt.RestrictSearchableAttributes(scopedFields...))
+	}
+	if advanced {
+		converted.params = append(converted.params, opt.AdvancedSyntax(true))
+	}
+	if q.HasRequired() && len(optionalWords) > 0 {
+		converted.params = append(converted.params, opt.OptionalWords(optionalWords...))
+	}
+
+	return converted
+}
+
+// optionalFilterParam returns the optionalFilters parameter of the optional
+// filters, or nil without any.
+func (c convertedQuery) optionalFilterParam() interface{} {
+	if len(c.optionalFilters) == 0 {
+		return nil
+	}
+	filters := make([]interface{}, len(c.optionalFilters))
+	for i, expr := range c.optionalFilters {
+		filters[i] = convertExpressionToFilter(expr)
+	}
+	return opt.OptionalFilterAnd(filters...)
+}
+
+// searchableField reports whether the words of a field are searched, which
+// they are for every field without a schema.
+func searchableField(schema *searchx.Schema, field string) bool {
+	if schema == nil {
+		return true
+	}
+	f, ok := schema.Field(field)
+	return !ok || f.Searchable
+}
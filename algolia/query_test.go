@@ -0,0 +1,128 @@
+package algolia
+
+import (
+	"testing"
+
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/opt"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestConvertQuery(t *testing.T) {
+	tests := map[string]struct {
+		query                   string
+		schema                  *searchx.Schema
+		expectedText            string
+		expectedFilters         []string
+		expectedAdvancedSyntax  bool
+		expectedOptionalWords   []string
+		expectedOptionalFilters []string
+		expectedRestricted      []string
+	}{
+		"plain_words": {
+			query:        "toyota  camry",
+			expectedText: "toyota camry",
+		},
+		"phrase": {
+			query:                  `"model s" plaid`,
+			expectedText:           `"model s" plaid`,
+			expectedAdvancedSyntax: true,
+		},
+		"excluded_word": {
+			query:                  "electric -tesla",
+			expectedText:           "electric -tesla",
+			expectedAdvancedSyntax: true,
+		},
+		"excluded_phrase": {
+			query:                  `sedan -"model s"`,
+			expectedText:           "sedan -model -s",
+			expectedAdvancedSyntax: true,
+		},
+		"required_makes_others_optional": {
+			query:                 "+tesla electric fast",
+			expectedText:          "tesla electric fast",
+			expectedOptionalWords: []string{"electric", "fast"},
+		},
+		"field_clauses": {
+			query:                  `title:civic +make:"honda motor"`,
+			expectedText:           `civic "honda motor"`,
+			expectedAdvancedSyntax: true,
+			expectedOptionalWords:  []string{"civic"},
+			expectedRestricted:     []string{"title", "make"},
+		},
+		"field_clauses_mixed_with_words": {
+			query:        "title:civic honda",
+			expectedText: "civic honda",
+		},
+		"field_clauses_not_searchable": {
+			query:                  `make:toyota +year:2020 -certified:true -color:red camry`,
+			schema:                 testSchema(),
+			expectedText:           "toyota -red camry",
+			expectedFilters:        []string{`year:"2020"`, `NOT certified:"true"`},
+			expectedAdvancedSyntax: true,
+			expectedOptionalWords:  []string{"toyota", "camry"},
+		},
+		"field_clauses_optional_filter": {
+			query:                   `civic year:2020`,
+			schema:                  testSchema(),
+			expectedText:            "civic",
+			expectedOptionalFilters: []string{`year:"2020"`},
+		},
+		"url_is_not_a_field": {
+			query:        "https://example.com",
+			expectedText: "https://example.com",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			converted := convertQuery(searchx.ParseQuery(tc.query), tc.schema)
+
+			if converted.text != tc.expectedText {
+				t.Errorf("Expected text %q, got %q", tc.expectedText, converted.text)
+			}
+
+			filters := make([]string, 0, len(converted.filters))
+			for _, expr := range converted.filters {
+				filters = append(filters, convertExpressionToFilter(expr))
+			}
+			if !equalStrings(filters, tc.expectedFilters) {
+				t.Errorf("Expected filters %q, got %q", tc.expectedFilters, filters)
+			}
+
+			var optionalFilters []string
+			if param := converted.optionalFilterParam(); param != nil {
+				for _, ors := range param.(*opt.OptionalFiltersOption).Get() {
+					optionalFilters = append(optionalFilters, ors...)
+				}
+			}
+
+			advancedSyntax := false
+			var optionalWords, restricted []string
+			for _, param := range converted.params {
+				switch p := param.(type) {
+				case *opt.AdvancedSyntaxOption:
+					advancedSyntax = p.Get()
+				case *opt.OptionalWordsOption:
+					optionalWords = p.Get()
+				case *opt.RestrictSearchableAttributesOption:
+					restricted = p.Get()
+				default:
+					t.Errorf("Unexpected parameter %T", param)
+				}
+			}
+
+			if advancedSyntax != tc.expectedAdvancedSyntax {
+				t.Errorf("Expected advancedSyntax %v, got %v", tc.expectedAdvancedSyntax, advancedSyntax)
+			}
+			if !equalStrings(optionalWords, tc.expectedOptionalWords) {
+				t.Errorf("Expected optionalWords %q, got %q", tc.expectedOptionalWords, optionalWords)
+			}
+			if !equalStrings(optionalFilters, tc.expectedOptionalFilters) {
+				t.Errorf("Expected optionalFilters %q, got %q", tc.expectedOptionalFilters, optionalFilters)
+			}
+			if !equalStrings(restricted, tc.expectedRestricted) {
+				t.Errorf("Expected restrictSearchableAttributes %q, got %q", tc.expectedRestricted, restricted)
+			}
+		})
+	}
+}
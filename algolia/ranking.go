@@ -0,0 +1,104 @@
+package algolia
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/opt"
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// RankingSettings converts the ranking options of a search configuration into
+// Algolia index settings. Algolia applies ranking at the index level rather than
+// per query, so the returned settings must be saved with Client.SaveSettings.
+//
+// Field weights become the searchableAttributes order, with equally weighted
+// fields sharing a priority and fields weighted zero or less left out.
+// Searchable fields of the schema missing from the weights keep the default
+// weight of 1, as in the inmemory searcher; schema may be nil.
+// Boost fields become descending customRanking criteria. Decay has no
+// equivalent in index settings, since the origin changes from query to query,
+// so it is left out.
+// The distinct field becomes attributeForDistinct, which queries using
+// WithDistinct rely on.
+func RankingSettings(schema *searchx.Schema, opts ...searchx.SearchOption) search.Settings {
+	cfg := &searchx.SearchConfig{}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+
+	var settings search.Settings
+
+	if attrs := searchableAttributes(schema, cfg.FieldWeights); len(attrs) > 0 {
+		settings.SearchableAttributes = opt.SearchableAttributes(attrs...)
+	}
+
+	if ranking := customRanking(cfg); len(ranking) > 0 {
+		settings.CustomRanking = opt.CustomRanking(ranking...)
+	}
+
+	if cfg.Distinct != nil {
+		settings.AttributeForDistinct = opt.AttributeForDistinct(cfg.Distinct.Field)
+	}
+
+	return settings
+}
+
+// searchableAttributes orders fields by descending weight, searchable fields of
+// the schema without a weight weighing 1. Fields with the same weight are joined
+// so Algolia treats them as equally important.
+func searchableAttributes(schema *searchx.Schema, weights map[string]float64) []string {
+	if len(weights) == 0 {
+		return nil
+	}
+
+	byWeight := make(map[float64][]string)
+	for field, weight := range weights {
+		if weight <= 0 {
+			continue
+		}
+		byWeight[weight] = append(byWeight[weight], field)
+	}
+	if schema != nil {
+		for _, f := range schema.Fields {
+			if _, weighted := weights[f.Name]; f.Searchable && !weighted {
+				byWeight[1] = append(byWeight[1], f.Name)
+			}
+		}
+	}
+
+	levels := make([]float64, 0, len(byWeight))
+	for weight := range byWeight {
+		levels = append(levels, weight)
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(levels)))
+
+	attrs := make([]string, 0, len(levels))
+	for _, weight := range levels {
+		fields := byWeight[weight]
+		sort.Strings(fields)
+		attrs = append(attrs, strings.Join(fields, ","))
+	}
+	return attrs
+}
+
+// customRanking builds descending customRanking criteria for boost fields.
+func customRanking(cfg *searchx.SearchConfig) []string {
+	seen := make(map[string]bool)
+	var ranking []string
+
+	add := func(field string) {
+		if field == "" || seen[field] {
+			return
+		}
+		seen[field] = true
+		ranking = append(ranking, "desc("+field+")")
+	}
+
+	for _, field := range cfg.BoostBy {
+		add(field)
+	}
+
+	return ranking
+}
@@ -0,0 +1,103 @@
+package algolia
+
+import (
+	"testing"
+	"time"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestRankingSettings(t *testing.T) {
+	tests := []struct {
+		name               string
+		schema             *searchx.Schema
+		opts               []searchx.SearchOption
+		expectedSearchable []string
+		expectedRanking    []string
+		expectedDistinct   string
+	}{
+		{
+			name: "no ranking options",
+		},
+		{
+			name: "field weights ordered by weight",
+			opts: []searchx.SearchOption{
+				searchx.WithFieldWeights(map[string]float64{
+					"description": 1,
+					"model":       5,
+					"make":        5,
+					"notes":       0,
+				}),
+			},
+			expectedSearchable: []string{"make,model", "description"},
+		},
+		{
+			name: "unweighted searchable fields weigh 1",
+			schema: searchx.NewSchema(
+				searchx.Field{Name: "make", Type: searchx.FieldString, Searchable: true},
+				searchx.Field{Name: "notes", Type: searchx.FieldString, Searchable: true},
+				searchx.Field{Name: "trim", Type: searchx.FieldString, Searchable: true},
+				searchx.Field{Name: "color", Type: searchx.FieldString, Searchable: true},
+				searchx.Field{Name: "year", Type: searchx.FieldNumber, Filterable: true},
+			),
+			opts: []searchx.SearchOption{
+				searchx.WithFieldWeights(map[string]float64{
+					"make":        5,
+					"description": 1,
+					"notes":       0,
+					"summary":     0.5,
+				}),
+			},
+			expectedSearchable: []string{"make", "color,description,trim", "summary"},
+		},
+		{
+			name:   "schema without weights",
+			schema: searchx.NewSchema(searchx.Field{Name: "make", Type: searchx.FieldString, Searchable: true}),
+		},
+		{
+			name: "boost becomes custom ranking and decay is left out",
+			opts: []searchx.SearchOption{
+				searchx.WithBoostBy("popularity"),
+				searchx.WithDecay("listed_at", time.Now(), 7*24*time.Hour),
+				searchx.WithBoostBy("popularity"),
+			},
+			expectedRanking: []string{"desc(popularity)"},
+		},
+		{
+			name:             "distinct field",
+			opts:             []searchx.SearchOption{searchx.WithDistinct("model", 2)},
+			expectedDistinct: "model",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := RankingSettings(tt.schema, tt.opts...)
+
+			if got := settings.SearchableAttributes.Get(); !equalStrings(got, tt.expectedSearchable) {
+				t.Errorf("Expected searchable attributes %v, got %v", tt.expectedSearchable, got)
+			}
+
+			if got := settings.CustomRanking.Get(); !equalStrings(got, tt.expectedRanking) {
+				t.Errorf("Expected custom ranking %v, got %v", tt.expectedRanking, got)
+			}
+
+			if got := settings.AttributeForDistinct.Get(); got != tt.expectedDistinct {
+				t.Errorf("Expected attribute for distinct %q, got %q", tt.expectedDistinct, got)
+			}
+		})
+	}
+}
+
+// equalStrings compares two string slices, treating nil and empty as equal.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package algolia
+
+import (
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/opt"
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// convertRules converts searchx query rules to Algolia Rules.
+func convertRules(rules []searchx.Rule) ([]search.Rule, error) {
+	converted := make([]search.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.ID == "" {
+			return nil, errors.Wrap(searchx.ErrInvalidOption, "rules must have an ID")
+		}
+
+		c, err := convertRuleCondition(rule.Condition)
+		if err != nil {
+			return nil, errors.Wrapf(err, "rule %s", rule.ID)
+		}
+
+		r := search.Rule{
+			ObjectID:    rule.ID,
+			Description: rule.Description,
+			Consequence: convertRuleConsequence(rule.Consequence),
+		}
+		if c != (search.RuleCondition{}) {
+			r.Conditions = []search.RuleCondition{c}
+		}
+		converted = append(converted, r)
+	}
+	return converted, nil
+}
+
+// convertRuleCondition converts a rule condition. A condition without a pattern
+// or filters converts to the zero condition, which Algolia applies to every search.
+func convertRuleCondition(condition searchx.RuleCondition) (search.RuleCondition, error) {
+	var c search.RuleCondition
+	switch condition.Anchoring {
+	case "":
+	case searchx.AnchoringIs:
+		c.Anchoring = search.Is
+	case searchx.AnchoringStartsWith:
+		c.Anchoring = search.StartsWith
+	case searchx.AnchoringEndsWith:
+		c.Anchoring = search.EndsWith
+	case searchx.AnchoringContains:
+		c.Anchoring = search.Contains
+	default:
+		return c, errors.Wrapf(searchx.ErrInvalidOption, "unsupported anchoring %q", condition.Anchoring)
+	}
+	if c.Anchoring != "" {
+		c.Pattern = condition.Pattern
+	}
+	c.Filters = joinFilters(condition.Filters)
+	return c, nil
+}
+
+// convertRuleConsequence converts a rule consequence to promoted and hidden
+// objects, and to query parameters for added filters and query rewrites.
+func convertRuleConsequence(consequence searchx.RuleConsequence) search.RuleConsequence {
+	var c search.RuleConsequence
+	for _, promotion := range consequence.Promote {
+		c.Promote = append(c.Promote, search.PromotedObject{ObjectID: promotion.ID, Position: promotion.Position})
+	}
+	if len(c.Promote) > 0 {
+		// Promoted objects must pass the filters, as in the inmemory searcher
+		c.FilterPromotes = opt.FilterPromotes(true)
+	}
+	for _, id := range consequence.Hide {
+		c.Hide = append(c.Hide, search.HiddenObject{ObjectID: id})
+	}
+
+	filters := joinFilters(consequence.Filters)
+	if filters != "" || consequence.Query != nil {
+		c.Params = &search.RuleParams{}
+		if filters != "" {
+			c.Params.Filters = opt.Filters(filters)
+		}
+		if consequence.Query != nil {
+			c.Params.Query = search.NewRuleQuerySimple(*consequence.Query)
+		}
+	}
+	return c
+}
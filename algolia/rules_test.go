@@ -0,0 +1,82 @@
+package algolia
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestConvertRules(t *testing.T) {
+	electric := "electric"
+
+	tests := map[string]struct {
+		rule         searchx.Rule
+		expectedJSON string
+	}{
+		"pin": {
+			rule:         searchx.PinRule("sponsored", "electric", "dealer-1", "dealer-2"),
+			expectedJSON: `{"conditions":[{"anchoring":"contains","pattern":"electric"}],"consequence":{"promote":[{"objectID":"dealer-1","position":0},{"objectID":"dealer-2","position":1}],"filterPromotes":true},"objectID":"sponsored"}`,
+		},
+		"hide": {
+			rule:         searchx.HideRule("recalled", "leaf", "car-7"),
+			expectedJSON: `{"conditions":[{"anchoring":"contains","pattern":"leaf"}],"consequence":{"hide":[{"objectID":"car-7"}]},"objectID":"recalled"}`,
+		},
+		"filters_and_rewrite": {
+			rule: searchx.Rule{
+				ID:          "ev",
+				Description: "EV means electric",
+				Condition: searchx.RuleCondition{
+					Pattern:   "ev",
+					Anchoring: searchx.AnchoringIs,
+					Filters:   []searchx.Expression{searchx.Eq("make", "tesla")},
+				},
+				Consequence: searchx.RuleConsequence{
+					Filters: []searchx.Expression{searchx.Eq("fuel", "electric")},
+					Query:   &electric,
+				},
+			},
+			expectedJSON: `{"conditions":[{"anchoring":"is","filters":"make:\"tesla\"","pattern":"ev"}],"consequence":{"params":{"query":"electric","filters":"fuel:\"electric\""}},"description":"EV means electric","objectID":"ev"}`,
+		},
+		"no_condition": {
+			rule: searchx.Rule{
+				ID:          "always",
+				Consequence: searchx.RuleConsequence{Hide: []string{"car-1"}},
+			},
+			expectedJSON: `{"consequence":{"hide":[{"objectID":"car-1"}]},"objectID":"always"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			converted, err := convertRules([]searchx.Rule{tc.rule})
+			if err != nil {
+				t.Fatalf("convertRules failed: %v", err)
+			}
+
+			data, err := json.Marshal(converted[0])
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(data) != tc.expectedJSON {
+				t.Errorf("Expected %s, got %s", tc.expectedJSON, data)
+			}
+		})
+	}
+}
+
+func TestConvertRulesErrors(t *testing.T) {
+	tests := map[string]searchx.Rule{
+		"missing_id":            {Consequence: searchx.RuleConsequence{Hide: []string{"1"}}},
+		"unsupported_anchoring": {ID: "bad", Condition: searchx.RuleCondition{Pattern: "x", Anchoring: "fuzzy"}},
+	}
+
+	for name, rule := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := convertRules([]searchx.Rule{rule}); !errors.Is(err, searchx.ErrInvalidOption) {
+				t.Errorf("Expected ErrInvalidOption, got: %v", err)
+			}
+		})
+	}
+}
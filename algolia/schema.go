@@ -0,0 +1,56 @@
+package algolia
+
+import (
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/opt"
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// SchemaSettings returns index settings matching a schema and the searches
+// made with the given options, which set the fields counted by Algolia.
+// Searchable fields become searchableAttributes in declaration order, and
+// filterable string and bool fields become filter-only attributesForFaceting,
+// which equality filters on them require. Numeric and time fields need no
+// settings to be filtered.
+//
+// The fields of WithStats aggregations and of WithDistinct, of any type, become
+// plain attributesForFaceting instead, without which Algolia returns no stats
+// or group counts. The distinct field also becomes attributeForDistinct.
+// Ranking settings are left to RankingSettings.
+func SchemaSettings(schema *searchx.Schema, opts ...searchx.SearchOption) search.Settings {
+	cfg := &searchx.SearchConfig{}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+	counted := facetFields(cfg)
+
+	var searchable, faceting []string
+	for _, f := range schema.Fields {
+		if f.Searchable {
+			searchable = append(searchable, f.Name)
+		}
+		switch {
+		case containsField(counted, f.Name):
+			faceting = append(faceting, f.Name)
+		case f.Filterable && (f.Type == searchx.FieldString || f.Type == searchx.FieldBool):
+			faceting = append(faceting, "filterOnly("+f.Name+")")
+		}
+	}
+	for _, field := range counted {
+		if _, ok := schema.Field(field); !ok {
+			faceting = append(faceting, field)
+		}
+	}
+
+	var settings search.Settings
+	if len(searchable) > 0 {
+		settings.SearchableAttributes = opt.SearchableAttributes(searchable...)
+	}
+	if len(faceting) > 0 {
+		settings.AttributesForFaceting = opt.AttributesForFaceting(faceting...)
+	}
+	if cfg.Distinct != nil {
+		settings.AttributeForDistinct = opt.AttributeForDistinct(cfg.Distinct.Field)
+	}
+	return settings
+}
@@ -0,0 +1,204 @@
+package algolia
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func testSchema() *searchx.Schema {
+	return searchx.NewSchema(
+		searchx.Field{Name: "title", Type: searchx.FieldString, Searchable: true},
+		searchx.Field{Name: "make", Type: searchx.FieldString, Searchable: true, Filterable: true},
+		searchx.Field{Name: "year", Type: searchx.FieldNumber, Filterable: true, Sortable: true},
+		searchx.Field{Name: "certified", Type: searchx.FieldBool, Filterable: true},
+		searchx.Field{Name: "vin", Type: searchx.FieldString},
+	)
+}
+
+func TestSchemaSettings(t *testing.T) {
+	tests := map[string]struct {
+		opts             []searchx.SearchOption
+		expectedFaceting []string
+		expectedDistinct string
+	}{
+		"filters_only": {
+			expectedFaceting: []string{"filterOnly(make)", "filterOnly(certified)"},
+		},
+		"counted_fields": {
+			opts:             []searchx.SearchOption{searchx.WithStats("year"), searchx.WithDistinct("make", 1)},
+			expectedFaceting: []string{"make", "year", "filterOnly(certified)"},
+			expectedDistinct: "make",
+		},
+		"undeclared_counted_field": {
+			opts:             []searchx.SearchOption{searchx.WithStats("price")},
+			expectedFaceting: []string{"filterOnly(make)", "filterOnly(certified)", "price"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			settings := SchemaSettings(testSchema(), tc.opts...)
+
+			expectedSearchable := []string{"title", "make"}
+			if got := settings.SearchableAttributes.Get(); !reflect.DeepEqual(got, expectedSearchable) {
+				t.Errorf("Expected searchable attributes %v, got %v", expectedSearchable, got)
+			}
+			if got := settings.AttributesForFaceting.Get(); !reflect.DeepEqual(got, tc.expectedFaceting) {
+				t.Errorf("Expected attributes for faceting %v, got %v", tc.expectedFaceting, got)
+			}
+			if got := settings.AttributeForDistinct.Get(); got != tc.expectedDistinct {
+				t.Errorf("Expected attribute for distinct %q, got %q", tc.expectedDistinct, got)
+			}
+		})
+	}
+}
+
+func TestSchemaFilters(t *testing.T) {
+	tests := map[string]struct {
+		filter      searchx.Expression
+		expected    string
+		expectedErr error
+	}{
+		"numeric_string": {
+			filter:   searchx.Eq("year", "2018"),
+			expected: "year = 2018",
+		},
+		"numeric_range": {
+			filter:   searchx.Range("year", "2015", 2018.5),
+			expected: "year >= 2015 AND year <= 2018.5",
+		},
+		"bool_string": {
+			filter:   searchx.Eq("certified", "true"),
+			expected: `certified:"true"`,
+		},
+		"undeclared": {
+			filter:      searchx.Eq("color", "red"),
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+		"not_filterable": {
+			filter:      searchx.Eq("vin", "123"),
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+		"not_a_number": {
+			filter:      searchx.Gt("year", "recent"),
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &searchx.SearchConfig{}
+			tc.filter.Apply(cfg)
+
+			err := testSchema().Check(cfg)
+			if tc.expectedErr != nil {
+				if !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("Expected %v, got: %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Check failed: %v", err)
+			}
+
+			if got := joinFilters(cfg.Filters); got != tc.expected {
+				t.Errorf("Expected filter %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSearchWithSchema(t *testing.T) {
+	client := NewClient(StaticSecrets("test-app", "test-key"))
+	searcher := NewSearcher(client, "test-index", WithSchema(testSchema()))
+
+	_, err := searcher.Search(context.Background(), "toyota", searchx.Eq("color", "red"))
+	if !errors.Is(err, searchx.ErrInvalidExpression) {
+		t.Errorf("Expected ErrInvalidExpression, got: %v", err)
+	}
+
+	_, err = searcher.Search(context.Background(), "toyota", searchx.WithSort("title", false))
+	if !errors.Is(err, searchx.ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption, got: %v", err)
+	}
+}
+
+func TestSchemaQueryFilters(t *testing.T) {
+	searcher := NewSearcher(NewClient(StaticSecrets("test-app", "test-key")), "test-index", WithSchema(testSchema()))
+
+	tests := map[string]struct {
+		query            string
+		opts             []searchx.SearchOption
+		expected         string
+		expectedOptional []string
+		expectedErr      error
+	}{
+		"numeric_field_clause": {
+			query:    "+year:2018 toyota",
+			expected: "year = 2018",
+		},
+		"with_other_filters": {
+			query:    "-certified:TRUE",
+			opts:     []searchx.SearchOption{searchx.Gte("year", "2015")},
+			expected: `year >= 2015 AND NOT certified:"true"`,
+		},
+		"optional_field_clause": {
+			query:            "toyota year:2018",
+			expectedOptional: []string{"year = 2018"},
+		},
+		"not_a_number": {
+			query:       "+year:recent",
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+		"not_filterable": {
+			query:       "+vin:123",
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+		"optional_not_filterable": {
+			query:       "toyota vin:123",
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &searchx.SearchConfig{}
+			for _, opt := range tc.opts {
+				opt.Apply(cfg)
+			}
+
+			converted, err := searcher.convert(tc.query, cfg, time.Now())
+			if tc.expectedErr != nil {
+				if !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("Expected %v, got: %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("convert failed: %v", err)
+			}
+
+			if got := joinFilters(cfg.Filters); got != tc.expected {
+				t.Errorf("Expected filters %q, got %q", tc.expected, got)
+			}
+			var optional []string
+			for _, expr := range converted.optionalFilters {
+				optional = append(optional, convertExpressionToFilter(expr))
+			}
+			if !equalStrings(optional, tc.expectedOptional) {
+				t.Errorf("Expected optional filters %q, got %q", tc.expectedOptional, optional)
+			}
+		})
+	}
+
+	// Invalid field clauses are rejected before calling Algolia
+	_, err := searcher.Search(context.Background(), "+year:recent")
+	if !errors.Is(err, searchx.ErrInvalidExpression) {
+		t.Errorf("Expected ErrInvalidExpression, got: %v", err)
+	}
+}
@@ -16,14 +16,32 @@ import (
 type Searcher struct {
 	client    *Client
 	indexName string
+	schema    *searchx.Schema
+}
+
+// SearcherOption configures a Searcher.
+type SearcherOption func(*Searcher)
+
+// WithSchema validates searches against a schema before calling Algolia:
+// filters and sorts must use fields it allows, and filter values are coerced
+// to the declared types, including those of field clauses in the query such as
+// +year:2018. See SchemaSettings for the matching index settings.
+func WithSchema(schema *searchx.Schema) SearcherOption {
+	return func(s *Searcher) {
+		s.schema = schema
+	}
 }
 
 // NewSearcher creates a new Algolia searcher for the specified index.
-func NewSearcher(client *Client, indexName string) *Searcher {
-	return &Searcher{
+func NewSearcher(client *Client, indexName string, opts ...SearcherOption) *Searcher {
+	s := &Searcher{
 		client:    client,
 		indexName: indexName,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Search implements the searchx.Searcher interface using Algolia search.
@@ -51,6 +69,18 @@ func (s *Searcher) Search(ctx context.Context, query string, opts ...searchx.Sea
 		cfg.Limit = 10
 	}
 
+	// Reject unsupported aggregations before calling Algolia
+	if err := checkAggregations(cfg.Aggregations); err != nil {
+		return nil, err
+	}
+	if cfg.Vector != nil || cfg.HybridAlpha != nil {
+		return nil, errors.Wrap(searchx.ErrNotImplemented, "Algolia searcher does not support vector search")
+	}
+	converted, err := s.convert(query, cfg, startTime)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get Algolia client
 	algoliaClient, err := s.client.getClient()
 	if err != nil {
@@ -64,10 +94,13 @@ func (s *Searcher) Search(ctx context.Context, query string, opts ...searchx.Sea
 	index := algoliaClient.InitIndex(s.indexName)
 
 	// Build search parameters
-	params := buildSearchParams(cfg)
+	params := append(buildSearchParams(cfg), converted.params...)
+	if param := converted.optionalFilterParam(); param != nil {
+		params = append(params, param)
+	}
 
 	// Execute search
-	res, err := index.Search(query, params...)
+	res, err := index.Search(converted.text, params...)
 	if err != nil {
 		// Check if this is a timeout or cancellation error
 		if errors.Is(err, context.DeadlineExceeded) {
@@ -117,6 +150,13 @@ func (s *Searcher) Search(ctx context.Context, query string, opts ...searchx.Sea
 		results.Items = append(results.Items, result)
 	}
 
+	if len(cfg.Aggregations) > 0 {
+		results.Aggregations = convertAggregations(cfg.Aggregations, res)
+	}
+	if cfg.Distinct != nil {
+		results.GroupCounts = convertGroupCounts(cfg.Distinct, res)
+	}
+
 	// Set next offset for pagination
 	nextPage := res.Page + 1
 	if nextPage < res.NbPages {
@@ -127,6 +167,32 @@ func (s *Searcher) Search(ctx context.Context, query string, opts ...searchx.Sea
 	return results, nil
 }
 
+// convert translates the query syntax, moving clauses on fields not searched
+// to the filters of cfg, and then checks cfg against the schema, so that the
+// filters of the query are validated and coerced like the others. Relative
+// times are resolved at now.
+func (s *Searcher) convert(query string, cfg *searchx.SearchConfig, now time.Time) (convertedQuery, error) {
+	converted := convertQuery(searchx.ParseQuery(query), s.schema)
+	for _, expr := range converted.filters {
+		expr.Apply(cfg)
+	}
+
+	if s.schema != nil {
+		if err := s.schema.Check(cfg); err != nil {
+			return convertedQuery{}, err
+		}
+		optional, err := s.schema.CoerceFilters(converted.optionalFilters)
+		if err != nil {
+			return convertedQuery{}, err
+		}
+		converted.optionalFilters = optional
+	}
+
+	// Resolve relative times once so that every filter uses the same instant
+	cfg.Filters = searchx.ResolveTimes(cfg.Filters, now)
+	return converted, nil
+}
+
 // buildSearchParams converts searchx.SearchConfig to Algolia search parameters
 func buildSearchParams(cfg *searchx.SearchConfig) []interface{} {
 	var params []interface{}
@@ -138,17 +204,24 @@ func buildSearchParams(cfg *searchx.SearchConfig) []interface{} {
 		params = append(params, opt.Page(page))
 	}
 
+	// Set typo tolerance
+	if cfg.TypoTolerance != nil {
+		params = append(params, opt.TypoTolerance(*cfg.TypoTolerance))
+	}
+
+	// Collapse results sharing the attributeForDistinct value
+	if cfg.Distinct != nil {
+		params = append(params, opt.DistinctOf(cfg.Distinct.PerGroup))
+	}
+
+	// Request facets for stats and group counts
+	if fields := facetFields(cfg); len(fields) > 0 {
+		params = append(params, opt.Facets(fields...), opt.MaxValuesPerFacet(maxValuesPerFacet))
+	}
+
 	// Convert filters
-	if len(cfg.Filters) > 0 {
-		filterStrings := make([]string, 0, len(cfg.Filters))
-		for _, expr := range cfg.Filters {
-			if filterStr := convertExpressionToFilter(expr); filterStr != "" {
-				filterStrings = append(filterStrings, filterStr)
-			}
-		}
-		if len(filterStrings) > 0 {
-			params = append(params, opt.Filters(strings.Join(filterStrings, " AND ")))
-		}
+	if filters := joinFilters(cfg.Filters); filters != "" {
+		params = append(params, opt.Filters(filters))
 	}
 
 	// Convert sorting
@@ -185,6 +258,17 @@ func calculateScore(totalResults, position int) float64 {
 	return float64(totalResults-position) / float64(totalResults)
 }
 
+// joinFilters converts expressions to an Algolia filter string matching all of them
+func joinFilters(exprs []searchx.Expression) string {
+	filters := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		if filter := convertExpressionToFilter(expr); filter != "" {
+			filters = append(filters, filter)
+		}
+	}
+	return strings.Join(filters, " AND ")
+}
+
 // convertExpressionToFilter converts a searchx expression to an Algolia filter string
 func convertExpressionToFilter(expr searchx.Expression) string {
 	switch e := expr.(type) {
@@ -254,11 +338,17 @@ func convertNotExpression(expr searchx.NotExpr) string {
 
 // convertEqExpression converts an equality expression to Algolia filter syntax
 func convertEqExpression(expr searchx.EqExpr) string {
+	if numeric, ok := numericFilterValue(expr.Value); ok {
+		return fmt.Sprintf("%s = %s", escapeField(expr.Field), numeric)
+	}
 	return fmt.Sprintf("%s:%s", escapeField(expr.Field), escapeValue(expr.Value))
 }
 
 // convertNeExpression converts a not-equal expression to Algolia filter syntax
 func convertNeExpression(expr searchx.NeExpr) string {
+	if numeric, ok := numericFilterValue(expr.Value); ok {
+		return fmt.Sprintf("%s != %s", escapeField(expr.Field), numeric)
+	}
 	return fmt.Sprintf("NOT %s:%s", escapeField(expr.Field), escapeValue(expr.Value))
 }
 
@@ -333,12 +423,32 @@ func escapeValue(value interface{}) string {
 	}
 }
 
+// numericFilterValue formats numbers and times for numeric equality filters.
+// Numeric attributes cannot be matched with facet filters, which only apply to
+// strings and booleans.
+func numericFilterValue(value interface{}) (string, bool) {
+	if seconds, ok := epochSeconds(value); ok {
+		return strconv.FormatInt(seconds, 10), true
+	}
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprintf("%v", value), true
+	default:
+		return "", false
+	}
+}
+
 // escapeNumericValue escapes numeric values for Algolia filters
 func escapeNumericValue(value interface{}) string {
 	if value == nil {
 		return "0"
 	}
 
+	// Times are indexed as Unix epoch seconds, see indexTimes
+	if seconds, ok := epochSeconds(value); ok {
+		return strconv.FormatInt(seconds, 10)
+	}
+
 	switch v := value.(type) {
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 		return fmt.Sprintf("%v", v)
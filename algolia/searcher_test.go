@@ -3,6 +3,7 @@ package algolia
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -70,6 +71,22 @@ func TestBuildSearchParams(t *testing.T) {
 			},
 			expectedCount: 1, // HitsPerPage only (sorting needs replica indices)
 		},
+		{
+			name: "with typo tolerance",
+			config: &searchx.SearchConfig{
+				Limit:         10,
+				TypoTolerance: new(bool),
+			},
+			expectedCount: 2, // HitsPerPage and TypoTolerance options
+		},
+		{
+			name: "with distinct",
+			config: &searchx.SearchConfig{
+				Limit:    10,
+				Distinct: &searchx.Distinct{Field: "model", PerGroup: 1},
+			},
+			expectedCount: 4, // HitsPerPage, Distinct, Facets and MaxValuesPerFacet options
+		},
 	}
 
 	for _, tt := range tests {
@@ -196,6 +213,41 @@ func TestConvertExpressionToFilter(t *testing.T) {
 			expr:     searchx.Not(searchx.Eq("status", "deleted")),
 			expected: `NOT (status:"deleted")`,
 		},
+		{
+			name:     "numeric equality",
+			expr:     searchx.Eq("year", 2018),
+			expected: "year = 2018",
+		},
+		{
+			name:     "numeric inequality",
+			expr:     searchx.Ne("year", 2018),
+			expected: "year != 2018",
+		},
+		{
+			name:     "time value",
+			expr:     searchx.Gte("listed_at", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+			expected: "listed_at >= 1709251200",
+		},
+		{
+			name:     "RFC 3339 string",
+			expr:     searchx.Lt("listed_at", "2024-03-01T01:00:00+01:00"),
+			expected: "listed_at < 1709251200",
+		},
+		{
+			name:     "time range",
+			expr:     searchx.Range("listed_at", time.Unix(1709251200, 0), time.Unix(1709337600, 0)),
+			expected: "listed_at >= 1709251200 AND listed_at <= 1709337600",
+		},
+		{
+			name:     "time equality",
+			expr:     searchx.Eq("listed_at", time.Unix(1709251200, 0)),
+			expected: "listed_at = 1709251200",
+		},
+		{
+			name:     "time inequality",
+			expr:     searchx.Ne("listed_at", time.Unix(1709251200, 0)),
+			expected: "listed_at != 1709251200",
+		},
 		{
 			name: "complex nested expression",
 			expr: searchx.And(
@@ -479,3 +531,53 @@ func TestErrorCodeUsage(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchWithVector(t *testing.T) {
+	client := NewClient(StaticSecrets("test-app", "test-key"))
+	searcher := NewSearcher(client, "test-index")
+
+	_, err := searcher.Search(context.Background(), "toyota", searchx.WithVector("embedding", []float32{1, 0}, 5))
+	if !errors.Is(err, searchx.ErrNotImplemented) {
+		t.Errorf("Expected ErrNotImplemented, got: %v", err)
+	}
+}
+
+func TestRelativeTimeFilter(t *testing.T) {
+	now := time.Unix(1709251200, 0)
+	filters := searchx.ResolveTimes([]searchx.Expression{searchx.InLast("listed_at", 24*time.Hour)}, now)
+
+	expected := "listed_at >= 1709164800"
+	if result := convertExpressionToFilter(filters[0]); result != expected {
+		t.Errorf("Expected filter '%s', got '%s'", expected, result)
+	}
+}
+
+func TestIndexTimes(t *testing.T) {
+	object := map[string]interface{}{
+		"objectID":  "1",
+		"title":     "Toyota Camry",
+		"listed_at": time.Unix(1709251200, 0),
+		"sold_at":   "2024-03-01T00:00:00Z",
+		"history": []interface{}{
+			map[string]interface{}{"at": "2024-03-02T00:00:00Z", "price": 20000},
+		},
+	}
+
+	converted := indexTimes(object)
+
+	expected := map[string]interface{}{
+		"objectID":  "1",
+		"title":     "Toyota Camry",
+		"listed_at": int64(1709251200),
+		"sold_at":   int64(1709251200),
+		"history": []interface{}{
+			map[string]interface{}{"at": int64(1709337600), "price": 20000},
+		},
+	}
+	if !reflect.DeepEqual(converted, expected) {
+		t.Errorf("Expected %v, got %v", expected, converted)
+	}
+	if _, ok := object["listed_at"].(time.Time); !ok {
+		t.Error("Expected the original object to be left unchanged")
+	}
+}
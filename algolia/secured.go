@@ -0,0 +1,55 @@
+package algolia
+
+import (
+	"time"
+
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/opt"
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// TenantKeyConfig configures a secured API key generated by TenantAPIKey.
+type TenantKeyConfig struct {
+	// ValidUntil is when the key expires. It is required, so that leaked keys
+	// do not grant access forever.
+	ValidUntil time.Time
+	// Indices restricts the key to the given indices when not empty.
+	Indices []string
+	// Filters are additional filters embedded in the key.
+	Filters []searchx.Expression
+}
+
+// TenantAPIKey generates a secured API key for browser-direct search on behalf
+// of a tenant. The key embeds a filter on field equal to tenant that callers
+// cannot remove, mirroring searchx.NewTenantSearcher.
+//
+// The parent key must be a search-only API key: a secured key inherits the
+// permissions of its parent, so the write key of the Client must not be used.
+// Keys are signed locally and no request is made to Algolia.
+func TenantAPIKey(searchAPIKey, field, tenant string, cfg TenantKeyConfig) (string, error) {
+	if searchAPIKey == "" {
+		return "", errors.Wrap(searchx.ErrInvalidOption, "secured API keys require a parent search API key")
+	}
+	if field == "" || tenant == "" {
+		return "", errors.Wrap(searchx.ErrInvalidOption, "secured API keys require a tenant field and ID")
+	}
+	if cfg.ValidUntil.IsZero() {
+		return "", errors.Wrap(searchx.ErrInvalidOption, "secured API keys require an expiry")
+	}
+
+	filters := append([]searchx.Expression{searchx.Eq(field, tenant)}, cfg.Filters...)
+	opts := []interface{}{
+		opt.Filters(joinFilters(filters)),
+		opt.ValidUntil(cfg.ValidUntil),
+	}
+	if len(cfg.Indices) > 0 {
+		opts = append(opts, opt.RestrictIndices(cfg.Indices...))
+	}
+
+	key, err := search.GenerateSecuredAPIKey(searchAPIKey, opts...)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate secured API key")
+	}
+	return key, nil
+}
@@ -0,0 +1,79 @@
+package algolia
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestTenantAPIKey(t *testing.T) {
+	validUntil := time.Unix(1900000000, 0)
+
+	key, err := TenantAPIKey("search-key", "dealer_id", "dealer-42", TenantKeyConfig{
+		ValidUntil: validUntil,
+		Indices:    []string{"vehicles"},
+		Filters:    []searchx.Expression{searchx.Eq("status", "available")},
+	})
+	if err != nil {
+		t.Fatalf("TenantAPIKey failed: %v", err)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		t.Fatalf("Expected a base64 key: %v", err)
+	}
+	// The key is a 64 character HMAC followed by the embedded parameters
+	params, err := url.ParseQuery(string(decoded[64:]))
+	if err != nil {
+		t.Fatalf("Expected URL-encoded parameters: %v", err)
+	}
+
+	expected := map[string]string{
+		"filters":         `dealer_id:"dealer-42" AND status:"available"`,
+		"validUntil":      strconv.FormatInt(validUntil.Unix(), 10),
+		"restrictIndices": `["vehicles"]`,
+	}
+	for name, value := range expected {
+		if got := params.Get(name); got != value {
+			t.Errorf("Expected %s %q, got %q", name, value, got)
+		}
+	}
+
+	other, err := TenantAPIKey("search-key", "dealer_id", "dealer-43", TenantKeyConfig{ValidUntil: validUntil})
+	if err != nil {
+		t.Fatalf("TenantAPIKey failed: %v", err)
+	}
+	if other == key {
+		t.Error("Expected different keys for different tenants")
+	}
+}
+
+func TestTenantAPIKeyValidation(t *testing.T) {
+	validUntil := time.Now().Add(time.Hour)
+
+	tests := map[string]struct {
+		parent string
+		field  string
+		tenant string
+		cfg    TenantKeyConfig
+	}{
+		"missing_parent": {field: "dealer_id", tenant: "dealer-42", cfg: TenantKeyConfig{ValidUntil: validUntil}},
+		"missing_field":  {parent: "search-key", tenant: "dealer-42", cfg: TenantKeyConfig{ValidUntil: validUntil}},
+		"missing_tenant": {parent: "search-key", field: "dealer_id", cfg: TenantKeyConfig{ValidUntil: validUntil}},
+		"missing_expiry": {parent: "search-key", field: "dealer_id", tenant: "dealer-42"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := TenantAPIKey(tc.parent, tc.field, tc.tenant, tc.cfg)
+			if !errors.Is(err, searchx.ErrInvalidOption) {
+				t.Errorf("Expected ErrInvalidOption, got: %v", err)
+			}
+		})
+	}
+}
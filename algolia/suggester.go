@@ -0,0 +1,119 @@
+package algolia
+
+import (
+	"context"
+
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/opt"
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// Suggester implements the searchx.Suggester interface using prefix search on
+// an Algolia Query Suggestions index. Records are expected to follow the Query
+// Suggestions format, with the suggested text in "query" and its search count
+// in "popularity". A dedicated index with the same attributes works as well.
+type Suggester struct {
+	client    *Client
+	indexName string
+}
+
+// NewSuggester creates a new Algolia suggester for the specified suggestions index.
+func NewSuggester(client *Client, indexName string) *Suggester {
+	return &Suggester{
+		client:    client,
+		indexName: indexName,
+	}
+}
+
+// Suggest implements the searchx.Suggester interface using Algolia prefix search.
+// Algolia ranks the suggestions, which are returned in its order. When fields
+// are given they restrict the searchable attributes of the suggestions index.
+// Filters return ErrNotImplemented: the records of a Query Suggestions index
+// are past queries, which do not hold the fields of the documents they found.
+func (s *Suggester) Suggest(ctx context.Context, prefix string, opts ...searchx.SuggestOption) ([]searchx.Suggestion, error) {
+	// Check context
+	select {
+	case <-ctx.Done():
+		return nil, searchx.ErrCanceled
+	default:
+	}
+
+	// Parse options
+	cfg := &searchx.SuggestConfig{}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+
+	// Set defaults
+	if cfg.Limit <= 0 {
+		cfg.Limit = 10
+	}
+
+	if len(cfg.Filters) > 0 {
+		return nil, errors.Wrap(searchx.ErrNotImplemented, "Algolia suggestions cannot be filtered")
+	}
+
+	// Get Algolia client
+	algoliaClient, err := s.client.getClient()
+	if err != nil {
+		return nil, errors.WithSecondaryError(
+			searchx.ErrBackendUnavailable,
+			errors.Wrapf(err, "failed to get Algolia client"),
+		)
+	}
+
+	// Get index
+	index := algoliaClient.InitIndex(s.indexName)
+
+	params := []interface{}{opt.HitsPerPage(cfg.Limit)}
+	if len(cfg.Fields) > 0 {
+		params = append(params, opt.RestrictSearchableAttributes(cfg.Fields...))
+	}
+
+	// Execute search
+	res, err := index.Search(prefix, params...)
+	if err != nil {
+		// Check if this is a timeout or cancellation error
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, searchx.ErrTimeout
+		}
+		if errors.Is(err, context.Canceled) {
+			return nil, searchx.ErrCanceled
+		}
+
+		return nil, errors.WithSecondaryError(
+			searchx.ErrBackendUnavailable,
+			errors.Wrapf(err, "Algolia suggestion search failed"),
+		)
+	}
+
+	suggestions := make([]searchx.Suggestion, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		if suggestion, ok := convertSuggestionHit(hit); ok {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	return suggestions, nil
+}
+
+// convertSuggestionHit converts a Query Suggestions record to a suggestion.
+// Records without a query are skipped.
+func convertSuggestionHit(hit map[string]interface{}) (searchx.Suggestion, bool) {
+	text, ok := hit["query"].(string)
+	if !ok || text == "" {
+		return searchx.Suggestion{}, false
+	}
+
+	var frequency int64
+	switch v := hit["popularity"].(type) {
+	case float64:
+		frequency = int64(v)
+	case int:
+		frequency = int64(v)
+	case int64:
+		frequency = v
+	}
+
+	return searchx.Suggestion{Text: text, Frequency: frequency}, true
+}
@@ -0,0 +1,87 @@
+package algolia
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestSuggesterInterface(t *testing.T) {
+	client := NewClient(StaticSecrets("test-app", "test-key"))
+	suggester := NewSuggester(client, "test-index_query_suggestions")
+
+	// This should compile if Suggester implements searchx.Suggester
+	var _ searchx.Suggester = suggester
+}
+
+func TestConvertSuggestionHit(t *testing.T) {
+	tests := map[string]struct {
+		hit        map[string]interface{}
+		expected   searchx.Suggestion
+		expectedOk bool
+	}{
+		"query_suggestions_record": {
+			hit:        map[string]interface{}{"objectID": "toyota camry", "query": "toyota camry", "popularity": float64(1520)},
+			expected:   searchx.Suggestion{Text: "toyota camry", Frequency: 1520},
+			expectedOk: true,
+		},
+		"missing_popularity": {
+			hit:        map[string]interface{}{"query": "tesla"},
+			expected:   searchx.Suggestion{Text: "tesla"},
+			expectedOk: true,
+		},
+		"missing_query": {
+			hit:        map[string]interface{}{"objectID": "1", "popularity": float64(3)},
+			expectedOk: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			suggestion, ok := convertSuggestionHit(tc.hit)
+			if ok != tc.expectedOk {
+				t.Fatalf("Expected ok %v, got %v", tc.expectedOk, ok)
+			}
+			if suggestion != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, suggestion)
+			}
+		})
+	}
+}
+
+func TestSuggestWithInvalidClient(t *testing.T) {
+	fetchSecrets := func() (Secrets, error) {
+		return Secrets{}, fmt.Errorf("failed to fetch secrets")
+	}
+	suggester := NewSuggester(NewClient(fetchSecrets), "test-index_query_suggestions")
+
+	_, err := suggester.Suggest(context.Background(), "toy")
+	if !errors.Is(err, searchx.ErrBackendUnavailable) {
+		t.Errorf("Expected ErrBackendUnavailable, got: %v", err)
+	}
+}
+
+func TestSuggestWithFilters(t *testing.T) {
+	client := NewClient(StaticSecrets("test-app", "test-key"))
+	suggester := NewSuggester(client, "test-index_query_suggestions")
+
+	_, err := suggester.Suggest(context.Background(), "toy", searchx.WithSuggestFilters(searchx.Eq("dealer", "a")))
+	if !errors.Is(err, searchx.ErrNotImplemented) {
+		t.Errorf("Expected ErrNotImplemented, got: %v", err)
+	}
+}
+
+func TestSuggestWithCanceledContext(t *testing.T) {
+	client := NewClient(StaticSecrets("test-app", "test-key"))
+	suggester := NewSuggester(client, "test-index_query_suggestions")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := suggester.Suggest(ctx, "toy"); err != searchx.ErrCanceled {
+		t.Errorf("Expected ErrCanceled, got: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package algolia
+
+import (
+	"strings"
+
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// convertSynonyms converts searchx synonyms to Algolia synonyms.
+// Synonyms without an ID get one derived from their type and words.
+func convertSynonyms(synonyms []searchx.Synonym) ([]search.Synonym, error) {
+	converted := make([]search.Synonym, 0, len(synonyms))
+	for _, syn := range synonyms {
+		id := syn.ID
+		if id == "" {
+			id = synonymID(syn)
+		}
+
+		switch syn.Type {
+		case searchx.SynonymTwoWay:
+			converted = append(converted, search.NewRegularSynonym(id, syn.Synonyms...))
+		case searchx.SynonymOneWay:
+			converted = append(converted, search.NewOneWaySynonym(id, syn.Input, syn.Synonyms...))
+		case searchx.SynonymPlaceholder:
+			converted = append(converted, search.NewPlaceholder(id, syn.Placeholder, syn.Synonyms...))
+		default:
+			return nil, errors.Wrapf(searchx.ErrInvalidOption, "unsupported synonym type %q", syn.Type)
+		}
+	}
+	return converted, nil
+}
+
+// synonymID derives a stable object ID for a synonym from its type and words.
+func synonymID(syn searchx.Synonym) string {
+	parts := []string{string(syn.Type)}
+	if syn.Input != "" {
+		parts = append(parts, syn.Input)
+	}
+	if syn.Placeholder != "" {
+		parts = append(parts, syn.Placeholder)
+	}
+	parts = append(parts, syn.Synonyms...)
+
+	id := strings.ToLower(strings.Join(parts, "-"))
+	return strings.Join(strings.Fields(id), "_")
+}
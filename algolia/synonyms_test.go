@@ -0,0 +1,51 @@
+package algolia
+
+import (
+	"testing"
+
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestConvertSynonyms(t *testing.T) {
+	synonyms := []searchx.Synonym{
+		searchx.TwoWaySynonym("chevy", "chevy", "chevrolet"),
+		searchx.OneWaySynonym("", "vw", "volkswagen"),
+		searchx.PlaceholderSynonym("trim", "<trim>", "amg", "sport"),
+	}
+
+	converted, err := convertSynonyms(synonyms)
+	if err != nil {
+		t.Fatalf("convertSynonyms failed: %v", err)
+	}
+
+	if len(converted) != 3 {
+		t.Fatalf("Expected 3 synonyms, got %d", len(converted))
+	}
+
+	tests := []struct {
+		expectedID   string
+		expectedType search.SynonymType
+	}{
+		{expectedID: "chevy", expectedType: search.RegularSynonymType},
+		{expectedID: "onewaysynonym-vw-volkswagen", expectedType: search.OneWaySynonymType},
+		{expectedID: "trim", expectedType: search.PlaceholderType},
+	}
+
+	for i, tt := range tests {
+		if converted[i].ObjectID() != tt.expectedID {
+			t.Errorf("Synonym %d: expected ID %q, got %q", i, tt.expectedID, converted[i].ObjectID())
+		}
+		if converted[i].Type() != tt.expectedType {
+			t.Errorf("Synonym %d: expected type %q, got %q", i, tt.expectedType, converted[i].Type())
+		}
+	}
+}
+
+func TestConvertSynonymsUnsupportedType(t *testing.T) {
+	_, err := convertSynonyms([]searchx.Synonym{{ID: "bad", Type: "unknown"}})
+	if !errors.Is(err, searchx.ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for unsupported synonym type, got: %v", err)
+	}
+}
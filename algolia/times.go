@@ -0,0 +1,47 @@
+package algolia
+
+import (
+	"time"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// epochSeconds converts a time value accepted by searchx.ParseTime to seconds
+// since the Unix epoch, the representation Algolia needs for numeric filters.
+func epochSeconds(value interface{}) (int64, bool) {
+	t, ok := searchx.ParseTime(value, time.Now())
+	if !ok {
+		return 0, false
+	}
+	return t.Unix(), true
+}
+
+// indexTimes returns a copy of an object with its time values, including
+// RFC 3339 strings, replaced by Unix epoch seconds so that they can be filtered
+// and sorted numerically. Nested objects and arrays are converted as well.
+func indexTimes(object map[string]interface{}) map[string]interface{} {
+	converted := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		converted[key] = indexTimeValue(value)
+	}
+	return converted
+}
+
+// indexTimeValue converts a single attribute value, see indexTimes.
+func indexTimeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return indexTimes(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = indexTimeValue(item)
+		}
+		return items
+	default:
+		if seconds, ok := epochSeconds(value); ok {
+			return seconds
+		}
+		return value
+	}
+}
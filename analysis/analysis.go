@@ -0,0 +1,98 @@
+// Package analysis provides text analyzers that turn field values and queries
+// into normalized tokens for full-text matching.
+package analysis
+
+// Token represents a single term produced by an analyzer.
+type Token struct {
+	// Term is the normalized text of the token.
+	Term string
+	// Position is the ordinal position of the token in the analyzed text.
+	// Filters that drop tokens keep the positions of the remaining ones,
+	// so phrase matching can account for the gaps.
+	Position int
+}
+
+// Analyzer converts text into a sequence of tokens.
+type Analyzer interface {
+	// Analyze tokenizes and normalizes the given text.
+	Analyze(text string) []Token
+}
+
+// Tokenizer splits text into tokens.
+type Tokenizer interface {
+	// Tokenize splits the given text into tokens with increasing positions.
+	Tokenize(text string) []Token
+}
+
+// TokenFilter transforms a sequence of tokens, e.g. by normalizing or removing them.
+type TokenFilter interface {
+	// Filter returns the transformed tokens.
+	Filter(tokens []Token) []Token
+}
+
+// Pipeline is an Analyzer made of a tokenizer followed by token filters.
+type Pipeline struct {
+	tokenizer Tokenizer
+	filters   []TokenFilter
+}
+
+// NewAnalyzer creates an analyzer that runs the tokenizer and then each filter in order.
+func NewAnalyzer(tokenizer Tokenizer, filters ...TokenFilter) *Pipeline {
+	return &Pipeline{
+		tokenizer: tokenizer,
+		filters:   filters,
+	}
+}
+
+// Analyze implements the Analyzer interface for Pipeline.
+func (p *Pipeline) Analyze(text string) []Token {
+	tokens := p.tokenizer.Tokenize(text)
+	for _, filter := range p.filters {
+		if len(tokens) == 0 {
+			break
+		}
+		tokens = filter.Filter(tokens)
+	}
+	return tokens
+}
+
+// AnalyzerFunc is a function type that implements the Analyzer interface.
+type AnalyzerFunc func(text string) []Token
+
+// Analyze implements the Analyzer interface for AnalyzerFunc.
+func (f AnalyzerFunc) Analyze(text string) []Token {
+	return f(text)
+}
+
+// Standard returns an analyzer that segments text into Unicode words,
+// lowercases them and folds diacritics, so "Citroën" matches "citroen".
+func Standard() *Pipeline {
+	return NewAnalyzer(UnicodeTokenizer{}, LowercaseFilter{}, FoldingFilter{})
+}
+
+// English returns the Standard analyzer followed by English stopword removal
+// and Porter stemming, so "trucks" matches "truck".
+func English() *Pipeline {
+	return NewAnalyzer(
+		UnicodeTokenizer{},
+		LowercaseFilter{},
+		FoldingFilter{},
+		NewStopwordFilter(EnglishStopwords...),
+		PorterStemFilter{},
+	)
+}
+
+// Keyword returns an analyzer that keeps the whole lowercased value as a single token.
+// It suits identifiers such as VINs or SKUs that should not be split.
+func Keyword() *Pipeline {
+	return NewAnalyzer(KeywordTokenizer{}, LowercaseFilter{})
+}
+
+// Terms returns the terms of the given tokens.
+func Terms(tokens []Token) []string {
+	terms := make([]string, len(tokens))
+	for i, token := range tokens {
+		terms[i] = token.Term
+	}
+	return terms
+}
@@ -0,0 +1,171 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnicodeTokenizer(t *testing.T) {
+	tests := map[string]struct {
+		text     string
+		expected []string
+	}{
+		"simple_words":      {text: "Go Programming Language", expected: []string{"Go", "Programming", "Language"}},
+		"punctuation":       {text: "hello-world_test.txt", expected: []string{"hello", "world", "test", "txt"}},
+		"email":             {text: "user@example.com", expected: []string{"user", "example", "com"}},
+		"decimal_number":    {text: "rated 4.5 stars", expected: []string{"rated", "4.5", "stars"}},
+		"thousands":         {text: "12,500 miles", expected: []string{"12,500", "miles"}},
+		"apostrophe":        {text: "driver's seat", expected: []string{"driver's", "seat"}},
+		"trailing_period":   {text: "Done.", expected: []string{"Done"}},
+		"diacritics":        {text: "Citroën C4", expected: []string{"Citroën", "C4"}},
+		"combining_marks":   {text: "Citroën", expected: []string{"Citroën"}},
+		"ideographs":        {text: "Hello 世界", expected: []string{"Hello", "世", "界"}},
+		"arabic":            {text: "مرحبا بك", expected: []string{"مرحبا", "بك"}},
+		"emoji":             {text: "🚀 launch", expected: []string{"🚀", "launch"}},
+		"only_separators":   {text: " -- ", expected: nil},
+		"empty":             {text: "", expected: nil},
+		"angle_placeholder": {text: "<trim>", expected: []string{"trim"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tokens := UnicodeTokenizer{}.Tokenize(tc.text)
+
+			got := Terms(tokens)
+			if len(got) == 0 && len(tc.expected) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+
+			for i, token := range tokens {
+				if token.Position != i {
+					t.Errorf("Expected token %d to have position %d, got %d", i, i, token.Position)
+				}
+			}
+		})
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := map[string]struct {
+		text     string
+		expected string
+	}{
+		"ascii":      {text: "citroen", expected: "citroen"},
+		"umlaut":     {text: "citroën", expected: "citroen"},
+		"accents":    {text: "café naïve", expected: "cafe naive"},
+		"cedilla":    {text: "français", expected: "francais"},
+		"eszett":     {text: "straße", expected: "strasse"},
+		"ligature":   {text: "cœur", expected: "coeur"},
+		"stroke":     {text: "Škoda Łódź", expected: "Skoda Lodz"},
+		"non_latin":  {text: "мир", expected: "мир"},
+		"ideographs": {text: "世界", expected: "世界"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := Fold(tc.text); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"trucks":         "truck",
+		"truck":          "truck",
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"conflated":      "conflat",
+		"hopping":        "hop",
+		"falling":        "fall",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"conditional":    "condit",
+		"generalization": "gener",
+		"electrical":     "electr",
+		"hopefulness":    "hope",
+		"adjustable":     "adjust",
+		"adoption":       "adopt",
+		"controlling":    "control",
+		"programming":    "program",
+		"sedans":         "sedan",
+		"go":             "go",
+		"4x4":            "4x4",
+		"citroën":        "citroën",
+	}
+
+	for word, expected := range tests {
+		t.Run(word, func(t *testing.T) {
+			if got := Stem(word); got != expected {
+				t.Errorf("Expected Stem(%q) = %q, got %q", word, expected, got)
+			}
+		})
+	}
+}
+
+func TestAnalyzers(t *testing.T) {
+	tests := map[string]struct {
+		analyzer  Analyzer
+		text      string
+		expected  []string
+		positions []int
+	}{
+		"standard": {
+			analyzer:  Standard(),
+			text:      "The Citroën TRUCKS",
+			expected:  []string{"the", "citroen", "trucks"},
+			positions: []int{0, 1, 2},
+		},
+		"english_stems_and_drops_stopwords": {
+			analyzer:  English(),
+			text:      "The Citroën TRUCKS",
+			expected:  []string{"citroen", "truck"},
+			positions: []int{1, 2},
+		},
+		"keyword": {
+			analyzer:  Keyword(),
+			text:      " 1HGCM82633A004352 ",
+			expected:  []string{"1hgcm82633a004352"},
+			positions: []int{0},
+		},
+		"custom_pipeline": {
+			analyzer:  NewAnalyzer(WhitespaceTokenizer{}, LowercaseFilter{}, NewStopwordFilter("of")),
+			text:      "Bank of AMERICA",
+			expected:  []string{"bank", "america"},
+			positions: []int{0, 2},
+		},
+		"func_analyzer": {
+			analyzer: AnalyzerFunc(func(text string) []Token {
+				return []Token{{Term: text, Position: 0}}
+			}),
+			text:      "as is",
+			expected:  []string{"as is"},
+			positions: []int{0},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tokens := tc.analyzer.Analyze(tc.text)
+			if got := Terms(tokens); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected terms %q, got %q", tc.expected, got)
+			}
+			for i, token := range tokens {
+				if i < len(tc.positions) && token.Position != tc.positions[i] {
+					t.Errorf("Expected token %q at position %d, got %d", token.Term, tc.positions[i], token.Position)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package analysis
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// LowercaseFilter lowercases every token.
+type LowercaseFilter struct{}
+
+// Filter implements the TokenFilter interface for LowercaseFilter.
+func (LowercaseFilter) Filter(tokens []Token) []Token {
+	for i := range tokens {
+		tokens[i].Term = strings.ToLower(tokens[i].Term)
+	}
+	return tokens
+}
+
+// foldingReplacer folds letters that do not decompose into a base letter and a mark.
+var foldingReplacer = strings.NewReplacer(
+	"ß", "ss",
+	"æ", "ae", "Æ", "AE",
+	"œ", "oe", "Œ", "OE",
+	"ø", "o", "Ø", "O",
+	"ł", "l", "Ł", "L",
+	"đ", "d", "Đ", "D",
+	"þ", "th", "Þ", "TH",
+)
+
+// FoldingFilter removes diacritics from tokens, so "Citroën" becomes "Citroen".
+type FoldingFilter struct{}
+
+// Filter implements the TokenFilter interface for FoldingFilter.
+func (FoldingFilter) Filter(tokens []Token) []Token {
+	for i := range tokens {
+		tokens[i].Term = Fold(tokens[i].Term)
+	}
+	return tokens
+}
+
+// Fold removes diacritics from text by decomposing it and dropping combining marks.
+func Fold(text string) string {
+	if isASCII(text) {
+		return text
+	}
+
+	folder := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(folder, text)
+	if err != nil {
+		return text
+	}
+	return foldingReplacer.Replace(folded)
+}
+
+// EnglishStopwords contains common English words that carry little meaning for search.
+var EnglishStopwords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in",
+	"into", "is", "it", "no", "not", "of", "on", "or", "such", "that", "the",
+	"their", "then", "there", "these", "they", "this", "to", "was", "will", "with",
+}
+
+// StopwordFilter removes stopwords from the token stream.
+// The positions of the remaining tokens are left untouched.
+type StopwordFilter struct {
+	words map[string]struct{}
+}
+
+// NewStopwordFilter creates a filter removing the given words.
+// Words are compared against tokens as-is, so it should run after lowercasing.
+func NewStopwordFilter(words ...string) StopwordFilter {
+	set := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		set[word] = struct{}{}
+	}
+	return StopwordFilter{words: set}
+}
+
+// Filter implements the TokenFilter interface for StopwordFilter.
+func (f StopwordFilter) Filter(tokens []Token) []Token {
+	kept := tokens[:0]
+	for _, token := range tokens {
+		if _, stop := f.words[token.Term]; !stop {
+			kept = append(kept, token)
+		}
+	}
+	return kept
+}
+
+// PorterStemFilter reduces English words to their stem using the Porter algorithm,
+// so "trucks" and "truck" share the stem "truck".
+// Tokens containing non-ASCII letters are left untouched.
+type PorterStemFilter struct{}
+
+// Filter implements the TokenFilter interface for PorterStemFilter.
+func (PorterStemFilter) Filter(tokens []Token) []Token {
+	for i := range tokens {
+		tokens[i].Term = Stem(tokens[i].Term)
+	}
+	return tokens
+}
+
+// isASCII reports whether text only contains ASCII characters.
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package analysis
+
+import (
+	"strings"
+	"unicode"
+)
+
+// UnicodeTokenizer splits text into words following a simplified form of the
+// Unicode word segmentation rules (UAX #29).
+//
+// Runs of letters, digits and combining marks form words. Apostrophes between
+// letters and decimal separators between digits are kept inside the word, so
+// "don't" and "4.5" stay whole. Ideographs and pictographic symbols such as
+// emoji become one token each. Everything else separates words.
+type UnicodeTokenizer struct{}
+
+// Tokenize implements the Tokenizer interface for UnicodeTokenizer.
+func (UnicodeTokenizer) Tokenize(text string) []Token {
+	runes := []rune(text)
+	var tokens []Token
+	var word strings.Builder
+
+	flush := func() {
+		if word.Len() == 0 {
+			return
+		}
+		tokens = append(tokens, Token{Term: word.String(), Position: len(tokens)})
+		word.Reset()
+	}
+
+	for i, r := range runes {
+		switch {
+		case isStandalone(r):
+			flush()
+			tokens = append(tokens, Token{Term: string(r), Position: len(tokens)})
+		case isWordRune(r):
+			word.WriteRune(r)
+		case word.Len() > 0 && i+1 < len(runes) && joinsWord(runes[i-1], r, runes[i+1]):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// KeywordTokenizer emits the whole trimmed text as a single token.
+type KeywordTokenizer struct{}
+
+// Tokenize implements the Tokenizer interface for KeywordTokenizer.
+func (KeywordTokenizer) Tokenize(text string) []Token {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	return []Token{{Term: text, Position: 0}}
+}
+
+// WhitespaceTokenizer splits text on Unicode whitespace only.
+type WhitespaceTokenizer struct{}
+
+// Tokenize implements the Tokenizer interface for WhitespaceTokenizer.
+func (WhitespaceTokenizer) Tokenize(text string) []Token {
+	fields := strings.Fields(text)
+	tokens := make([]Token, len(fields))
+	for i, field := range fields {
+		tokens[i] = Token{Term: field, Position: i}
+	}
+	return tokens
+}
+
+// isWordRune reports whether a rune is part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.M, r)
+}
+
+// isStandalone reports whether a rune forms a token on its own.
+// Scripts written without spaces between words and pictographic symbols are
+// emitted one rune at a time.
+func isStandalone(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana) || unicode.Is(unicode.So, r)
+}
+
+// joinsWord reports whether the separator r, found between prev and next, keeps
+// the surrounding runes in the same word.
+func joinsWord(prev, r, next rune) bool {
+	switch r {
+	case '\'', '’':
+		return unicode.IsLetter(prev) && unicode.IsLetter(next)
+	case '.', ',':
+		return unicode.IsDigit(prev) && unicode.IsDigit(next)
+	default:
+		return false
+	}
+}
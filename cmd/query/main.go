@@ -68,6 +68,10 @@ func main() {
 				Name:  "filter",
 				Usage: "Filter in field=value format; repeatable",
 			},
+			&cli.StringFlag{
+				Name:  "schema",
+				Usage: "Path to a JSON index schema used to validate filters and coerce their values",
+			},
 		},
 		Action: runAction,
 	}
@@ -111,6 +115,15 @@ func runAction(c *cli.Context) error {
 		return fmt.Errorf("invalid filter: %w", err)
 	}
 
+	var searcherOptions []algolia.SearcherOption
+	if schemaPath := strings.TrimSpace(c.String("schema")); schemaPath != "" {
+		schema, err := loadSchema(schemaPath)
+		if err != nil {
+			return fmt.Errorf("invalid schema: %w", err)
+		}
+		searcherOptions = append(searcherOptions, algolia.WithSchema(schema))
+	}
+
 	secretArn := strings.TrimSpace(c.String("algolia-secret-arn"))
 
 	var fetchSecrets algolia.FetchSecrets
@@ -130,7 +143,7 @@ func runAction(c *cli.Context) error {
 	defer cancel()
 
 	client := algolia.NewClient(fetchSecrets)
-	searcher := algolia.NewSearcher(client, indexName)
+	searcher := algolia.NewSearcher(client, indexName, searcherOptions...)
 
 	opts := []searchx.SearchOption{
 		searchx.WithLimit(limit),
@@ -188,6 +201,19 @@ func buildFilterOptions(raw []string) ([]searchx.SearchOption, error) {
 	return options, nil
 }
 
+func loadSchema(path string) (*searchx.Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var schema searchx.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &schema, nil
+}
+
 func printResults(res *searchx.Results) error {
 	if res == nil {
 		fmt.Println("{}")
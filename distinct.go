@@ -0,0 +1,22 @@
+package searchx
+
+// Distinct describes how results sharing a field value are collapsed.
+type Distinct struct {
+	// Field is the name of the field whose values group results.
+	Field string
+	// PerGroup is the number of results kept per group.
+	PerGroup int
+}
+
+// WithDistinct keeps only the best perGroup results for each value of a field,
+// e.g. one listing per model. Results without the field are never collapsed.
+// A perGroup below 1 keeps a single result per group.
+// The number of matches per value before collapsing is returned in Results.GroupCounts.
+func WithDistinct(field string, perGroup int) SearchOption {
+	return optionFunc(func(cfg *SearchConfig) {
+		if perGroup < 1 {
+			perGroup = 1
+		}
+		cfg.Distinct = &Distinct{Field: field, PerGroup: perGroup}
+	})
+}
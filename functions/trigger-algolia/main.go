@@ -15,16 +15,16 @@ import (
 )
 
 type Handler struct {
-	tableName     string
-	algoliaClient *algolia.Client
+	tableName string
+	objects   algolia.ObjectWriter
 }
 
-func NewHandler(tableName string, fetchSecrets algolia.FetchSecrets) *Handler {
-	algoliaClient := algolia.NewClient(fetchSecrets)
-
+// NewHandler creates a handler writing the records to objects, an
+// algolia.Client or an inmemory.Store.
+func NewHandler(tableName string, objects algolia.ObjectWriter) *Handler {
 	return &Handler{
-		tableName:     tableName,
-		algoliaClient: algoliaClient,
+		tableName: tableName,
+		objects:   objects,
 	}
 }
 
@@ -104,12 +104,12 @@ func (h *Handler) handleUpsert(ctx context.Context, record *ddb.Record) error {
 	algoliaObject["objectID"] = record.ID
 
 	slog.InfoContext(ctx, "Saving object to Algolia", "object_id", record.ID, "index", record.IndexName)
-	return h.algoliaClient.SaveObject(ctx, record.IndexName, algoliaObject)
+	return h.objects.SaveObject(ctx, record.IndexName, algoliaObject)
 }
 
 func (h *Handler) handleDelete(ctx context.Context, indexName, objectID string) error {
 	slog.InfoContext(ctx, "Deleting object from Algolia", "object_id", objectID, "index", indexName)
-	return h.algoliaClient.DeleteObject(ctx, indexName, objectID)
+	return h.objects.DeleteObject(ctx, indexName, objectID)
 }
 
 func main() {
@@ -178,7 +178,7 @@ func runAction(c *cli.Context) error {
 		fetchSecrets = algolia.EnvSecrets()
 	}
 
-	handler := NewHandler(tableName, fetchSecrets)
+	handler := NewHandler(tableName, algolia.NewClient(fetchSecrets))
 
 	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
 		slog.InfoContext(ctx, "Running in Lambda environment")
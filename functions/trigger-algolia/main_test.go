@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/letmevibethatforyou/searchx"
+	"github.com/letmevibethatforyou/searchx/algolia"
+	"github.com/letmevibethatforyou/searchx/inmemory"
+	"github.com/letmevibethatforyou/searchx/internal/ddb"
+)
+
+var _ algolia.ObjectWriter = (*inmemory.Store)(nil)
+
+// keys returns the keys of the record with the given ID in the given index.
+func keys(id, indexName string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: id},
+		"sk": &types.AttributeValueMemberS{Value: indexName},
+	}
+}
+
+// image returns the record with the given ID in the given index, holding the
+// given title and year.
+func image(id, indexName, title, year string) map[string]types.AttributeValue {
+	item := keys(id, indexName)
+	item["object"] = &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"title": &types.AttributeValueMemberS{Value: title},
+		"year":  &types.AttributeValueMemberN{Value: year},
+	}}
+	return item
+}
+
+func TestHandleDynamoDBEvent(t *testing.T) {
+	store := inmemory.NewStore()
+	handler := NewHandler("records", store)
+
+	event := ddb.DynamoDBEvent{Records: []ddb.DynamoDBEventRecord{
+		{EventName: "INSERT", Change: ddb.DynamoDBStreamRecord{NewImage: image("1", "cars", "Toyota Camry", "2018")}},
+		{EventName: "INSERT", Change: ddb.DynamoDBStreamRecord{NewImage: image("2", "cars", "Honda Civic", "2020")}},
+		{EventName: "INSERT", Change: ddb.DynamoDBStreamRecord{NewImage: image("3", "trucks", "Ford Ranger", "2019")}},
+		{EventName: "MODIFY", Change: ddb.DynamoDBStreamRecord{NewImage: image("1", "cars", "Toyota Corolla", "2018")}},
+		{EventName: "REMOVE", Change: ddb.DynamoDBStreamRecord{Keys: keys("2", "cars")}},
+		{EventName: "INSERT", Change: ddb.DynamoDBStreamRecord{NewImage: keys("4", "cars")}},
+		{EventName: "INSERT", Change: ddb.DynamoDBStreamRecord{NewImage: image("", "cars", "Kia Rio", "2021")}},
+	}}
+	if err := handler.HandleDynamoDBEvent(context.Background(), event); err != nil {
+		t.Fatalf("HandleDynamoDBEvent failed: %v", err)
+	}
+
+	tests := map[string]struct {
+		index    string
+		query    string
+		opts     []searchx.SearchOption
+		expected []string
+	}{
+		"inserted":  {index: "trucks", query: "ranger", expected: []string{"3"}},
+		"modified":  {index: "cars", query: "corolla", expected: []string{"1"}},
+		"replaced":  {index: "cars", query: "camry", expected: []string{}},
+		"removed":   {index: "cars", query: "civic", expected: []string{}},
+		"skipped":   {index: "cars", query: "kia", expected: []string{}},
+		"all_cars":  {index: "cars", expected: []string{"1"}},
+		"numbers":   {index: "cars", opts: []searchx.SearchOption{searchx.Eq("year", 2018)}, expected: []string{"1"}},
+		"separated": {index: "trucks", query: "toyota", expected: []string{}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := store.Index(tc.index).Search(context.Background(), tc.query, tc.opts...)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if len(results.Items) != len(tc.expected) {
+				t.Fatalf("Expected %d results, got %d", len(tc.expected), len(results.Items))
+			}
+			for i, id := range tc.expected {
+				if results.Items[i].ID != id {
+					t.Errorf("At index %d: expected ID %s, got %s", i, id, results.Items[i].ID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package inmemory
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// validateAggregations checks aggregation requests before searching.
+func validateAggregations(requests []searchx.AggregationRequest) error {
+	for _, req := range requests {
+		switch req.Type {
+		case searchx.AggStats:
+		case searchx.AggHistogram:
+			if req.Interval <= 0 || math.IsInf(req.Interval, 0) || math.IsNaN(req.Interval) {
+				return errors.Wrapf(searchx.ErrInvalidOption, "histogram interval on %q must be positive, got %v", req.Field, req.Interval)
+			}
+		case searchx.AggRange:
+			for _, r := range req.Ranges {
+				if !isOptionalNumber(r.From) || !isOptionalNumber(r.To) {
+					return errors.Wrapf(searchx.ErrInvalidOption, "range bounds on %q must be numbers or nil", req.Field)
+				}
+			}
+		default:
+			return errors.Wrapf(searchx.ErrInvalidOption, "unsupported aggregation type %q", req.Type)
+		}
+	}
+	return nil
+}
+
+// isOptionalNumber reports whether v is nil or a number.
+func isOptionalNumber(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	_, ok := toFloat64(v)
+	return ok
+}
+
+// computeAggregations computes the requested aggregations over the matched documents.
+func computeAggregations(matches []scoredDocument, requests []searchx.AggregationRequest) map[string]*searchx.Aggregation {
+	aggregations := make(map[string]*searchx.Aggregation, len(requests))
+	for _, req := range requests {
+		var values []float64
+		for _, match := range matches {
+			values = appendNumericValues(values, match.document.Fields[req.Field])
+		}
+
+		agg, ok := aggregations[req.Field]
+		if !ok {
+			agg = &searchx.Aggregation{}
+			aggregations[req.Field] = agg
+		}
+
+		switch req.Type {
+		case searchx.AggStats:
+			agg.Stats = computeStats(values)
+		case searchx.AggHistogram:
+			agg.Histogram = computeHistogram(values, req.Interval)
+		case searchx.AggRange:
+			agg.Ranges = computeRanges(values, req.Ranges)
+		}
+	}
+	return aggregations
+}
+
+// appendNumericValues appends the numbers of a field value, including those of an array.
+func appendNumericValues(values []float64, value interface{}) []float64 {
+	if items, ok := value.([]interface{}); ok {
+		for _, item := range items {
+			if v, ok := toFloat64(item); ok {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	if v, ok := toFloat64(value); ok {
+		values = append(values, v)
+	}
+	return values
+}
+
+// computeStats summarizes values.
+func computeStats(values []float64) *searchx.Stats {
+	stats := &searchx.Stats{Count: int64(len(values))}
+	if len(values) == 0 {
+		return stats
+	}
+
+	stats.Min, stats.Max = values[0], values[0]
+	for _, v := range values {
+		stats.Min = min(stats.Min, v)
+		stats.Max = max(stats.Max, v)
+		stats.Sum += v
+	}
+	stats.Avg = stats.Sum / float64(len(values))
+	return stats
+}
+
+// computeHistogram counts values in buckets of the given width, returning the
+// non-empty buckets in ascending order.
+func computeHistogram(values []float64, interval float64) []searchx.Bucket {
+	counts := make(map[float64]int64)
+	for _, v := range values {
+		counts[math.Floor(v/interval)*interval]++
+	}
+
+	starts := make([]float64, 0, len(counts))
+	for start := range counts {
+		starts = append(starts, start)
+	}
+	sort.Float64s(starts)
+
+	buckets := make([]searchx.Bucket, len(starts))
+	for i, start := range starts {
+		buckets[i] = searchx.Bucket{
+			Key:   formatBound(start),
+			From:  start,
+			To:    start + interval,
+			Count: counts[start],
+		}
+	}
+	return buckets
+}
+
+// computeRanges counts values in each range, from inclusive to exclusive.
+func computeRanges(values []float64, ranges []searchx.AggregationRange) []searchx.Bucket {
+	buckets := make([]searchx.Bucket, len(ranges))
+	for i, r := range ranges {
+		from, hasFrom := toFloat64(r.From)
+		to, hasTo := toFloat64(r.To)
+
+		bucket := searchx.Bucket{Key: r.Key, From: r.From, To: r.To}
+		if bucket.Key == "" {
+			bucket.Key = rangeKey(r.From, r.To)
+		}
+		for _, v := range values {
+			if (!hasFrom || v >= from) && (!hasTo || v < to) {
+				bucket.Count++
+			}
+		}
+		buckets[i] = bucket
+	}
+	return buckets
+}
+
+// rangeKey returns the default key of a range, "*" standing for an open end.
+func rangeKey(from, to interface{}) string {
+	bound := func(v interface{}) string {
+		if f, ok := toFloat64(v); ok {
+			return formatBound(f)
+		}
+		return "*"
+	}
+	return bound(from) + "-" + bound(to)
+}
+
+// formatBound formats a bucket bound without trailing zeros.
+func formatBound(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,117 @@
+package inmemory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestAggregations(t *testing.T) {
+	searcher := New()
+
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"make": "Toyota", "price": 18000.0, "mileage": 42000}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"make": "Toyota", "price": 24500.0, "mileage": 15000}})
+	searcher.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"make": "Honda", "price": 21000.0, "mileage": 30000}})
+	searcher.AddDocument(Document{ID: "4", Fields: map[string]interface{}{"make": "Tesla", "price": 39990.0}})
+	searcher.AddDocument(Document{ID: "5", Fields: map[string]interface{}{"make": "Toyota", "price": "call"}})
+
+	ctx := context.Background()
+
+	t.Run("stats_over_filtered_set", func(t *testing.T) {
+		results, err := searcher.Search(ctx, "toyota", searchx.WithStats("price"), searchx.WithLimit(1))
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		expected := &searchx.Stats{Count: 2, Min: 18000, Max: 24500, Avg: 21250, Sum: 42500}
+		agg := results.Aggregations["price"]
+		if agg == nil || !reflect.DeepEqual(agg.Stats, expected) {
+			t.Errorf("Expected stats %+v, got %+v", expected, agg)
+		}
+	})
+
+	t.Run("stats_without_values", func(t *testing.T) {
+		results, err := searcher.Search(ctx, "nothing", searchx.WithStats("price"))
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if stats := results.Aggregations["price"].Stats; stats.Count != 0 {
+			t.Errorf("Expected empty stats, got %+v", stats)
+		}
+	})
+
+	t.Run("histogram", func(t *testing.T) {
+		results, err := searcher.Search(ctx, "", searchx.WithHistogram("price", 10000))
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		expected := []searchx.Bucket{
+			{Key: "10000", From: 10000.0, To: 20000.0, Count: 1},
+			{Key: "20000", From: 20000.0, To: 30000.0, Count: 2},
+			{Key: "30000", From: 30000.0, To: 40000.0, Count: 1},
+		}
+		if got := results.Aggregations["price"].Histogram; !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected histogram %+v, got %+v", expected, got)
+		}
+	})
+
+	t.Run("range_buckets", func(t *testing.T) {
+		results, err := searcher.Search(ctx, "",
+			searchx.WithRangeBuckets("mileage",
+				searchx.AggregationRange{Key: "low", To: 20000},
+				searchx.AggregationRange{From: 20000, To: 40000},
+				searchx.AggregationRange{From: 40000},
+			),
+			searchx.WithStats("mileage"),
+		)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+
+		expected := []searchx.Bucket{
+			{Key: "low", To: 20000, Count: 1},
+			{Key: "20000-40000", From: 20000, To: 40000, Count: 1},
+			{Key: "40000-*", From: 40000, Count: 1},
+		}
+		agg := results.Aggregations["mileage"]
+		if !reflect.DeepEqual(agg.Ranges, expected) {
+			t.Errorf("Expected ranges %+v, got %+v", expected, agg.Ranges)
+		}
+		if agg.Stats == nil || agg.Stats.Count != 3 {
+			t.Errorf("Expected stats alongside ranges, got %+v", agg.Stats)
+		}
+	})
+
+	t.Run("not_requested", func(t *testing.T) {
+		results, err := searcher.Search(ctx, "toyota")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if results.Aggregations != nil {
+			t.Errorf("Expected no aggregations, got %+v", results.Aggregations)
+		}
+	})
+}
+
+func TestInvalidAggregations(t *testing.T) {
+	searcher := New()
+
+	tests := map[string]searchx.SearchOption{
+		"zero_interval":     searchx.WithHistogram("price", 0),
+		"negative_interval": searchx.WithHistogram("price", -5),
+		"non_numeric_range": searchx.WithRangeBuckets("price", searchx.AggregationRange{From: "cheap"}),
+	}
+
+	for name, opt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := searcher.Search(context.Background(), "", opt)
+			if !errors.Is(err, searchx.ErrInvalidOption) {
+				t.Errorf("Expected ErrInvalidOption, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package inmemory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/letmevibethatforyou/searchx/analysis"
+)
+
+// Option configures a Searcher.
+type Option func(*Searcher)
+
+// WithAnalyzer sets the analyzer used for fields without a field-specific analyzer.
+// It defaults to analysis.Standard().
+func WithAnalyzer(analyzer analysis.Analyzer) Option {
+	return func(s *Searcher) {
+		s.analyzer = analyzer
+	}
+}
+
+// WithFieldAnalyzer sets the analyzer used for a top-level field, overriding the
+// default analyzer. For example, analysis.English() on a description field makes
+// "trucks" match "truck", while analysis.Keyword() keeps a VIN whole.
+func WithFieldAnalyzer(field string, analyzer analysis.Analyzer) Option {
+	return func(s *Searcher) {
+		if s.fieldAnalyzers == nil {
+			s.fieldAnalyzers = make(map[string]analysis.Analyzer)
+		}
+		s.fieldAnalyzers[field] = analyzer
+	}
+}
+
+// defaultAnalyzerKey identifies the default analyzer in analyzed query terms.
+const defaultAnalyzerKey = ""
+
+// analyzerFor returns the analyzer for a field along with the key identifying
+// it in analyzed query terms.
+func (s *Searcher) analyzerFor(field string) (analysis.Analyzer, string) {
+	if analyzer, ok := s.fieldAnalyzers[field]; ok {
+		return analyzer, field
+	}
+	return s.analyzer, defaultAnalyzerKey
+}
+
+// analyzeTerm analyzes a query term and its alternatives with the default
+// analyzer and every field analyzer. Alternatives that produce no tokens are dropped.
+func (s *Searcher) analyzeTerm(term *queryTerm) {
+	term.phrases = map[string][][]analysis.Token{
+		defaultAnalyzerKey: analyzeAlternatives(s.analyzer, term.alternatives),
+	}
+	for field, analyzer := range s.fieldAnalyzers {
+		term.phrases[field] = analyzeAlternatives(analyzer, term.alternatives)
+	}
+}
+
+// analyzeAlternatives analyzes each alternative, dropping those that produce no tokens.
+func analyzeAlternatives(analyzer analysis.Analyzer, alternatives []string) [][]analysis.Token {
+	phrases := make([][]analysis.Token, 0, len(alternatives))
+	for _, alt := range alternatives {
+		if tokens := analyzer.Analyze(alt); len(tokens) > 0 {
+			phrases = append(phrases, tokens)
+		}
+	}
+	return phrases
+}
+
+// analyzeValue analyzes a field value. Arrays and nested objects are flattened,
+// leaving a gap in positions between elements so phrases never span two of them.
+// Non-string values are analyzed in their default string format.
+func analyzeValue(analyzer analysis.Analyzer, value interface{}) []analysis.Token {
+	var tokens []analysis.Token
+
+	var visit func(v interface{})
+	visit = func(v interface{}) {
+		var analyzed []analysis.Token
+		switch val := v.(type) {
+		case string:
+			analyzed = analyzer.Analyze(val)
+		case []interface{}:
+			for _, item := range val {
+				visit(item)
+			}
+			return
+		case map[string]interface{}:
+			for _, item := range val {
+				visit(item)
+			}
+			return
+		default:
+			analyzed = analyzer.Analyze(fmt.Sprintf("%v", val))
+		}
+
+		offset := 0
+		if len(tokens) > 0 {
+			offset = tokens[len(tokens)-1].Position + 2
+		}
+		for _, token := range analyzed {
+			token.Position += offset
+			tokens = append(tokens, token)
+		}
+	}
+	visit(value)
+
+	return tokens
+}
+
+// phraseOccurrences calls fn for every occurrence of the phrase in the tokens,
+// that is with the same relative positions, with the indexes of the matching
+// tokens. Every phrase term must match exactly except the last, which may match
+// as a prefix so partially typed words still find results, unless exact is set.
+// It stops when fn returns false. The indexes are only valid during the call.
+func phraseOccurrences(tokens []analysis.Token, phrase []analysis.Token, exact bool, fn func(matched []int) bool) {
+	if len(phrase) == 0 {
+		return
+	}
+
+	last := len(phrase) - 1
+	matchedTokens := make([]int, len(phrase))
+	for i, start := range tokens {
+		if !termMatches(start.Term, phrase[0].Term, !exact && last == 0) {
+			continue
+		}
+
+		matchedTokens[0] = i
+		matched := true
+		j := i
+		for k := 1; k <= last && matched; k++ {
+			want := start.Position + phrase[k].Position - phrase[0].Position
+			for j < len(tokens) && tokens[j].Position < want {
+				j++
+			}
+			matched = j < len(tokens) && tokens[j].Position == want &&
+				termMatches(tokens[j].Term, phrase[k].Term, !exact && k == last)
+			matchedTokens[k] = j
+		}
+		if matched && !fn(matchedTokens) {
+			return
+		}
+	}
+}
+
+// termMatches reports whether a document term matches a query term.
+func termMatches(docTerm, term string, prefix bool) bool {
+	if prefix {
+		return strings.HasPrefix(docTerm, term)
+	}
+	return docTerm == term
+}
@@ -0,0 +1,127 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+	"github.com/letmevibethatforyou/searchx/analysis"
+)
+
+func TestAnalyzedSearch(t *testing.T) {
+	docs := []Document{
+		{ID: "1", Fields: map[string]interface{}{"make": "Citroën", "description": "Compact hatchback"}},
+		{ID: "2", Fields: map[string]interface{}{"make": "Ford", "description": "Pickup trucks for hauling"}},
+		{ID: "3", Fields: map[string]interface{}{"make": "Ford", "description": "Hauling truck"}},
+		{ID: "4", Fields: map[string]interface{}{"make": "Tesla", "description": "Electric car with autopilot"}},
+	}
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		opts     []Option
+		query    string
+		expected []string
+	}{
+		"diacritics_folded": {
+			query:    "citroen",
+			expected: []string{"1"},
+		},
+		"diacritics_in_query": {
+			query:    "CITROËN",
+			expected: []string{"1"},
+		},
+		"prefix_of_last_token": {
+			query:    "hatch",
+			expected: []string{"1"},
+		},
+		"no_match_inside_words": {
+			query:    "back",
+			expected: []string{},
+		},
+		"standard_does_not_stem": {
+			query:    "trucks",
+			expected: []string{"2"},
+		},
+		"english_field_analyzer_stems": {
+			opts:     []Option{WithFieldAnalyzer("description", analysis.English())},
+			query:    "trucks",
+			expected: []string{"2", "3"},
+		},
+		"english_analyzer_drops_stopwords": {
+			opts:     []Option{WithAnalyzer(analysis.English())},
+			query:    "the electric car",
+			expected: []string{"4"},
+		},
+		"multiple_terms": {
+			query:    "pickup trucks",
+			expected: []string{"2"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			searcher := New(tc.opts...)
+			for _, doc := range docs {
+				searcher.AddDocument(doc)
+			}
+
+			// Typos are not tolerated, so that only analysis makes terms match
+			results, err := searcher.Search(ctx, tc.query, searchx.WithTypoTolerance(false))
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			ids := make([]string, 0, len(results.Items))
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+			}
+			sort.Strings(ids)
+
+			if len(ids) != len(tc.expected) {
+				t.Fatalf("Expected %v, got %v", tc.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != tc.expected[i] {
+					t.Errorf("Expected %v, got %v", tc.expected, ids)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestPhraseOccurrences(t *testing.T) {
+	analyzer := analysis.Standard()
+
+	tests := map[string]struct {
+		value    interface{}
+		phrase   string
+		expected bool
+	}{
+		"single_term":        {value: "Toyota Camry", phrase: "camry", expected: true},
+		"consecutive_terms":  {value: "new york city", phrase: "new york", expected: true},
+		"out_of_order":       {value: "york new", phrase: "new york", expected: false},
+		"gap_between_terms":  {value: "new big york", phrase: "new york", expected: false},
+		"last_term_prefix":   {value: "new york", phrase: "new yo", expected: true},
+		"first_term_exact":   {value: "newer york", phrase: "new york", expected: false},
+		"across_array_items": {value: []interface{}{"new", "york"}, phrase: "new york", expected: false},
+		"within_array_item":  {value: []interface{}{"boston", "new york"}, phrase: "new york", expected: true},
+		"empty_phrase":       {value: "anything", phrase: "--", expected: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tokens := analyzeValue(analyzer, tc.value)
+			got := false
+			phraseOccurrences(tokens, analyzer.Analyze(tc.phrase), false, func([]int) bool {
+				got = true
+				return false
+			})
+			if got != tc.expected {
+				t.Errorf("Expected %v for %q in %v, got %v", tc.expected, tc.phrase, tc.value, got)
+			}
+		})
+	}
+}
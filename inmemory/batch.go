@@ -0,0 +1,74 @@
+package inmemory
+
+// Batch is a list of changes to the documents, applied in order under a single
+// write lock by Apply. The zero value is an empty batch.
+type Batch struct {
+	changes []walEntry
+}
+
+// Add queues adding a document, replacing any document with the same ID.
+func (b *Batch) Add(doc Document) {
+	b.changes = append(b.changes, walEntry{Op: walAdd, ID: doc.ID, Fields: doc.Fields})
+}
+
+// Remove queues removing a document by ID. Removing a missing document does nothing.
+func (b *Batch) Remove(id string) {
+	b.changes = append(b.changes, walEntry{Op: walRemove, ID: id})
+}
+
+// Len returns the number of changes in the batch.
+func (b *Batch) Len() int {
+	return len(b.changes)
+}
+
+// Apply makes the changes of a batch in order under a single write lock, and
+// publishes them at once so that searches see either none or all of them. Changes
+// are logged to the write-ahead log one by one, like those made individually.
+// This method is safe for concurrent use.
+func (s *Searcher) Apply(batch *Batch) {
+	s.lock()
+	defer s.unlock()
+
+	for _, change := range batch.changes {
+		if change.Op == walRemove {
+			if !s.contains(change.ID) {
+				continue
+			}
+		}
+		s.logChange(change)
+		s.applyChange(change)
+	}
+	s.publish()
+}
+
+// AddDocuments adds documents under a single write lock, replacing those with
+// the same IDs. This method is safe for concurrent use.
+func (s *Searcher) AddDocuments(docs []Document) {
+	s.lock()
+	defer s.unlock()
+
+	for _, doc := range docs {
+		s.logChange(walEntry{Op: walAdd, ID: doc.ID, Fields: doc.Fields})
+		s.addDocument(doc)
+	}
+	s.publish()
+}
+
+// RemoveDocuments removes documents by ID under a single write lock. It returns
+// the number of documents found and removed. This method is safe for concurrent use.
+func (s *Searcher) RemoveDocuments(ids []string) int {
+	s.lock()
+	defer s.unlock()
+
+	removed := 0
+	for _, id := range ids {
+		if !s.contains(id) {
+			continue
+		}
+		s.logChange(walEntry{Op: walRemove, ID: id})
+		s.removeDocument(id)
+		removed++
+	}
+	s.publish()
+	return removed
+}
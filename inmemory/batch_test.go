@@ -0,0 +1,119 @@
+package inmemory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestApply(t *testing.T) {
+	var wal bytes.Buffer
+	searcher := New(WithWAL(&wal))
+	searcher.AddDocuments([]Document{
+		{ID: "1", Fields: map[string]interface{}{"make": "Toyota"}},
+		{ID: "2", Fields: map[string]interface{}{"make": "Ford"}},
+	})
+
+	var batch Batch
+	batch.Add(Document{ID: "3", Fields: map[string]interface{}{"make": "Honda"}})
+	batch.Remove("1")
+	batch.Remove("missing")
+	batch.Add(Document{ID: "2", Fields: map[string]interface{}{"make": "Lincoln"}})
+	batch.Add(Document{ID: "1", Fields: map[string]interface{}{"make": "Lexus"}})
+	if batch.Len() != 5 {
+		t.Errorf("Expected 5 changes, got %d", batch.Len())
+	}
+	searcher.Apply(&batch)
+
+	if got := documentIDs(searcher); got != "[2 3 1]" {
+		t.Errorf("Expected documents [2 3 1], got %v", got)
+	}
+	if got := searcher.documentByID("2").Fields["make"]; got != "Lincoln" {
+		t.Errorf("Expected document 2 to be updated, got %v", got)
+	}
+
+	// The missing document is not logged
+	if lines := strings.Count(wal.String(), "\n"); lines != 6 {
+		t.Errorf("Expected 6 logged changes, got %d", lines)
+	}
+	replayed := New()
+	if err := replayed.ReplayWAL(&wal); err != nil {
+		t.Fatalf("ReplayWAL failed: %v", err)
+	}
+	if got := documentIDs(replayed); got != "[2 3 1]" {
+		t.Errorf("Expected replayed documents [2 3 1], got %v", got)
+	}
+}
+
+func TestRemoveDocuments(t *testing.T) {
+	tests := map[string]struct {
+		total  int
+		remove func(i int) bool
+	}{
+		"few":        {total: 10, remove: func(i int) bool { return i%3 == 0 }},
+		"compaction": {total: 4 * minCompaction, remove: func(i int) bool { return i%4 != 1 }},
+		"all":        {total: 2 * minCompaction, remove: func(i int) bool { return true }},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			searcher := New(WithSchema(searchx.NewSchema(
+				searchx.Field{Name: "year", Type: searchx.FieldNumber, Filterable: true},
+			)))
+			docs := make([]Document, tc.total)
+			for i := range docs {
+				docs[i] = Document{ID: fmt.Sprint(i), Fields: map[string]interface{}{"year": 2000 + i%20}}
+			}
+			searcher.AddDocuments(docs)
+
+			var ids, kept []string
+			for i := 0; i < tc.total; i++ {
+				if tc.remove(i) {
+					ids = append(ids, fmt.Sprint(i))
+				} else if 2000+i%20 >= 2010 {
+					kept = append(kept, fmt.Sprint(i))
+				}
+			}
+			ids = append(ids, "missing")
+
+			if removed := searcher.RemoveDocuments(ids); removed != len(ids)-1 {
+				t.Errorf("Expected %d documents removed, got %d", len(ids)-1, removed)
+			}
+			if searcher.Size()+len(ids)-1 != tc.total {
+				t.Errorf("Expected size %d, got %d", tc.total-len(ids)+1, searcher.Size())
+			}
+
+			// Searches see the remaining documents in insertion order, through
+			// the field indexes
+			results, err := searcher.Search(context.Background(), "",
+				searchx.Gte("year", 2010),
+				searchx.WithLimit(tc.total),
+			)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			var got []string
+			for _, item := range results.Items {
+				got = append(got, item.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(kept) {
+				t.Errorf("Expected %d documents %v, got %d %v", len(kept), kept, len(got), got)
+			}
+		})
+	}
+}
+
+// documentIDs returns the IDs of the documents of a searcher in insertion order.
+func documentIDs(s *Searcher) string {
+	var ids []string
+	for pos, doc := range s.documents.all() {
+		if !s.tombstones.get(pos) {
+			ids = append(ids, doc.ID)
+		}
+	}
+	return fmt.Sprint(ids)
+}
@@ -0,0 +1,120 @@
+package inmemory
+
+import "math/bits"
+
+// bitmap is a set of document positions, one bit per position.
+type bitmap []uint64
+
+// newBitmap creates an empty bitmap holding positions below n.
+func newBitmap(n int) bitmap {
+	return make(bitmap, (n+63)/64)
+}
+
+// grow returns the bitmap extended to hold positions below n.
+func (b bitmap) grow(n int) bitmap {
+	for len(b) < (n+63)/64 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// set adds a position to the bitmap.
+func (b bitmap) set(i int) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+// has reports whether the bitmap contains a position.
+func (b bitmap) has(i int) bool {
+	return i/64 < len(b) && b[i/64]&(1<<(i%64)) != 0
+}
+
+// and keeps the positions also in other. Both bitmaps must have the same size.
+func (b bitmap) and(other bitmap) {
+	for i := range b {
+		b[i] &= other[i]
+	}
+}
+
+// or adds the positions of other. Both bitmaps must have the same size.
+func (b bitmap) or(other bitmap) {
+	for i := range b {
+		b[i] |= other[i]
+	}
+}
+
+// not replaces the positions below n with those missing from the bitmap.
+func (b bitmap) not(n int) {
+	for i := range b {
+		b[i] = ^b[i]
+	}
+	if rest := n % 64; rest != 0 {
+		b[len(b)-1] &= 1<<rest - 1
+	}
+}
+
+// count returns the number of positions in the bitmap.
+func (b bitmap) count() int {
+	n := 0
+	for _, word := range b {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// forEach calls fn for every position in ascending order, stopping when fn returns false.
+func (b bitmap) forEach(fn func(i int) bool) {
+	for w, word := range b {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			if !fn(w*64 + bit) {
+				return
+			}
+			word &= word - 1
+		}
+	}
+}
+
+// positionSet is a persistent set of document positions. It holds the non-zero
+// words of a bitmap by index, so that sets of few positions stay small.
+type positionSet struct {
+	words pmap[int, uint64]
+}
+
+// add adds a position to the set in generation gen.
+func (p *positionSet) add(gen uint64, i int) {
+	word, _ := p.words.get(i / 64)
+	p.words.set(gen, i/64, word|1<<(i%64))
+}
+
+// remove removes a position from the set in generation gen.
+func (p *positionSet) remove(gen uint64, i int) {
+	word, _ := p.words.get(i / 64)
+	if word &^= 1 << (i % 64); word == 0 {
+		p.words.delete(gen, i/64)
+	} else {
+		p.words.set(gen, i/64, word)
+	}
+}
+
+// empty reports whether the set has no positions.
+func (p *positionSet) empty() bool {
+	return p.words.len() == 0
+}
+
+// addTo adds the positions of the set to a bitmap large enough to hold them.
+func (p *positionSet) addTo(b bitmap) {
+	for w, word := range p.words.all() {
+		b[w] |= word
+	}
+}
+
+// forEach calls fn for every position of the set, in no particular order.
+func (p *positionSet) forEach(fn func(i int)) {
+	for w, word := range p.words.all() {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			fn(w*64 + bit)
+			word &= word - 1
+		}
+	}
+}
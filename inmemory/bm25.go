@@ -0,0 +1,88 @@
+package inmemory
+
+import (
+	"math"
+
+	"github.com/letmevibethatforyou/searchx/analysis"
+)
+
+const (
+	// defaultBM25K1 is the default term frequency saturation of BM25.
+	defaultBM25K1 = 1.2
+	// defaultBM25B is the default strength of BM25 length normalization.
+	defaultBM25B = 0.75
+)
+
+// WithBM25 sets the parameters of BM25 relevance scoring. k1 controls how
+// quickly repeated occurrences of a term stop adding to the score, and b how
+// much matches in long field values are discounted, from 0 (not at all) to 1
+// (proportionally to their length). They default to 1.2 and 0.75.
+func WithBM25(k1, b float64) Option {
+	return func(s *Searcher) {
+		s.k1 = k1
+		s.b = b
+	}
+}
+
+// corpus holds the inverted indexes BM25 statistics are computed over: those
+// of every shard of a version, so that sharding leaves scores unchanged.
+type corpus []*invertedIndex
+
+// corpus returns the indexes of the shards of the searcher.
+func (s *Searcher) corpus() corpus {
+	shards := s.readShards()
+	c := make(corpus, len(shards))
+	for i, shard := range shards {
+		c[i] = shard.index
+	}
+	return c
+}
+
+// bm25 scores a term matching a field value of length tokens with frequency tf,
+// its rarity being measured by idf.
+func (s *Searcher) bm25(c corpus, field string, tf, idf float64, length int) float64 {
+	norm := 1.0
+	if avg := c.averageLength(field); avg > 0 {
+		norm = 1 - s.b + s.b*float64(length)/avg
+	}
+	return idf * tf * (s.k1 + 1) / (tf + s.k1*norm)
+}
+
+// idf returns the inverse document frequency of a term in a field, computed
+// over the indexed documents having the field. Terms occurring in fewer
+// documents are rarer and weigh more. It is always positive.
+func (c corpus) idf(field, term string) float64 {
+	n, df := 0.0, 0.0
+	for _, idx := range c {
+		n += float64(idx.fieldDocs[field])
+		if node := idx.terms.find(term); node != nil {
+			df += float64(node.fields[field])
+		}
+	}
+	df = math.Min(df, n)
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// phraseIDF returns the inverse document frequency of the tokens matched by a
+// phrase, summing those of its words like the terms of a Lucene phrase query.
+func (c corpus) phraseIDF(field string, tokens []analysis.Token, matched []int) float64 {
+	idf := 0.0
+	for _, i := range matched {
+		idf += c.idf(field, tokens[i].Term)
+	}
+	return idf
+}
+
+// averageLength returns the average number of tokens of a field over the
+// indexed documents having it, or 0 when there are none.
+func (c corpus) averageLength(field string) float64 {
+	docs, length := 0, 0
+	for _, idx := range c {
+		docs += idx.fieldDocs[field]
+		length += idx.fieldLengths[field]
+	}
+	if docs == 0 {
+		return 0
+	}
+	return float64(length) / float64(docs)
+}
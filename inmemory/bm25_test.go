@@ -0,0 +1,109 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestBM25Ranking(t *testing.T) {
+	tests := map[string]struct {
+		opts     []Option
+		contents []string
+		query    string
+		expected []string // IDs in ranking order, nil when every match ties
+	}{
+		"rare_terms_weigh_more": {
+			contents: []string{"the mustang the the", "corvette", "the camry", "the civic"},
+			query:    "the corvette",
+			expected: []string{"2", "1", "3", "4"},
+		},
+		"term_frequency": {
+			contents: []string{"red car", "red red car"},
+			query:    "red",
+			expected: []string{"2", "1"},
+		},
+		"length_normalization": {
+			contents: []string{"red car for sale in portland oregon today", "red car"},
+			query:    "red",
+			expected: []string{"2", "1"},
+		},
+		"no_length_normalization": {
+			opts:     []Option{WithBM25(1.2, 0)},
+			contents: []string{"red car for sale in portland oregon today", "red car"},
+			query:    "red",
+		},
+		"no_term_frequency": {
+			opts:     []Option{WithBM25(0, 0)},
+			contents: []string{"red car", "red red car"},
+			query:    "red",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			searcher := New(tc.opts...)
+			for i, content := range tc.contents {
+				searcher.AddDocument(Document{
+					ID:     fmt.Sprint(i + 1),
+					Fields: map[string]interface{}{"content": content},
+				})
+			}
+
+			results, err := searcher.Search(context.Background(), tc.query)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			if tc.expected == nil {
+				for _, item := range results.Items[1:] {
+					if math.Abs(item.Score-results.Items[0].Score) > 1e-9 {
+						t.Errorf("Expected tied scores, got %v and %v", results.Items[0].Score, item.Score)
+					}
+				}
+				return
+			}
+
+			if len(results.Items) != len(tc.expected) {
+				t.Fatalf("Expected %d results, got %d", len(tc.expected), len(results.Items))
+			}
+			for i, id := range tc.expected {
+				if results.Items[i].ID != id {
+					t.Errorf("At index %d: expected ID %s, got %s", i, id, results.Items[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestBM25Statistics(t *testing.T) {
+	searcher := New()
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"make": "Toyota", "model": "Land Cruiser"}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"make": "Toyota", "model": "Camry"}})
+	searcher.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"make": "Ford"}})
+	searcher.RemoveDocument("3")
+
+	tests := map[string]struct {
+		field    string
+		term     string
+		expected float64
+	}{
+		"common":  {field: "make", term: "toyota", expected: math.Log(1 + 0.5/2.5)},
+		"rare":    {field: "model", term: "camry", expected: math.Log(1 + 1.5/1.5)},
+		"removed": {field: "make", term: "ford", expected: math.Log(1 + 2.5/0.5)},
+		"unseen":  {field: "color", term: "red", expected: math.Log(1 + 0.5/0.5)},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := searcher.corpus().idf(tc.field, tc.term); math.Abs(got-tc.expected) > 1e-9 {
+				t.Errorf("Expected IDF %f, got %f", tc.expected, got)
+			}
+		})
+	}
+
+	if got := searcher.corpus().averageLength("model"); got != 1.5 {
+		t.Errorf("Expected average model length 1.5, got %f", got)
+	}
+}
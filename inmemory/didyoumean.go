@@ -0,0 +1,183 @@
+package inmemory
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// maxSpellingSuggestions is the maximum number of corrected queries suggested
+// for a query without results.
+const maxSpellingSuggestions = 3
+
+// spellingCandidate is a vocabulary term close to a misspelled word.
+type spellingCandidate struct {
+	term      string
+	distance  int
+	frequency int
+}
+
+// didYouMean suggests corrected queries for a query that returned no results.
+// Words missing from the vocabulary are replaced by the closest terms within
+// the typo budget of their length, preferring fewer edits and then more
+// frequent terms. Excluded words, field-scoped words and phrases are left as-is.
+// With filters, only the terms of the documents passing them are known or
+// suggested, so that a search never learns the words of documents it cannot
+// see, such as those of other tenants.
+func (s *Searcher) didYouMean(query string, filters []searchx.Expression) []string {
+	var filtered map[string]int
+	if len(filters) > 0 {
+		filtered = s.filteredVocabulary(filters, nil)
+	}
+
+	words := strings.Fields(query)
+	candidates := make([][]spellingCandidate, len(words))
+
+	corrected := false
+	for i, word := range words {
+		if strings.HasPrefix(word, "-") || strings.ContainsAny(word, `:"`) {
+			continue
+		}
+
+		tokens := s.analyzer.Analyze(strings.TrimPrefix(word, "+"))
+		if len(tokens) != 1 {
+			continue
+		}
+		term := tokens[0].Term
+		if filtered != nil && filtered[term] > 0 || filtered == nil && s.inVocabulary(term) {
+			continue
+		}
+
+		candidates[i] = s.spellingCandidates(term, filtered)
+		if len(candidates[i]) > 0 {
+			corrected = true
+		}
+	}
+	if !corrected {
+		return nil
+	}
+
+	// The first suggestion uses the best correction of every word, later ones
+	// fall back to the next best corrections.
+	var suggestions []string
+	seen := make(map[string]bool)
+	for k := 0; k < maxSpellingSuggestions; k++ {
+		parts := make([]string, len(words))
+		for i, word := range words {
+			parts[i] = word
+			if len(candidates[i]) == 0 {
+				continue
+			}
+			candidate := candidates[i][min(k, len(candidates[i])-1)]
+			parts[i] = candidate.term
+			if strings.HasPrefix(word, "+") {
+				parts[i] = "+" + candidate.term
+			}
+		}
+
+		suggestion := strings.Join(parts, " ")
+		if !seen[suggestion] {
+			seen[suggestion] = true
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	return suggestions
+}
+
+// inVocabulary reports whether a term is in the vocabulary of any shard.
+func (s *Searcher) inVocabulary(term string) bool {
+	for _, shard := range s.readShards() {
+		if node := shard.vocabulary.find(term); node != nil && node.docs > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// spellingCandidates returns the vocabulary terms within the typo budget of a
+// term, closest and most frequent first. Frequencies add up over the shards.
+// When filtered is not nil, it holds the frequencies of the terms to suggest.
+func (s *Searcher) spellingCandidates(term string, filtered map[string]int) []spellingCandidate {
+	maxDist := maxTypos(term)
+	if maxDist == 0 {
+		return nil
+	}
+
+	found := make(map[string]int)
+	var candidates []spellingCandidate
+	for _, shard := range s.readShards() {
+		shard.vocabulary.fuzzy(term, maxDist, func(match string, distance int, node *trieNode) {
+			if i, ok := found[match]; ok {
+				candidates[i].frequency += node.docs
+				return
+			}
+			found[match] = len(candidates)
+			candidates = append(candidates, spellingCandidate{
+				term:      match,
+				distance:  distance,
+				frequency: node.docs,
+			})
+		})
+	}
+
+	if filtered != nil {
+		kept := candidates[:0]
+		for _, candidate := range candidates {
+			if candidate.frequency = filtered[candidate.term]; candidate.frequency > 0 {
+				kept = append(kept, candidate)
+			}
+		}
+		candidates = kept
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		if candidates[i].frequency != candidates[j].frequency {
+			return candidates[i].frequency > candidates[j].frequency
+		}
+		return candidates[i].term < candidates[j].term
+	})
+
+	return candidates
+}
+
+// filteredVocabulary counts the documents passing the filters containing each
+// vocabulary term, in any of the given fields as trieNode.frequency does.
+// Documents are analyzed again, which is only worth it for searches without
+// results and filtered suggestions.
+func (s *Searcher) filteredVocabulary(filters []searchx.Expression, fields []string) map[string]int {
+	frequencies := make(map[string]int)
+	for _, shard := range s.readShards() {
+		plan := shard.planFilters(filters)
+		visit := func(pos int) bool {
+			if doc := shard.documents.get(pos); !shard.tombstones.get(pos) && plan.matches(shard, pos, doc) {
+				for term, termFields := range shard.documentTerms(doc) {
+					if len(fields) == 0 {
+						frequencies[term]++
+						continue
+					}
+					for _, field := range termFields {
+						if slices.Contains(fields, field) {
+							frequencies[term]++
+						}
+					}
+				}
+			}
+			return true
+		}
+
+		if plan.allowed != nil {
+			plan.allowed.forEach(visit)
+			continue
+		}
+		for pos := range shard.documents.len() {
+			visit(pos)
+		}
+	}
+	return frequencies
+}
@@ -0,0 +1,127 @@
+package inmemory
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestDidYouMean(t *testing.T) {
+	searcher := New()
+
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"make": "Toyota", "model": "Camry"}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"make": "Toyota", "model": "Corolla"}})
+	searcher.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"make": "Chevrolet", "model": "Camaro"}})
+	searcher.AddDocument(Document{ID: "4", Fields: map[string]interface{}{"make": "Honda", "model": "Civic"}})
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		query    string
+		expected []string
+	}{
+		"misspelled_model": {
+			query:    "corola",
+			expected: []string{"corolla"},
+		},
+		"transposition": {
+			query:    "toyoat",
+			expected: []string{"toyota"},
+		},
+		"two_typos_in_long_word": {
+			query:    "chevorlet",
+			expected: []string{"chevrolet"},
+		},
+		"keeps_correct_words": {
+			query:    "+toyota +camery",
+			expected: []string{"+toyota +camry"},
+		},
+		"closest_term_only": {
+			query:    "camara",
+			expected: []string{"camaro"},
+		},
+		"alternatives_with_same_distance": {
+			query:    "camro",
+			expected: []string{"camaro", "camry"},
+		},
+		"short_words_not_corrected": {
+			query:    "civ",
+			expected: nil,
+		},
+		"nothing_close": {
+			query:    "xyzzy",
+			expected: nil,
+		},
+		"known_words_not_corrected": {
+			query:    "honda camry -civic",
+			expected: nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(ctx, tc.query, searchx.WithTypoTolerance(false))
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if !reflect.DeepEqual(results.Suggestions, tc.expected) {
+				t.Errorf("Expected suggestions %q, got %q", tc.expected, results.Suggestions)
+			}
+		})
+	}
+}
+
+func TestDidYouMeanOnlyForZeroResults(t *testing.T) {
+	searcher := New()
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"model": "Camry"}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"model": "Camaro"}})
+
+	results, err := searcher.Search(context.Background(), "camry")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results.Suggestions) != 0 {
+		t.Errorf("Expected no suggestions when results were found, got %q", results.Suggestions)
+	}
+}
+
+func TestTrieFuzzyMatchesDamerauLevenshtein(t *testing.T) {
+	vocabulary := []string{
+		"toyota", "toyoat", "camry", "camaro", "corolla", "chevrolet", "civic",
+		"honda", "hond", "ab", "ba", "abc", "ca", "citroen", "café",
+	}
+	targets := []string{"toyota", "camara", "hnoda", "ab", "ac", "civci", "cafe", "chevorlet"}
+
+	var tr trie
+	for _, term := range vocabulary {
+		tr.add(0, term, nil)
+	}
+
+	for _, target := range targets {
+		for maxDist := 0; maxDist <= 2; maxDist++ {
+			var expected []string
+			for _, term := range vocabulary {
+				if damerauLevenshtein([]rune(target), []rune(term)) <= maxDist {
+					expected = append(expected, term)
+				}
+			}
+
+			var got []string
+			tr.fuzzy(target, maxDist, func(term string, distance int, _ *trieNode) {
+				if want := damerauLevenshtein([]rune(target), []rune(term)); distance != want {
+					t.Errorf("Distance between %q and %q: expected %d, got %d", target, term, want, distance)
+				}
+				got = append(got, term)
+			})
+
+			sort.Strings(expected)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Terms within %d of %q: expected %q, got %q", maxDist, target, expected, got)
+			}
+		}
+	}
+}
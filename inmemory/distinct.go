@@ -0,0 +1,32 @@
+package inmemory
+
+import (
+	"fmt"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// collapseMatches keeps the first distinct.PerGroup matches for each value of
+// the distinct field, so matches must already be sorted. Matches without the
+// field are always kept. It also returns the number of matches per value
+// before collapsing.
+func collapseMatches(matches []scoredDocument, distinct searchx.Distinct) ([]scoredDocument, map[string]int64) {
+	counts := make(map[string]int64)
+	collapsed := make([]scoredDocument, 0, len(matches))
+
+	for _, match := range matches {
+		value, ok := match.document.Fields[distinct.Field]
+		if !ok || value == nil {
+			collapsed = append(collapsed, match)
+			continue
+		}
+
+		key := fmt.Sprintf("%v", value)
+		counts[key]++
+		if counts[key] <= int64(distinct.PerGroup) {
+			collapsed = append(collapsed, match)
+		}
+	}
+
+	return collapsed, counts
+}
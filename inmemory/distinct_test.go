@@ -0,0 +1,104 @@
+package inmemory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestDistinct(t *testing.T) {
+	searcher := New()
+
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"model": "Camry", "price": 21000}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"model": "Camry", "price": 19000}})
+	searcher.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"model": "Camry", "price": 25000}})
+	searcher.AddDocument(Document{ID: "4", Fields: map[string]interface{}{"model": "Corolla", "price": 18000}})
+	searcher.AddDocument(Document{ID: "5", Fields: map[string]interface{}{"price": 5000}})
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		opts          []searchx.SearchOption
+		expectedOrder []string
+		expectedTotal int64
+	}{
+		"one_per_model": {
+			opts:          []searchx.SearchOption{searchx.WithSort("price", false), searchx.WithDistinct("model", 1)},
+			expectedOrder: []string{"5", "4", "2"},
+			expectedTotal: 3,
+		},
+		"two_per_model": {
+			opts:          []searchx.SearchOption{searchx.WithSort("price", true), searchx.WithDistinct("model", 2)},
+			expectedOrder: []string{"3", "1", "4", "5"},
+			expectedTotal: 4,
+		},
+		"pagination_after_grouping": {
+			opts:          []searchx.SearchOption{searchx.WithSort("price", false), searchx.WithDistinct("model", 1), searchx.WithOffset(1), searchx.WithLimit(1)},
+			expectedOrder: []string{"4"},
+			expectedTotal: 3,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(ctx, "", tc.opts...)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			if results.Total != tc.expectedTotal {
+				t.Errorf("Expected total %d, got %d", tc.expectedTotal, results.Total)
+			}
+
+			ids := make([]string, 0, len(results.Items))
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.expectedOrder) {
+				t.Errorf("Expected %v, got %v", tc.expectedOrder, ids)
+			}
+
+			expectedCounts := map[string]int64{"Camry": 3, "Corolla": 1}
+			if !reflect.DeepEqual(results.GroupCounts, expectedCounts) {
+				t.Errorf("Expected group counts %v, got %v", expectedCounts, results.GroupCounts)
+			}
+		})
+	}
+}
+
+func TestDistinctKeepsAggregationsUncollapsed(t *testing.T) {
+	searcher := New()
+
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"model": "Camry", "price": 20000}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"model": "Camry", "price": 30000}})
+
+	results, err := searcher.Search(context.Background(), "", searchx.WithDistinct("model", 1), searchx.WithStats("price"))
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(results.Items) != 1 {
+		t.Errorf("Expected 1 collapsed result, got %d", len(results.Items))
+	}
+	if stats := results.Aggregations["price"].Stats; stats.Count != 2 {
+		t.Errorf("Expected stats over both matches, got %+v", stats)
+	}
+	if count := results.GroupCounts["Camry"]; count != 2 {
+		t.Errorf("Expected 2 Camrys before collapsing, got %d", count)
+	}
+}
+
+func TestGroupCountsOnlyWithDistinct(t *testing.T) {
+	searcher := New()
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"model": "Camry"}})
+
+	results, err := searcher.Search(context.Background(), "camry")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if results.GroupCounts != nil {
+		t.Errorf("Expected no group counts, got %v", results.GroupCounts)
+	}
+}
@@ -2,6 +2,7 @@ package inmemory
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/letmevibethatforyou/searchx"
 )
@@ -163,6 +164,11 @@ func (s *Searcher) compareEqual(v1, v2 interface{}) bool {
 		return v1 == v2
 	}
 
+	// Try time comparison
+	if t1, t2, ok := toTimes(v1, v2); ok {
+		return t1.Equal(t2)
+	}
+
 	// Try numeric comparison
 	if f1, ok1 := toFloat64(v1); ok1 {
 		if f2, ok2 := toFloat64(v2); ok2 {
@@ -174,6 +180,45 @@ func (s *Searcher) compareEqual(v1, v2 interface{}) bool {
 	return fmt.Sprintf("%v", v1) == fmt.Sprintf("%v", v2)
 }
 
+// toTimes converts two values to times when at least one of them is a time
+// value as accepted by searchx.ParseTime. A number compared with a time is taken
+// as seconds since the Unix epoch, the way the Algolia backend indexes times.
+// It is called for every comparison, so other values must cost little: strings
+// not starting with a date are rejected without parsing, and the time is only
+// read for relative times, which searches resolve beforehand.
+func toTimes(v1, v2 interface{}) (time.Time, time.Time, bool) {
+	var now time.Time
+	if isRelativeTime(v1) || isRelativeTime(v2) {
+		now = time.Now()
+	}
+	t1, ok1 := searchx.ParseTime(v1, now)
+	t2, ok2 := searchx.ParseTime(v2, now)
+	switch {
+	case ok1 && ok2:
+		return t1, t2, true
+	case ok1:
+		if f, ok := toFloat64(v2); ok {
+			return t1, epochTime(f), true
+		}
+	case ok2:
+		if f, ok := toFloat64(v1); ok {
+			return epochTime(f), t2, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// isRelativeTime reports whether a value is a searchx.RelativeTime.
+func isRelativeTime(v interface{}) bool {
+	_, ok := v.(searchx.RelativeTime)
+	return ok
+}
+
+// epochTime converts seconds since the Unix epoch to a time.
+func epochTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
 // toFloat64 attempts to convert a value to float64.
 // Returns the converted value and a boolean indicating success.
 func toFloat64(v interface{}) (float64, bool) {
@@ -1,7 +1,10 @@
 package inmemory
 
 import (
+	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/letmevibethatforyou/searchx"
 )
@@ -580,6 +583,31 @@ func TestCompareValues(t *testing.T) {
 			v2:       10.5,
 			expected: 0,
 		},
+		"times": {
+			v1:       time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			v2:       time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			expected: -1,
+		},
+		"rfc3339_strings_across_zones": {
+			v1:       "2024-03-01T09:00:00+10:00",
+			v2:       "2024-03-01T00:00:00Z",
+			expected: -1,
+		},
+		"rfc3339_string_and_time": {
+			v1:       "2024-03-01T01:00:00+01:00",
+			v2:       time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			expected: 0,
+		},
+		"date_only_string_and_time": {
+			v1:       "2024-03-01",
+			v2:       time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			expected: 0,
+		},
+		"epoch_seconds_and_time": {
+			v1:       1709251200,
+			v2:       time.Date(2024, 3, 1, 0, 0, 1, 0, time.UTC),
+			expected: -1,
+		},
 	}
 
 	for name, tc := range tests {
@@ -767,3 +795,60 @@ func TestToFloat64(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeFilters(t *testing.T) {
+	now := time.Now()
+	searcher := New()
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"listed_at": now.Add(-time.Hour)}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"listed_at": now.Add(-3 * 24 * time.Hour).Format(time.RFC3339)}})
+	searcher.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"listed_at": now.Add(-30 * 24 * time.Hour).UTC().Format(time.RFC3339Nano)}})
+	searcher.AddDocument(Document{ID: "4", Fields: map[string]interface{}{"title": "no listing date"}})
+	searcher.AddDocument(Document{ID: "5", Fields: map[string]interface{}{"listed_at": now.Add(-10 * 24 * time.Hour).Format(time.DateOnly)}})
+
+	tests := map[string]struct {
+		filter      searchx.Expression
+		expectedIDs []string
+	}{
+		"last_day": {
+			filter:      searchx.InLast("listed_at", 24*time.Hour),
+			expectedIDs: []string{"1"},
+		},
+		"last_week": {
+			filter:      searchx.Gte("listed_at", searchx.Ago(7*24*time.Hour)),
+			expectedIDs: []string{"1", "2"},
+		},
+		"older_than_a_week": {
+			filter:      searchx.Lt("listed_at", searchx.Ago(7*24*time.Hour)),
+			expectedIDs: []string{"5", "3"},
+		},
+		"absolute_range": {
+			filter:      searchx.Range("listed_at", now.Add(-31*24*time.Hour), now.Add(-2*24*time.Hour)),
+			expectedIDs: []string{"2", "5", "3"},
+		},
+		"date_only_value": {
+			filter:      searchx.Gte("listed_at", now.Add(-12*24*time.Hour).Format(time.DateOnly)),
+			expectedIDs: []string{"1", "2", "5"},
+		},
+		"not_in_future": {
+			filter:      searchx.Gt("listed_at", searchx.FromNow(time.Hour)),
+			expectedIDs: []string{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(context.Background(), "", tc.filter, searchx.WithSort("listed_at", true))
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			ids := make([]string, 0, len(results.Items))
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.expectedIDs) {
+				t.Errorf("Expected %v, got %v", tc.expectedIDs, ids)
+			}
+		})
+	}
+}
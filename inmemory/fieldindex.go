@@ -0,0 +1,507 @@
+package inmemory
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// fieldKey is a value of an indexed field normalized for its declared type.
+// Only the member for the type is set, so that keys compare the way filters
+// compare the values they stand for.
+type fieldKey struct {
+	num  float64
+	nano int64
+	str  string
+	b    bool
+}
+
+// fieldKeyOf converts a value to its key in the index of a field of the given
+// type. It returns false when filters would not compare the value as the type,
+// such as a string holding a number in a number field.
+func fieldKeyOf(fieldType searchx.FieldType, value interface{}) (fieldKey, bool) {
+	switch fieldType {
+	case searchx.FieldNumber:
+		if f, ok := toFloat64(value); ok && !math.IsNaN(f) {
+			return fieldKey{num: f}, true
+		}
+	case searchx.FieldString:
+		// Strings holding times are compared chronologically
+		if v, ok := value.(string); ok {
+			if _, isTime := searchx.ParseTime(v, time.Time{}); !isTime {
+				return fieldKey{str: v}, true
+			}
+		}
+	case searchx.FieldBool:
+		if v, ok := value.(bool); ok {
+			return fieldKey{b: v}, true
+		}
+	case searchx.FieldTime:
+		t, ok := searchx.ParseTime(value, time.Time{})
+		if !ok {
+			f, isNumber := toFloat64(value)
+			if !isNumber {
+				return fieldKey{}, false
+			}
+			t = epochTime(f)
+		}
+		// Only times within the range of UnixNano are indexed
+		if year := t.Year(); year > 1678 && year < 2262 {
+			return fieldKey{nano: t.UnixNano()}, true
+		}
+	}
+	return fieldKey{}, false
+}
+
+// compareKeys orders two keys of a field of the given type.
+func compareKeys(fieldType searchx.FieldType, a, b fieldKey) int {
+	switch fieldType {
+	case searchx.FieldNumber:
+		return cmp.Compare(a.num, b.num)
+	case searchx.FieldString:
+		return strings.Compare(a.str, b.str)
+	case searchx.FieldBool:
+		switch {
+		case a.b == b.b:
+			return 0
+		case b.b:
+			return -1
+		default:
+			return 1
+		}
+	default:
+		return cmp.Compare(a.nano, b.nano)
+	}
+}
+
+// sortedKeysBlockSize is the maximum number of keys in a block of sortedKeys.
+const sortedKeysBlockSize = 256
+
+// sortedKeys holds distinct keys in ascending order. Keys are stored in blocks
+// so that insertions and deletions only move, and copy, the keys of one block.
+// Like pvec, it is persistent, gen being the generation of the block slice.
+type sortedKeys struct {
+	fieldType searchx.FieldType
+	gen       uint64
+	blocks    []keyBlock
+}
+
+// keyBlock is a block of sortedKeys.
+type keyBlock struct {
+	gen  uint64
+	keys []fieldKey
+}
+
+// edit returns the keys of a block to change in generation gen, copying the
+// block and the block slice if needed.
+func (k *sortedKeys) edit(gen uint64, b int) []fieldKey {
+	if k.gen != gen {
+		k.blocks = slices.Clone(k.blocks)
+		k.gen = gen
+	}
+	if block := &k.blocks[b]; block.gen != gen {
+		block.keys = slices.Clone(block.keys)
+		block.gen = gen
+	}
+	return k.blocks[b].keys
+}
+
+// locate returns the block holding key, or where it would be inserted, along
+// with the index of key in the block and whether it is there.
+func (k *sortedKeys) locate(key fieldKey) (int, int, bool) {
+	b := sort.Search(len(k.blocks), func(b int) bool {
+		block := k.blocks[b].keys
+		return compareKeys(k.fieldType, block[len(block)-1], key) >= 0
+	})
+	if b == len(k.blocks) {
+		// Above every key: append to the last block
+		if b == 0 {
+			return 0, 0, false
+		}
+		return b - 1, len(k.blocks[b-1].keys), false
+	}
+
+	block := k.blocks[b].keys
+	i := sort.Search(len(block), func(i int) bool {
+		return compareKeys(k.fieldType, block[i], key) >= 0
+	})
+	return b, i, i < len(block) && compareKeys(k.fieldType, block[i], key) == 0
+}
+
+// insert adds a key in generation gen, splitting its block when it grows too large.
+func (k *sortedKeys) insert(gen uint64, key fieldKey) {
+	if len(k.blocks) == 0 {
+		k.blocks = []keyBlock{{gen: gen, keys: []fieldKey{key}}}
+		k.gen = gen
+		return
+	}
+	b, i, found := k.locate(key)
+	if found {
+		return
+	}
+
+	block := slices.Insert(k.edit(gen, b), i, key)
+	if len(block) <= sortedKeysBlockSize {
+		k.blocks[b].keys = block
+		return
+	}
+	half := len(block) / 2
+	right := keyBlock{gen: gen, keys: append([]fieldKey(nil), block[half:]...)}
+	k.blocks[b].keys = block[:half]
+	k.blocks = slices.Insert(k.blocks, b+1, right)
+}
+
+// delete removes a key in generation gen, dropping its block when it becomes empty.
+func (k *sortedKeys) delete(gen uint64, key fieldKey) {
+	b, i, found := k.locate(key)
+	if !found {
+		return
+	}
+	if block := slices.Delete(k.edit(gen, b), i, i+1); len(block) > 0 {
+		k.blocks[b].keys = block
+	} else {
+		k.blocks = slices.Delete(k.blocks, b, b+1)
+	}
+}
+
+// ascend calls fn for the keys between lower and upper in ascending order.
+// A nil bound leaves the range open on its side.
+func (k *sortedKeys) ascend(lower *fieldKey, lowerInclusive bool, upper *fieldKey, upperInclusive bool, fn func(key fieldKey)) {
+	b, i := 0, 0
+	if lower != nil {
+		var found bool
+		if b, i, found = k.locate(*lower); found && !lowerInclusive {
+			i++
+		}
+	}
+
+	for ; b < len(k.blocks); b, i = b+1, 0 {
+		for ; i < len(k.blocks[b].keys); i++ {
+			key := k.blocks[b].keys[i]
+			if upper != nil {
+				if c := compareKeys(k.fieldType, key, *upper); c > 0 || (c == 0 && !upperInclusive) {
+					return
+				}
+			}
+			fn(key)
+		}
+	}
+}
+
+// fieldIndex indexes the values of a filterable field by document position:
+// a hash index for equality and sorted keys for ranges. It is changed in the
+// generation gen only, see editFieldIndexes.
+type fieldIndex struct {
+	gen       uint64
+	fieldType searchx.FieldType
+	// hash maps each value to the positions of the documents holding it.
+	hash pmap[fieldKey, positionSet]
+	// keys holds the values of hash in ascending order.
+	keys sortedKeys
+	// irregular counts the documents whose value does not match the declared
+	// type. Filters compare such values differently, so the index is not used
+	// while there are any.
+	irregular int
+}
+
+// resetFieldIndexes creates empty indexes for the fields the schema declares
+// filterable, except arrays and geo fields.
+func (s *Searcher) resetFieldIndexes() {
+	s.fieldIndexes = nil
+	if s.schema == nil {
+		return
+	}
+	for _, f := range s.schema.Fields {
+		if !f.Filterable || f.Array || f.Type == searchx.FieldGeo {
+			continue
+		}
+		if s.fieldIndexes == nil {
+			s.fieldIndexes = make(map[string]*fieldIndex)
+		}
+		s.fieldIndexes[f.Name] = &fieldIndex{
+			gen:       s.gen,
+			fieldType: f.Type,
+			keys:      sortedKeys{fieldType: f.Type},
+		}
+	}
+}
+
+// indexFields adds the document at a position to the field indexes.
+// The caller must hold the write lock.
+func (s *Searcher) indexFields(pos int, doc Document) {
+	s.editFieldIndexes()
+	for field, index := range s.fieldIndexes {
+		if value, ok := doc.Fields[field]; ok {
+			index.add(s.gen, pos, value)
+		}
+	}
+}
+
+// unindexFields removes the document at a position from the field indexes.
+// The caller must hold the write lock.
+func (s *Searcher) unindexFields(pos int, doc Document) {
+	s.editFieldIndexes()
+	for field, index := range s.fieldIndexes {
+		if value, ok := doc.Fields[field]; ok {
+			index.remove(s.gen, pos, value)
+		}
+	}
+}
+
+// editFieldIndexes prepares the field indexes for changes in the current
+// generation, copying them unless they were copied in it already.
+// The caller must hold the write lock.
+func (s *Searcher) editFieldIndexes() {
+	for _, index := range s.fieldIndexes {
+		if index.gen != s.gen {
+			indexes := make(map[string]*fieldIndex, len(s.fieldIndexes))
+			for field, index := range s.fieldIndexes {
+				edited := *index
+				edited.gen = s.gen
+				indexes[field] = &edited
+			}
+			s.fieldIndexes = indexes
+		}
+		return // The indexes are all copied at once
+	}
+}
+
+// moveFieldIndexes renumbers the indexed documents after a compaction, moved
+// giving the new position of each old one. The caller must hold the write lock.
+func (s *Searcher) moveFieldIndexes(moved []int) {
+	s.editFieldIndexes()
+	for _, index := range s.fieldIndexes {
+		var hash pmap[fieldKey, positionSet]
+		for key, positions := range index.hash.all() {
+			var renumbered positionSet
+			positions.forEach(func(pos int) {
+				renumbered.add(s.gen, moved[pos])
+			})
+			hash.set(s.gen, key, renumbered)
+		}
+		index.hash = hash
+	}
+}
+
+// add indexes the value of the document at a position in generation gen.
+func (idx *fieldIndex) add(gen uint64, pos int, value interface{}) {
+	key, ok := fieldKeyOf(idx.fieldType, value)
+	if !ok {
+		idx.irregular++
+		return
+	}
+
+	positions, ok := idx.hash.get(key)
+	if !ok {
+		idx.keys.insert(gen, key)
+	}
+	positions.add(gen, pos)
+	idx.hash.set(gen, key, positions)
+}
+
+// remove forgets the value of the document at a position in generation gen.
+func (idx *fieldIndex) remove(gen uint64, pos int, value interface{}) {
+	key, ok := fieldKeyOf(idx.fieldType, value)
+	if !ok {
+		idx.irregular--
+		return
+	}
+
+	positions, _ := idx.hash.get(key)
+	positions.remove(gen, pos)
+	if positions.empty() {
+		idx.hash.delete(gen, key)
+		idx.keys.delete(gen, key)
+	} else {
+		idx.hash.set(gen, key, positions)
+	}
+}
+
+// equal returns the positions of the documents holding a value, among n documents.
+func (idx *fieldIndex) equal(key fieldKey, n int) bitmap {
+	set := newBitmap(n)
+	positions, _ := idx.hash.get(key)
+	positions.addTo(set)
+	return set
+}
+
+// between returns the positions of the documents holding a value between lower
+// and upper, among n documents. A nil bound leaves the range open on its side.
+func (idx *fieldIndex) between(lower *fieldKey, lowerInclusive bool, upper *fieldKey, upperInclusive bool, n int) bitmap {
+	set := newBitmap(n)
+	idx.keys.ascend(lower, lowerInclusive, upper, upperInclusive, func(key fieldKey) {
+		positions, _ := idx.hash.get(key)
+		positions.addTo(set)
+	})
+	return set
+}
+
+// filterPlan splits search filters between those answered by the field
+// indexes and those evaluated per document.
+type filterPlan struct {
+	// allowed holds the positions of the documents passing the indexed
+	// filters, or nil when no filter is indexed.
+	allowed bitmap
+	// residual contains the filters evaluated per document.
+	residual []searchx.Expression
+}
+
+// planFilters answers the filters it can from the field indexes, intersecting
+// the bitmaps of the documents passing each of them. Nested AND expressions are
+// flattened so that their indexed operands are used even when others are not.
+func (s *Searcher) planFilters(filters []searchx.Expression) filterPlan {
+	var plan filterPlan
+	if len(s.fieldIndexes) == 0 {
+		plan.residual = filters
+		return plan
+	}
+
+	var visit func(exprs []searchx.Expression)
+	visit = func(exprs []searchx.Expression) {
+		for _, expr := range exprs {
+			if and, ok := expr.(searchx.AndExpr); ok {
+				visit(and.Exprs)
+				continue
+			}
+
+			set, ok := s.indexedFilter(expr)
+			switch {
+			case !ok:
+				plan.residual = append(plan.residual, expr)
+			case plan.allowed == nil:
+				plan.allowed = set
+			default:
+				plan.allowed.and(set)
+			}
+		}
+	}
+	visit(filters)
+	return plan
+}
+
+// matches reports whether the document at a position passes the filters.
+func (p filterPlan) matches(s *Searcher, pos int, doc Document) bool {
+	return (p.allowed == nil || p.allowed.has(pos)) && s.matchesFilters(doc, p.residual)
+}
+
+// indexedFilter returns the positions of the documents matching an expression,
+// or false when the field indexes cannot answer it exactly.
+func (s *Searcher) indexedFilter(expr searchx.Expression) (bitmap, bool) {
+	n := s.documents.len()
+
+	switch e := expr.(type) {
+	case searchx.AndExpr:
+		all := newBitmap(n)
+		all.not(n)
+		for _, inner := range e.Exprs {
+			set, ok := s.indexedFilter(inner)
+			if !ok {
+				return nil, false
+			}
+			all.and(set)
+		}
+		return all, true
+
+	case searchx.OrExpr:
+		union := newBitmap(n)
+		for _, inner := range e.Exprs {
+			set, ok := s.indexedFilter(inner)
+			if !ok {
+				return nil, false
+			}
+			union.or(set)
+		}
+		return union, true
+
+	case searchx.NotExpr:
+		set, ok := s.indexedFilter(e.Inner)
+		if ok {
+			set.not(n)
+		}
+		return set, ok
+
+	case searchx.EqExpr:
+		if idx, key, ok := s.indexedValue(e.Field, e.Value); ok {
+			return idx.equal(key, n), true
+		}
+
+	case searchx.NeExpr:
+		if idx, key, ok := s.indexedValue(e.Field, e.Value); ok {
+			set := idx.equal(key, n)
+			set.not(n)
+			return set, true
+		}
+
+	case searchx.GtExpr:
+		if idx, key, ok := s.indexedValue(e.Field, e.Value); ok {
+			return idx.between(&key, false, nil, false, n), true
+		}
+
+	case searchx.GteExpr:
+		if idx, key, ok := s.indexedValue(e.Field, e.Value); ok {
+			return idx.between(&key, true, nil, false, n), true
+		}
+
+	case searchx.LtExpr:
+		if idx, key, ok := s.indexedValue(e.Field, e.Value); ok {
+			return idx.between(nil, false, &key, false, n), true
+		}
+
+	case searchx.LteExpr:
+		if idx, key, ok := s.indexedValue(e.Field, e.Value); ok {
+			return idx.between(nil, false, &key, true, n), true
+		}
+
+	case searchx.RangeExpr:
+		idx, ok := s.usableFieldIndex(e.Field)
+		if !ok {
+			break
+		}
+		var lower, upper *fieldKey
+		if e.Min != nil {
+			key, ok := fieldKeyOf(idx.fieldType, e.Min)
+			if !ok {
+				break
+			}
+			lower = &key
+		}
+		if e.Max != nil {
+			key, ok := fieldKeyOf(idx.fieldType, e.Max)
+			if !ok {
+				break
+			}
+			upper = &key
+		}
+		return idx.between(lower, true, upper, true, n), true
+
+	case searchx.ExistsExpr:
+		if idx, ok := s.usableFieldIndex(e.Field); ok {
+			return idx.between(nil, false, nil, false, n), true
+		}
+	}
+	return nil, false
+}
+
+// usableFieldIndex returns the index of a field, if it has one holding only
+// regular values.
+func (s *Searcher) usableFieldIndex(field string) (*fieldIndex, bool) {
+	idx, ok := s.fieldIndexes[field]
+	return idx, ok && idx.irregular == 0
+}
+
+// indexedValue returns the index of a field along with the key of a filter
+// value, if the index is usable and the value has the type of the field.
+// Nil values, which also match missing fields, are left to evaluateExpression.
+func (s *Searcher) indexedValue(field string, value interface{}) (*fieldIndex, fieldKey, bool) {
+	idx, ok := s.usableFieldIndex(field)
+	if !ok || value == nil {
+		return nil, fieldKey{}, false
+	}
+	key, ok := fieldKeyOf(idx.fieldType, value)
+	return idx, key, ok
+}
@@ -0,0 +1,204 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// newIndexedSearcher creates a searcher with indexes on filterable fields, and
+// documents with a mix of values and missing fields.
+func newIndexedSearcher() *Searcher {
+	searcher := New(WithSchema(searchx.NewSchema(
+		searchx.Field{Name: "make", Type: searchx.FieldString, Searchable: true, Filterable: true},
+		searchx.Field{Name: "year", Type: searchx.FieldNumber, Filterable: true, Sortable: true},
+		searchx.Field{Name: "used", Type: searchx.FieldBool, Filterable: true},
+		searchx.Field{Name: "listed_at", Type: searchx.FieldTime, Filterable: true},
+		searchx.Field{Name: "color", Type: searchx.FieldString, Filterable: true},
+	)))
+
+	makes := []string{"Toyota", "Ford", "Honda", "Tesla"}
+	colors := []interface{}{"red", "blue", 7, nil} // 7 and nil are irregular
+	listed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 40; i++ {
+		fields := map[string]interface{}{
+			"make":      makes[i%len(makes)],
+			"year":      2010 + i%12,
+			"used":      i%3 == 0,
+			"listed_at": listed.Add(time.Duration(i) * 24 * time.Hour).Format(time.RFC3339),
+			"color":     colors[i%len(colors)],
+		}
+		if i%5 == 0 {
+			delete(fields, "year")
+		}
+		if i%7 == 0 {
+			fields["listed_at"] = float64(listed.Unix()) // Epoch seconds
+		}
+		searcher.AddDocument(Document{ID: fmt.Sprint(i), Fields: fields})
+	}
+
+	// Updates and removals move positions
+	searcher.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"make": "Kia", "year": 2030}})
+	searcher.RemoveDocument("1")
+	searcher.RemoveDocument("20")
+	return searcher
+}
+
+func TestFieldIndexFilters(t *testing.T) {
+	searcher := newIndexedSearcher()
+	since := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		filter  searchx.Expression
+		indexed bool
+	}{
+		"eq_string":    {filter: searchx.Eq("make", "Toyota"), indexed: true},
+		"eq_missing":   {filter: searchx.Eq("make", "Tata"), indexed: true},
+		"eq_number":    {filter: searchx.Eq("year", 2018), indexed: true},
+		"eq_float":     {filter: searchx.Eq("year", 2018.0), indexed: true},
+		"eq_bool":      {filter: searchx.Eq("used", true), indexed: true},
+		"eq_nil":       {filter: searchx.Eq("year", nil), indexed: false},
+		"ne_number":    {filter: searchx.Ne("year", 2018), indexed: true},
+		"gt":           {filter: searchx.Gt("year", 2015), indexed: true},
+		"gte":          {filter: searchx.Gte("year", 2015), indexed: true},
+		"lt":           {filter: searchx.Lt("year", 2015), indexed: true},
+		"lte":          {filter: searchx.Lte("year", 2015), indexed: true},
+		"range":        {filter: searchx.Range("year", 2018, 2020), indexed: true},
+		"range_open":   {filter: searchx.Range("year", nil, 2012), indexed: true},
+		"time_gte":     {filter: searchx.Gte("listed_at", since), indexed: true},
+		"time_range":   {filter: searchx.Range("listed_at", since, since.Add(72*time.Hour)), indexed: true},
+		"exists":       {filter: searchx.Exists("year"), indexed: true},
+		"not":          {filter: searchx.Not(searchx.Eq("make", "Ford")), indexed: true},
+		"or":           {filter: searchx.Or(searchx.Eq("make", "Ford"), searchx.Gt("year", 2019)), indexed: true},
+		"and":          {filter: searchx.And(searchx.Eq("make", "Toyota"), searchx.Range("year", 2012, 2020)), indexed: true},
+		"not_or":       {filter: searchx.Not(searchx.Or(searchx.Eq("used", true), searchx.Exists("year"))), indexed: true},
+		"irregular":    {filter: searchx.Eq("color", "red"), indexed: false},
+		"undeclared":   {filter: searchx.Eq("trim", "LE"), indexed: false},
+		"mixed_or":     {filter: searchx.Or(searchx.Eq("make", "Ford"), searchx.Eq("color", "red")), indexed: false},
+		"wrong_type":   {filter: searchx.Eq("year", "2018"), indexed: false},
+		"time_string":  {filter: searchx.Eq("make", "2024-01-01T00:00:00Z"), indexed: false},
+		"partial_and":  {filter: searchx.And(searchx.Eq("make", "Toyota"), searchx.Eq("color", "red")), indexed: true},
+		"empty_or":     {filter: searchx.Or(), indexed: true},
+		"empty_and":    {filter: searchx.And(), indexed: false}, // Flattened away
+		"ne_irregular": {filter: searchx.Ne("color", "blue"), indexed: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			plan := searcher.planFilters([]searchx.Expression{tc.filter})
+			if indexed := plan.allowed != nil; indexed != tc.indexed {
+				t.Fatalf("Expected indexed %v, got %v with residual %v", tc.indexed, indexed, plan.residual)
+			}
+
+			for pos, doc := range searcher.documents.all() {
+				if searcher.tombstones.get(pos) {
+					continue
+				}
+				expected := searcher.matchesFilters(doc, []searchx.Expression{tc.filter})
+				if got := plan.matches(searcher, pos, doc); got != expected {
+					t.Errorf("Document %s: expected %v, got %v", doc.ID, expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldIndexSearch(t *testing.T) {
+	searcher := newIndexedSearcher()
+
+	results, err := searcher.Search(context.Background(), "",
+		searchx.Eq("make", "Toyota"),
+		searchx.Range("year", 2012, 2020),
+		searchx.WithSort("year", false),
+	)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	var got []string
+	for _, item := range results.Items {
+		got = append(got, item.ID)
+	}
+	if fmt.Sprint(got) != "[4 16 28 8 32]" {
+		t.Errorf("Expected [4 16 28 8 32], got %v", got)
+	}
+
+	searcher.Clear()
+	if plan := searcher.planFilters([]searchx.Expression{searchx.Eq("make", "Toyota")}); plan.allowed == nil || plan.allowed.count() != 0 {
+		t.Error("Expected Clear to empty the field indexes")
+	}
+}
+
+func TestBitmap(t *testing.T) {
+	tests := map[string]struct {
+		n        int
+		set      []int
+		not      bool
+		expected []int
+	}{
+		"empty":       {n: 10, expected: nil},
+		"set":         {n: 130, set: []int{0, 63, 64, 129}, expected: []int{0, 63, 64, 129}},
+		"not":         {n: 5, set: []int{1, 3}, not: true, expected: []int{0, 2, 4}},
+		"not_words":   {n: 66, set: []int{0}, not: true, expected: rangeInts(1, 66)},
+		"not_aligned": {n: 64, set: rangeInts(0, 63), not: true, expected: []int{63}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := newBitmap(tc.n)
+			for _, i := range tc.set {
+				b.set(i)
+			}
+			if tc.not {
+				b.not(tc.n)
+			}
+
+			var got []int
+			b.forEach(func(i int) bool {
+				got = append(got, i)
+				return true
+			})
+			if fmt.Sprint(got) != fmt.Sprint(tc.expected) || b.count() != len(tc.expected) {
+				t.Errorf("Expected %v, got %v (count %d)", tc.expected, got, b.count())
+			}
+		})
+	}
+}
+
+// rangeInts returns the integers from start to end, excluded.
+func rangeInts(start, end int) []int {
+	var ints []int
+	for i := start; i < end; i++ {
+		ints = append(ints, i)
+	}
+	return ints
+}
+
+func BenchmarkFilterSearch(b *testing.B) {
+	searcher := New(WithSchema(searchx.NewSchema(
+		searchx.Field{Name: "make", Type: searchx.FieldString, Filterable: true},
+		searchx.Field{Name: "year", Type: searchx.FieldNumber, Filterable: true},
+	)))
+
+	makes := []string{"Toyota", "Ford", "Honda", "Tesla", "Kia", "Mazda", "BMW", "Audi"}
+	for i := 0; i < 200000; i++ {
+		searcher.AddDocument(Document{ID: fmt.Sprint(i), Fields: map[string]interface{}{
+			"make": makes[i%len(makes)],
+			"year": 2000 + i%25,
+		}})
+	}
+
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = searcher.Search(ctx, "",
+			searchx.Eq("make", "Toyota"),
+			searchx.Range("year", 2018, 2020),
+			searchx.WithLimit(10),
+		)
+	}
+}
@@ -0,0 +1,116 @@
+package inmemory
+
+import (
+	"unicode/utf8"
+
+	"github.com/letmevibethatforyou/searchx"
+	"github.com/letmevibethatforyou/searchx/analysis"
+)
+
+const (
+	// minWordSizeForOneTypo is the minimum term length allowing one typo.
+	minWordSizeForOneTypo = 4
+	// minWordSizeForTwoTypos is the minimum term length allowing two typos.
+	minWordSizeForTwoTypos = 8
+)
+
+// typoBudget returns the maximum number of typos allowed for a query term.
+// Typos are tolerated by default, as by Algolia, and it returns 0 when typo
+// tolerance is disabled in the configuration.
+func typoBudget(cfg *searchx.SearchConfig, term string) int {
+	if cfg != nil && cfg.TypoTolerance != nil && !*cfg.TypoTolerance {
+		return 0
+	}
+	return maxTypos(term)
+}
+
+// maxTypos returns the maximum number of typos a term of its length may contain.
+func maxTypos(term string) int {
+	n := utf8.RuneCountInString(term)
+	switch {
+	case n >= minWordSizeForTwoTypos:
+		return 2
+	case n >= minWordSizeForOneTypo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// fuzzyOccurrences calls fn for every token within maxTypos edits of a
+// single-token phrase, with the smallest number of typos it needs and the term
+// of that phrase. Multi-token phrases, such as synonyms spanning several words,
+// only match exactly.
+func fuzzyOccurrences(tokens []analysis.Token, phrases [][]analysis.Token, maxTypos int, fn func(i, typos int, term string)) {
+	for i, token := range tokens {
+		best, term := -1, ""
+		for _, phrase := range phrases {
+			if len(phrase) != 1 {
+				continue
+			}
+			if typos, ok := withinDistance(phrase[0].Term, token.Term, maxTypos); ok && (best < 0 || typos < best) {
+				best, term = typos, phrase[0].Term
+			}
+		}
+		if best >= 0 {
+			fn(i, best, term)
+		}
+	}
+}
+
+// withinDistance reports whether the Damerau-Levenshtein distance between a and b
+// is at most maxDist, returning the distance when it is.
+func withinDistance(a, b string, maxDist int) (int, bool) {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > maxDist {
+		return 0, false
+	}
+
+	d := damerauLevenshtein(ra, rb)
+	return d, d <= maxDist
+}
+
+// damerauLevenshtein computes the optimal string alignment distance between two
+// rune slices, counting insertions, deletions, substitutions and transpositions
+// of adjacent runes as one edit each.
+func damerauLevenshtein(a, b []rune) int {
+	// Three rolling rows are enough since transpositions look back two rows.
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(
+				prev[j]+1,      // deletion
+				curr[j-1]+1,    // insertion
+				prev[j-1]+cost, // substitution
+			)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1) // transposition
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(b)]
+}
+
+// abs returns the absolute value of an int.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,177 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestDamerauLevenshtein(t *testing.T) {
+	tests := map[string]struct {
+		a        string
+		b        string
+		expected int
+	}{
+		"identical":     {a: "toyota", b: "toyota", expected: 0},
+		"insertion":     {a: "toyta", b: "toyota", expected: 1},
+		"deletion":      {a: "toyoota", b: "toyota", expected: 1},
+		"substitution":  {a: "toyosa", b: "toyota", expected: 1},
+		"transposition": {a: "tyoota", b: "toyota", expected: 1},
+		"two_edits":     {a: "chevrlot", b: "chevrolet", expected: 2},
+		"empty_first":   {a: "", b: "abc", expected: 3},
+		"empty_second":  {a: "abc", b: "", expected: 3},
+		"unicode":       {a: "citroen", b: "citroën", expected: 1},
+		"unrelated":     {a: "honda", b: "mazda", expected: 3},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := damerauLevenshtein([]rune(tc.a), []rune(tc.b))
+			if got != tc.expected {
+				t.Errorf("Expected distance %d between %q and %q, got %d", tc.expected, tc.a, tc.b, got)
+			}
+		})
+	}
+}
+
+func TestTypoBudget(t *testing.T) {
+	enabled := &searchx.SearchConfig{}
+	searchx.WithTypoTolerance(true).Apply(enabled)
+
+	disabled := &searchx.SearchConfig{}
+	searchx.WithTypoTolerance(false).Apply(disabled)
+
+	tests := map[string]struct {
+		cfg      *searchx.SearchConfig
+		term     string
+		expected int
+	}{
+		"unset":             {cfg: &searchx.SearchConfig{}, term: "chevrolet", expected: 2},
+		"disabled":          {cfg: disabled, term: "chevrolet", expected: 0},
+		"short_term":        {cfg: enabled, term: "kia", expected: 0},
+		"one_typo_term":     {cfg: enabled, term: "toyta", expected: 1},
+		"two_typos_term":    {cfg: enabled, term: "chevrolet", expected: 2},
+		"unicode_rune_size": {cfg: enabled, term: "škod", expected: 1},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := typoBudget(tc.cfg, tc.term)
+			if got != tc.expected {
+				t.Errorf("Expected budget %d for %q, got %d", tc.expected, tc.term, got)
+			}
+		})
+	}
+}
+
+func TestTypoTolerantSearch(t *testing.T) {
+	searcher := New()
+
+	searcher.AddDocument(Document{
+		ID:     "1",
+		Fields: map[string]interface{}{"make": "Toyota", "model": "Camry"},
+	})
+	searcher.AddDocument(Document{
+		ID:     "2",
+		Fields: map[string]interface{}{"make": "Toyta", "model": "Corolla"},
+	})
+	searcher.AddDocument(Document{
+		ID:     "3",
+		Fields: map[string]interface{}{"make": "Honda", "model": "Civic"},
+	})
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		query         string
+		opts          []searchx.SearchOption
+		expectedOrder []string
+	}{
+		"enabled_by_default": {
+			query:         "toyta",
+			expectedOrder: []string{"2", "1"},
+		},
+		"misspelled_query_matches": {
+			query:         "toyta",
+			opts:          []searchx.SearchOption{searchx.WithTypoTolerance(true)},
+			expectedOrder: []string{"2", "1"},
+		},
+		"exact_match_ranks_first": {
+			query:         "toyota",
+			opts:          []searchx.SearchOption{searchx.WithTypoTolerance(true)},
+			expectedOrder: []string{"1", "2"},
+		},
+		"short_terms_stay_exact": {
+			query:         "civi",
+			opts:          []searchx.SearchOption{searchx.WithTypoTolerance(true)},
+			expectedOrder: []string{"3"},
+		},
+		"explicitly_disabled": {
+			query:         "hondda",
+			opts:          []searchx.SearchOption{searchx.WithTypoTolerance(false)},
+			expectedOrder: []string{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(ctx, tc.query, tc.opts...)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			if len(results.Items) != len(tc.expectedOrder) {
+				t.Fatalf("Expected %d results, got %d", len(tc.expectedOrder), len(results.Items))
+			}
+
+			for i, id := range tc.expectedOrder {
+				if results.Items[i].ID != id {
+					t.Errorf("At index %d: expected ID %s, got %s", i, id, results.Items[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestFuzzyScoresUseQueryTermRarity(t *testing.T) {
+	// Ford is common and fort rare, so the rarity of the word matched with a
+	// typo would rank the fort document first when searching ford
+	searcher := New()
+	for i := 0; i < 50; i++ {
+		searcher.AddDocument(Document{ID: fmt.Sprint("ford", i), Fields: map[string]interface{}{"title": "ford wagon"}})
+	}
+	searcher.AddDocument(Document{ID: "fort", Fields: map[string]interface{}{"title": "fort wagon"}})
+
+	tests := map[string]struct {
+		query    string
+		expected []string // documents ranking above all the others
+		last     string
+	}{
+		"common_term": {query: "ford", last: "fort"},
+		"rare_term":   {query: "fort", expected: []string{"fort"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(context.Background(), tc.query, searchx.WithLimit(100))
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if results.Total != 51 {
+				t.Fatalf("Expected 51 results, got %d", results.Total)
+			}
+			for i, id := range tc.expected {
+				if results.Items[i].ID != id {
+					t.Errorf("At index %d: expected ID %s, got %s", i, id, results.Items[i].ID)
+				}
+			}
+			if tc.last != "" {
+				if last := results.Items[len(results.Items)-1]; last.ID != tc.last || last.Score >= results.Items[0].Score {
+					t.Errorf("Expected %s to rank last below the exact matches, got %s scoring %v against %v", tc.last, last.ID, last.Score, results.Items[0].Score)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package inmemory
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// HNSWConfig configures a hierarchical navigable small world graph, an
+// approximate nearest-neighbour index trading a little recall for speed.
+type HNSWConfig struct {
+	// M is the number of neighbours kept per node on upper layers, and twice
+	// that on the bottom layer. It defaults to 16.
+	M int
+	// EfConstruction is the size of the candidate list when inserting.
+	// Larger values build a better graph more slowly. It defaults to 200.
+	EfConstruction int
+	// EfSearch is the size of the candidate list when searching, raised to k
+	// when smaller. Larger values improve recall. It defaults to 64.
+	EfSearch int
+	// Seed seeds the random layer assignment so graphs are reproducible.
+	Seed int64
+}
+
+// withDefaults returns the configuration with unset values defaulted.
+func (c HNSWConfig) withDefaults() HNSWConfig {
+	if c.M <= 0 {
+		c.M = 16
+	}
+	if c.EfConstruction <= 0 {
+		c.EfConstruction = 200
+	}
+	if c.EfSearch <= 0 {
+		c.EfSearch = 64
+	}
+	return c
+}
+
+// hnswIndex is an HNSW graph over the vectors of a field.
+// Removed documents are tombstoned and the graph is rebuilt once they make up
+// half of its nodes. It is changed in the generation gen only, see edit.
+type hnswIndex struct {
+	gen        uint64
+	cfg        HNSWConfig
+	similarity searchx.Similarity
+	levelMult  float64
+	// rng is shared by the versions of the graph, only the writer using it.
+	rng *rand.Rand
+
+	nodes    pvec[*hnswNode]
+	byID     pmap[string, int]
+	entry    int // -1 when the graph is empty
+	maxLevel int
+	deleted  int
+	dims     int
+}
+
+// hnswNode is a vector in the graph along with its neighbours on each layer.
+// Like the graph, it is changed in the generation gen only.
+type hnswNode struct {
+	gen       uint64
+	id        string
+	vector    []float32
+	neighbors [][]int
+	deleted   bool
+}
+
+// vectorHit is a document found by a vector search.
+type vectorHit struct {
+	id    string
+	score float64
+}
+
+// newHNSWIndex creates an empty graph comparing vectors with the given similarity.
+func newHNSWIndex(cfg HNSWConfig, similarity searchx.Similarity) *hnswIndex {
+	cfg = cfg.withDefaults()
+	return &hnswIndex{
+		cfg:        cfg,
+		similarity: similarity,
+		levelMult:  1 / math.Log(float64(cfg.M)),
+		rng:        rand.New(rand.NewSource(cfg.Seed)),
+		entry:      -1,
+	}
+}
+
+// edit returns the graph to change in generation gen, copying it if needed.
+func (h *hnswIndex) edit(gen uint64) *hnswIndex {
+	if h.gen == gen {
+		return h
+	}
+	edited := *h
+	edited.gen = gen
+	return &edited
+}
+
+// node returns the node at an index to change in generation gen, copying it
+// and its links if needed.
+func (h *hnswIndex) node(gen uint64, idx int) *hnswNode {
+	node := h.nodes.get(idx)
+	if node.gen == gen {
+		return node
+	}
+	edited := *node
+	edited.gen = gen
+	edited.neighbors = make([][]int, len(node.neighbors))
+	for l, links := range node.neighbors {
+		edited.neighbors[l] = slices.Clone(links)
+	}
+	h.nodes.set(gen, idx, &edited)
+	return &edited
+}
+
+// add inserts or replaces the vector of a document in generation gen.
+// Vectors whose dimension differs from the first vector added are ignored.
+func (h *hnswIndex) add(gen uint64, id string, vector []float32) {
+	h.remove(gen, id)
+
+	vector = prepareVector(vector, h.similarity)
+	if vector == nil {
+		return
+	}
+	if h.dims == 0 {
+		h.dims = len(vector)
+	} else if len(vector) != h.dims {
+		return
+	}
+
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+	idx := h.nodes.len()
+	node := &hnswNode{gen: gen, id: id, vector: vector, neighbors: make([][]int, level+1)}
+	h.nodes.append(gen, node)
+	h.byID.set(gen, id, idx)
+
+	if h.entry < 0 {
+		h.entry = idx
+		h.maxLevel = level
+		return
+	}
+
+	// Descend greedily through the layers above the node's own
+	entryPoints := []int{h.entry}
+	for l := h.maxLevel; l > level; l-- {
+		entryPoints = []int{h.searchLayer(vector, entryPoints, 1, l)[0].idx}
+	}
+
+	// Connect the node on each of its layers
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		found := h.searchLayer(vector, entryPoints, h.cfg.EfConstruction, l)
+
+		neighbors := make([]int, 0, h.cfg.M)
+		for _, c := range found {
+			if len(neighbors) == h.cfg.M {
+				break
+			}
+			neighbors = append(neighbors, c.idx)
+		}
+		node.neighbors[l] = neighbors
+		for _, n := range neighbors {
+			h.connect(gen, n, idx, l)
+		}
+
+		entryPoints = entryPoints[:0]
+		for _, c := range found {
+			entryPoints = append(entryPoints, c.idx)
+		}
+	}
+
+	if level > h.maxLevel {
+		h.maxLevel = level
+		h.entry = idx
+	}
+}
+
+// connect adds a link from one node to another on a layer in generation gen,
+// keeping only the closest neighbours when the node has too many.
+func (h *hnswIndex) connect(gen uint64, from, to, layer int) {
+	node := h.node(gen, from)
+	node.neighbors[layer] = append(node.neighbors[layer], to)
+
+	maxConn := h.cfg.M
+	if layer == 0 {
+		maxConn = 2 * h.cfg.M
+	}
+	if len(node.neighbors[layer]) <= maxConn {
+		return
+	}
+
+	links := node.neighbors[layer]
+	sort.Slice(links, func(i, j int) bool {
+		return dot(node.vector, h.nodes.get(links[i]).vector) > dot(node.vector, h.nodes.get(links[j]).vector)
+	})
+	node.neighbors[layer] = links[:maxConn]
+}
+
+// remove tombstones the vector of a document in generation gen, rebuilding the
+// graph when tombstones make up half of it.
+func (h *hnswIndex) remove(gen uint64, id string) {
+	idx, ok := h.byID.get(id)
+	if !ok {
+		return
+	}
+	h.node(gen, idx).deleted = true
+	h.byID.delete(gen, id)
+	h.deleted++
+
+	if h.deleted*2 >= h.nodes.len() {
+		h.rebuild(gen)
+	}
+}
+
+// rebuild recreates the graph in generation gen from the nodes that are not
+// tombstoned.
+func (h *hnswIndex) rebuild(gen uint64) {
+	nodes := h.nodes
+	h.nodes = pvec[*hnswNode]{}
+	h.byID = pmap[string, int]{}
+	h.entry = -1
+	h.maxLevel = 0
+	h.deleted = 0
+
+	for _, node := range nodes.all() {
+		if !node.deleted {
+			h.add(gen, node.id, node.vector)
+		}
+	}
+}
+
+// search returns up to k documents accepted by accept, most similar first.
+// The candidate list grows until k accepted documents are found, so selective
+// filters trade speed for completeness rather than returning fewer results.
+// Query vectors with a different dimension than the graph return
+// searchx.ErrInvalidOption.
+func (h *hnswIndex) search(vector []float32, k int, accept func(id string) bool) ([]vectorHit, error) {
+	if h.dims != 0 && len(vector) != h.dims {
+		return nil, errors.Wrapf(searchx.ErrInvalidOption, "vector has %d dimensions, index has %d", len(vector), h.dims)
+	}
+	vector = prepareVector(vector, h.similarity)
+	if h.entry < 0 || vector == nil {
+		return nil, nil
+	}
+
+	for ef := max(h.cfg.EfSearch, k); ; ef *= 2 {
+		entryPoints := []int{h.entry}
+		for l := h.maxLevel; l > 0; l-- {
+			entryPoints = []int{h.searchLayer(vector, entryPoints, 1, l)[0].idx}
+		}
+
+		var hits []vectorHit
+		for _, c := range h.searchLayer(vector, entryPoints, ef, 0) {
+			node := h.nodes.get(c.idx)
+			if node.deleted || !accept(node.id) {
+				continue
+			}
+			hits = append(hits, vectorHit{id: node.id, score: c.score})
+			if len(hits) == k {
+				break
+			}
+		}
+
+		if len(hits) == k || ef >= h.nodes.len() {
+			return hits, nil
+		}
+	}
+}
+
+// searchLayer finds the ef nodes closest to vector on a layer, starting from
+// the entry points. Results are ordered most similar first.
+func (h *hnswIndex) searchLayer(vector []float32, entryPoints []int, ef, layer int) []hnswCandidate {
+	visited := make(map[int]bool, ef*4)
+	candidates := &candidateHeap{best: true}
+	results := &candidateHeap{}
+
+	for _, ep := range entryPoints {
+		if visited[ep] {
+			continue
+		}
+		visited[ep] = true
+		c := hnswCandidate{idx: ep, score: dot(vector, h.nodes.get(ep).vector)}
+		heap.Push(candidates, c)
+		heap.Push(results, c)
+		if results.Len() > ef {
+			heap.Pop(results)
+		}
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && c.score < results.items[0].score {
+			break
+		}
+
+		node := h.nodes.get(c.idx)
+		if layer >= len(node.neighbors) {
+			continue
+		}
+		for _, n := range node.neighbors[layer] {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+
+			score := dot(vector, h.nodes.get(n).vector)
+			if results.Len() < ef || score > results.items[0].score {
+				heap.Push(candidates, hnswCandidate{idx: n, score: score})
+				heap.Push(results, hnswCandidate{idx: n, score: score})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	found := results.items
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].score > found[j].score
+	})
+	return found
+}
+
+// hnswCandidate is a node considered during a layer search.
+type hnswCandidate struct {
+	idx   int
+	score float64
+}
+
+// candidateHeap is a heap of candidates ordered by score. With best set the
+// most similar candidate is on top, otherwise the least similar one.
+type candidateHeap struct {
+	items []hnswCandidate
+	best  bool
+}
+
+func (c candidateHeap) Len() int { return len(c.items) }
+
+func (c candidateHeap) Less(i, j int) bool {
+	if c.best {
+		return c.items[i].score > c.items[j].score
+	}
+	return c.items[i].score < c.items[j].score
+}
+
+func (c candidateHeap) Swap(i, j int) { c.items[i], c.items[j] = c.items[j], c.items[i] }
+
+func (c *candidateHeap) Push(x interface{}) { c.items = append(c.items, x.(hnswCandidate)) }
+
+func (c *candidateHeap) Pop() interface{} {
+	last := c.items[len(c.items)-1]
+	c.items = c.items[:len(c.items)-1]
+	return last
+}
@@ -0,0 +1,129 @@
+package inmemory
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func randomVector(rng *rand.Rand, dims int) []float32 {
+	vector := make([]float32, dims)
+	for i := range vector {
+		vector[i] = float32(rng.NormFloat64())
+	}
+	return vector
+}
+
+func TestHNSWRecall(t *testing.T) {
+	const (
+		count   = 500
+		dims    = 16
+		k       = 10
+		queries = 20
+	)
+
+	rng := rand.New(rand.NewSource(42))
+	exact := New()
+	approximate := New(WithHNSW("embedding", HNSWConfig{Seed: 7}))
+	for i := 0; i < count; i++ {
+		doc := Document{ID: strconv.Itoa(i), Fields: map[string]interface{}{
+			"embedding": randomVector(rng, dims),
+			"group":     i % 10,
+		}}
+		exact.AddDocument(doc)
+		approximate.AddDocument(doc)
+	}
+
+	ctx := context.Background()
+
+	tests := map[string][]searchx.SearchOption{
+		"unfiltered": nil,
+		"filtered":   {searchx.Eq("group", 3)},
+	}
+
+	for name, filters := range tests {
+		t.Run(name, func(t *testing.T) {
+			found, total := 0, 0
+			for q := 0; q < queries; q++ {
+				query := searchx.WithVector("embedding", randomVector(rng, dims), k)
+
+				expected, err := exact.Search(ctx, "", append(filters, query)...)
+				if err != nil {
+					t.Fatalf("Search failed: %v", err)
+				}
+				actual, err := approximate.Search(ctx, "", append(filters, query)...)
+				if err != nil {
+					t.Fatalf("Search failed: %v", err)
+				}
+				if len(actual.Items) != k {
+					t.Fatalf("Expected %d results, got %d", k, len(actual.Items))
+				}
+
+				want := make(map[string]bool)
+				for _, item := range expected.Items {
+					want[item.ID] = true
+				}
+				for _, item := range actual.Items {
+					if want[item.ID] {
+						found++
+					}
+				}
+				total += len(expected.Items)
+			}
+
+			if recall := float64(found) / float64(total); recall < 0.9 {
+				t.Errorf("Expected recall@%d of at least 0.9, got %.2f", k, recall)
+			}
+		})
+	}
+}
+
+func TestHNSWUpdates(t *testing.T) {
+	searcher := New(WithHNSW("embedding", HNSWConfig{Seed: 1}))
+	for i := 0; i < 20; i++ {
+		searcher.AddDocument(Document{ID: strconv.Itoa(i), Fields: map[string]interface{}{
+			"embedding": []float32{1, float32(i)},
+		}})
+	}
+
+	// Removing most documents rebuilds the graph
+	for i := 0; i < 15; i++ {
+		searcher.RemoveDocument(strconv.Itoa(i))
+	}
+	index := searcher.vectorIndexes["embedding"]
+	if index.nodes.len() != 5 || index.deleted != 0 {
+		t.Errorf("Expected a rebuilt graph of 5 nodes, got %d nodes with %d deleted", index.nodes.len(), index.deleted)
+	}
+
+	// Updating a document moves its vector
+	searcher.AddDocument(Document{ID: "19", Fields: map[string]interface{}{"embedding": []float32{-1, 0}}})
+
+	ctx := context.Background()
+	results, err := searcher.Search(ctx, "", searchx.WithVector("embedding", []float32{-1, 0}, 10))
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results.Items) != 5 {
+		t.Fatalf("Expected 5 results, got %d", len(results.Items))
+	}
+	if results.Items[0].ID != "19" {
+		t.Errorf("Expected updated document first, got %s", results.Items[0].ID)
+	}
+	for _, item := range results.Items {
+		if id, _ := strconv.Atoi(item.ID); id < 15 {
+			t.Errorf("Removed document %s returned", item.ID)
+		}
+	}
+
+	searcher.Clear()
+	results, err = searcher.Search(ctx, "", searchx.WithVector("embedding", []float32{-1, 0}, 10))
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results.Items) != 0 {
+		t.Errorf("Expected no results after Clear, got %d", len(results.Items))
+	}
+}
@@ -0,0 +1,278 @@
+package inmemory
+
+import (
+	"maps"
+	"sort"
+
+	"github.com/letmevibethatforyou/searchx"
+	"github.com/letmevibethatforyou/searchx/analysis"
+)
+
+// fieldTokens holds the analyzed values of the searchable fields of a document.
+type fieldTokens map[string][]analysis.Token
+
+// postings maps the IDs of the documents containing a term to the number of
+// occurrences of the term in each of their fields. The maps of occurrences are
+// never changed once added.
+type postings = pmap[string, map[string]int]
+
+// invertedIndex maps the terms of indexed documents to the documents containing
+// them, so that searches only score documents which may match the query.
+// It is changed in the generation gen only, see edit.
+type invertedIndex struct {
+	gen uint64
+	// postings maps each indexed term to its postings.
+	postings pmap[string, postings]
+	// terms holds the indexed terms for prefix and fuzzy expansion.
+	terms trie
+	// tokens holds the analyzed fields of each document by ID, so that
+	// documents are analyzed once when added rather than on every search.
+	tokens pmap[string, fieldTokens]
+	// fieldDocs counts the documents having each field, and fieldLengths the
+	// tokens of each field over all documents, for BM25 scoring.
+	fieldDocs    map[string]int
+	fieldLengths map[string]int
+}
+
+// newInvertedIndex creates an empty inverted index.
+func newInvertedIndex() *invertedIndex {
+	return &invertedIndex{
+		fieldDocs:    make(map[string]int),
+		fieldLengths: make(map[string]int),
+	}
+}
+
+// edit returns the index to change in generation gen, copying it if needed.
+func (idx *invertedIndex) edit(gen uint64) *invertedIndex {
+	if idx.gen == gen {
+		return idx
+	}
+	edited := *idx
+	edited.gen = gen
+	edited.fieldDocs = maps.Clone(idx.fieldDocs)
+	edited.fieldLengths = maps.Clone(idx.fieldLengths)
+	return &edited
+}
+
+// documentTokens returns the analyzed fields of a document.
+func (idx *invertedIndex) documentTokens(id string) fieldTokens {
+	fields, _ := idx.tokens.get(id)
+	return fields
+}
+
+// analyzeDocument analyzes the searchable fields of a document, each with its
+// analyzer. Fields the schema does not declare searchable are left out.
+func (s *Searcher) analyzeDocument(doc Document) fieldTokens {
+	fields := make(fieldTokens, len(doc.Fields))
+	for field, value := range doc.Fields {
+		if s.schema != nil && !s.schema.Searchable(field) {
+			continue
+		}
+		analyzer, _ := s.analyzerFor(field)
+		if tokens := analyzeValue(analyzer, value); len(tokens) > 0 {
+			fields[field] = tokens
+		}
+	}
+	return fields
+}
+
+// add indexes the analyzed fields of a document in generation gen. The
+// document must not be indexed already.
+func (idx *invertedIndex) add(gen uint64, id string, fields fieldTokens) {
+	idx.tokens.set(gen, id, fields)
+
+	occurrences := make(map[string]map[string]int)
+	for field, tokens := range fields {
+		idx.fieldDocs[field]++
+		idx.fieldLengths[field] += len(tokens)
+		for _, token := range tokens {
+			if occurrences[token.Term] == nil {
+				occurrences[token.Term] = make(map[string]int)
+			}
+			occurrences[token.Term][field]++
+		}
+	}
+	for term, frequencies := range occurrences {
+		p, _ := idx.postings.get(term)
+		p.set(gen, id, frequencies)
+		idx.postings.set(gen, term, p)
+		idx.terms.add(gen, term, postingFields(frequencies))
+	}
+}
+
+// remove removes a document from the index in generation gen, reporting
+// whether it was indexed.
+func (idx *invertedIndex) remove(gen uint64, id string) bool {
+	fields, ok := idx.tokens.get(id)
+	if !ok {
+		return false
+	}
+
+	for field, tokens := range fields {
+		if idx.fieldDocs[field]--; idx.fieldDocs[field] <= 0 {
+			delete(idx.fieldDocs, field)
+			delete(idx.fieldLengths, field)
+		} else {
+			idx.fieldLengths[field] -= len(tokens)
+		}
+		for _, token := range tokens {
+			p, _ := idx.postings.get(token.Term)
+			frequencies, ok := p.get(id)
+			if !ok {
+				continue // Already removed for another occurrence
+			}
+			idx.terms.remove(gen, token.Term, postingFields(frequencies))
+			p.delete(gen, id)
+			if p.len() == 0 {
+				idx.postings.delete(gen, token.Term)
+			} else {
+				idx.postings.set(gen, token.Term, p)
+			}
+		}
+	}
+	idx.tokens.delete(gen, id)
+	return true
+}
+
+// postingFields returns the fields of a posting.
+func postingFields(frequencies map[string]int) []string {
+	fields := make([]string, 0, len(frequencies))
+	for field := range frequencies {
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// candidates returns the IDs of the documents which may match the query terms,
+// a superset of those scoreTerms scores above zero. Required terms intersect
+// their postings and optional terms union them. Excluded terms are left to
+// scoreTerms. It returns nil when every document may match, as for empty
+// queries and queries with only excluded terms.
+func (idx *invertedIndex) candidates(terms []queryTerm, cfg *searchx.SearchConfig) map[string]bool {
+	var must, should []queryTerm
+	for _, term := range terms {
+		switch term.occur {
+		case searchx.OccurMust:
+			must = append(must, term)
+		case searchx.OccurMustNot:
+		default:
+			should = append(should, term)
+		}
+	}
+
+	if len(must) > 0 {
+		ids := idx.termCandidates(must[0], cfg)
+		for _, term := range must[1:] {
+			if len(ids) == 0 {
+				break
+			}
+			ids = intersect(ids, idx.termCandidates(term, cfg))
+		}
+		return ids
+	}
+
+	if len(should) == 0 {
+		return nil
+	}
+	ids := make(map[string]bool)
+	for _, term := range should {
+		for id := range idx.termCandidates(term, cfg) {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// termCandidates returns the IDs of the documents which may match a query term
+// with any of its alternatives, as analyzed by any analyzer. Like
+// phraseOccurrences, the last word of a phrase is expanded to the indexed terms
+// it prefixes unless the term is exact, and like scoreTerms, single words are
+// expanded to the indexed terms within the typo budget.
+func (idx *invertedIndex) termCandidates(term queryTerm, cfg *searchx.SearchConfig) map[string]bool {
+	maxTypos := 0
+	if !term.exact && term.occur != searchx.OccurMustNot {
+		maxTypos = typoBudget(cfg, term.text)
+	}
+
+	ids := make(map[string]bool)
+	for _, phrases := range term.phrases {
+		for _, phrase := range phrases {
+			for id := range idx.phraseCandidates(phrase, term.field, term.exact) {
+				ids[id] = true
+			}
+			if maxTypos > 0 && len(phrase) == 1 {
+				idx.terms.fuzzy(phrase[0].Term, maxTypos, func(t string, _ int, _ *trieNode) {
+					idx.collect(ids, t, term.field)
+				})
+			}
+		}
+	}
+	return ids
+}
+
+// phraseCandidates returns the IDs of the documents containing every word of a
+// phrase, in the given field or in any field when field is empty. Positions are
+// left to phraseOccurrences.
+func (idx *invertedIndex) phraseCandidates(phrase []analysis.Token, field string, exact bool) map[string]bool {
+	var ids map[string]bool
+	for i, token := range phrase {
+		found := make(map[string]bool)
+		if !exact && i == len(phrase)-1 {
+			if node := idx.terms.find(token.Term); node != nil {
+				node.walk(token.Term, func(t string, _ *trieNode) {
+					idx.collect(found, t, field)
+				})
+			}
+		} else {
+			idx.collect(found, token.Term, field)
+		}
+
+		if ids == nil {
+			ids = found
+		} else {
+			ids = intersect(ids, found)
+		}
+		if len(ids) == 0 {
+			break
+		}
+	}
+	return ids
+}
+
+// collect adds the IDs of the documents containing term to ids, restricted to
+// those containing it in field when field is not empty.
+func (idx *invertedIndex) collect(ids map[string]bool, term, field string) {
+	p, _ := idx.postings.get(term)
+	for id, frequencies := range p.all() {
+		if field == "" || frequencies[field] > 0 {
+			ids[id] = true
+		}
+	}
+}
+
+// intersect returns the IDs present in both sets.
+func intersect(a, b map[string]bool) map[string]bool {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	ids := make(map[string]bool, len(a))
+	for id := range a {
+		if b[id] {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// positionsByID returns the positions of the documents with the given IDs in
+// ascending order.
+func (s *Searcher) positionsByID(ids map[string]bool) []int {
+	positions := make([]int, 0, len(ids))
+	for id := range ids {
+		if pos, ok := s.idIndex.get(id); ok {
+			positions = append(positions, pos)
+		}
+	}
+	sort.Ints(positions)
+	return positions
+}
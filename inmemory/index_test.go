@@ -0,0 +1,141 @@
+package inmemory
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+	"github.com/letmevibethatforyou/searchx/analysis"
+)
+
+func TestIndexCandidates(t *testing.T) {
+	searcher := New(WithFieldAnalyzer("description", analysis.English()))
+	searcher.SaveSynonyms(searchx.OneWaySynonym("vw", "vw", "volkswagen"))
+
+	docs := []Document{
+		{ID: "1", Fields: map[string]interface{}{"make": "Toyota", "model": "Camry", "year": 2018, "description": "Reliable family sedan"}},
+		{ID: "2", Fields: map[string]interface{}{"make": "Toyota", "model": "Corolla", "year": 2020, "tags": []interface{}{"hybrid", "compact"}}},
+		{ID: "3", Fields: map[string]interface{}{"make": "Ford", "model": "Mustang", "description": "Red sports cars"}},
+		{ID: "4", Fields: map[string]interface{}{"make": "Volkswagen", "model": "Golf", "dealer": map[string]interface{}{"city": "Portland"}}},
+		{ID: "5", Fields: map[string]interface{}{"make": "Chevrolet", "model": "Corvette", "year": 2018}},
+	}
+	for _, doc := range docs {
+		searcher.AddDocument(doc)
+	}
+
+	tests := map[string]struct {
+		query    string
+		typos    bool
+		expected []string // nil when every document is a candidate
+	}{
+		"empty":            {query: "", expected: nil},
+		"single_term":      {query: "toyota", expected: []string{"1", "2"}},
+		"prefix":           {query: "cor", expected: []string{"2", "5"}},
+		"union":            {query: "camry mustang", expected: []string{"1", "3"}},
+		"intersection":     {query: "+toyota +2018", expected: []string{"1"}},
+		"required_missing": {query: "+toyota +ford", expected: []string{}},
+		"field_scoped":     {query: "model:corolla", expected: []string{"2"}},
+		"field_mismatch":   {query: "make:corolla", expected: []string{}},
+		"exact_phrase":     {query: `"sports cars"`, expected: []string{"3"}},
+		"exact_no_prefix":  {query: `"cor"`, expected: []string{}},
+		"field_analyzer":   {query: "car", expected: []string{"3"}},
+		"synonym":          {query: "vw", expected: []string{"4"}},
+		"nested":           {query: "portland", expected: []string{"4"}},
+		"array":            {query: "hybrid", expected: []string{"2"}},
+		"typo":             {query: "toyta", typos: true, expected: []string{"1", "2"}},
+		"typo_disabled":    {query: "toyta", expected: []string{}},
+		"only_exclusions":  {query: "-toyota", expected: nil},
+		"exclusion_pruned": {query: "ford -toyota", expected: []string{"3"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &searchx.SearchConfig{}
+			searchx.WithTypoTolerance(tc.typos).Apply(cfg)
+			terms := searcher.parseQuery(tc.query)
+
+			ids := searcher.index.candidates(terms, cfg)
+			if tc.expected == nil {
+				if ids != nil {
+					t.Fatalf("Expected every document to be a candidate, got %v", sortedIDs(ids))
+				}
+				return
+			}
+
+			got := sortedIDs(ids)
+			if len(got) != len(tc.expected) {
+				t.Fatalf("Expected candidates %v, got %v", tc.expected, got)
+			}
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Fatalf("Expected candidates %v, got %v", tc.expected, got)
+				}
+			}
+
+			// Every document scoring above zero must be a candidate
+			for _, doc := range docs {
+				if searcher.scoreTerms(searcher.corpus(), searcher.analyzeDocument(doc), terms, cfg) > 0 && !ids[doc.ID] {
+					t.Errorf("Document %s matches %q but is not a candidate", doc.ID, tc.query)
+				}
+			}
+		})
+	}
+}
+
+func TestIndexMaintenance(t *testing.T) {
+	searcher := New()
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"make": "Toyota", "model": "Camry"}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"make": "Toyota", "model": "Corolla"}})
+
+	// Updating a document replaces its terms
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"make": "Honda", "model": "Civic"}})
+	searcher.RemoveDocument("2")
+
+	tests := map[string]struct {
+		term     string
+		expected []string
+	}{
+		"updated_away": {term: "camry", expected: nil},
+		"removed":      {term: "corolla", expected: nil},
+		"shared":       {term: "toyota", expected: nil},
+		"updated_to":   {term: "civic", expected: []string{"1"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, ok := searcher.index.postings.get(tc.term)
+			if tc.expected == nil {
+				if ok {
+					t.Errorf("Expected no postings for %q, got %v", tc.term, p)
+				}
+				if node := searcher.index.terms.find(tc.term); node != nil && node.docs > 0 {
+					t.Errorf("Expected %q to be removed from the terms", tc.term)
+				}
+				return
+			}
+
+			ids := make(map[string]bool)
+			for id := range p.all() {
+				ids[id] = true
+			}
+			if got := sortedIDs(ids); len(got) != len(tc.expected) || got[0] != tc.expected[0] {
+				t.Errorf("Expected postings %v for %q, got %v", tc.expected, tc.term, got)
+			}
+		})
+	}
+
+	searcher.Clear()
+	if searcher.index.postings.len() != 0 || searcher.index.tokens.len() != 0 {
+		t.Error("Expected Clear to empty the index")
+	}
+}
+
+// sortedIDs returns the IDs of a set in ascending order.
+func sortedIDs(ids map[string]bool) []string {
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
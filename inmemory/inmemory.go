@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"sort"
+	"io"
+	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/letmevibethatforyou/searchx"
+	"github.com/letmevibethatforyou/searchx/analysis"
 )
 
 // Document represents a JSON document in the in-memory database.
@@ -22,36 +25,182 @@ type Document struct {
 }
 
 // Searcher implements the searchx.Searcher interface using an in-memory store.
+//
+// Changes are made by a single writer at a time to a version of the state of
+// the searcher, or of each of its shards, then published atomically. Searches
+// run against the last published version without locking, so that they never
+// wait for writers nor block them. Versions share the structures they have in
+// common, see pmap.
 type Searcher struct {
-	mu        sync.RWMutex
-	documents []Document
-	idIndex   map[string]int // maps document ID to index in documents slice
+	state
+
+	// mu serializes the writers, and published holds the last version published.
+	// gen is the generation of the changes to the next version. With shards, the
+	// writers of a single document only hold the mu of its shard, see lock.
+	mu        sync.Mutex
+	published atomic.Pointer[Searcher]
+	gen       uint64
+
+	// logMu guards the numbering and logging of changes and the publishing of
+	// versions, which writers to different shards do concurrently.
+	logMu sync.Mutex
+
+	// wal logs the changes when set, see WithWAL. walErr holds the first
+	// error writing to wal.
+	wal    io.Writer
+	walErr error
+
+	// watchers holds the state of the queries registered with Watch in the
+	// documents of the searcher, or of the shard.
+	watchers map[*watcher]*watchState
+
+	// shards holds the shards the documents are partitioned into, see
+	// WithShards, and shardCount their number. logged is the number of the
+	// first change to a shard not published yet, or 0, guarded by the logMu of
+	// its searcher.
+	shards     []*Searcher
+	shardCount int
+	logged     uint64
 }
 
-// New creates a new in-memory searcher.
+// state is a version of the documents and configuration of a searcher.
+// Published versions are never changed.
+type state struct {
+	documents pvec[Document]
+	idIndex   pmap[string, int] // maps document ID to index in documents
+	synonyms  synonymDictionary
+
+	// tombstones marks the positions of removed documents, which keep their
+	// slot in documents until compact reclaims them. removed counts them.
+	tombstones pvec[bool]
+	removed    int
+
+	// vocabulary holds the terms of indexed documents for suggestions.
+	vocabulary trie
+
+	// index maps analyzed terms to the documents containing them.
+	index *invertedIndex
+
+	analyzer       analysis.Analyzer
+	fieldAnalyzers map[string]analysis.Analyzer
+
+	// k1 and b are the BM25 parameters, see WithBM25.
+	k1, b float64
+
+	// rules contains the query rules in the order they were saved.
+	rules []searchx.Rule
+
+	// schema validates searches when set, see WithSchema.
+	schema *searchx.Schema
+	// fieldIndexes indexes the values of the fields the schema declares filterable.
+	fieldIndexes map[string]*fieldIndex
+
+	// similarities and hnswConfigs configure vector fields, and vectorIndexes
+	// holds the HNSW graphs of the fields configured with WithHNSW.
+	similarities  map[string]searchx.Similarity
+	hnswConfigs   map[string]HNSWConfig
+	vectorIndexes map[string]*hnswIndex
+
+	// sequence numbers the changes made to the documents, see WithWAL.
+	sequence uint64
+
+	// shardVersions holds the versions of the shards, when there are any.
+	shardVersions []*Searcher
+}
+
+// New creates a new in-memory searcher configured with the given options.
 // The searcher is ready to use and is safe for concurrent operations.
-func New() *Searcher {
-	return &Searcher{
-		documents: make([]Document, 0),
-		idIndex:   make(map[string]int),
+func New(opts ...Option) *Searcher {
+	s := &Searcher{
+		state: state{
+			analyzer: analysis.Standard(),
+			k1:       defaultBM25K1,
+			b:        defaultBM25B,
+		},
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	s.index = newInvertedIndex()
+	s.resetFieldIndexes()
+	s.resetVectorIndexes()
+	s.newShards()
+	s.publish()
+	return s
+}
+
+// publish makes the changes made so far visible to searches, and starts a new
+// generation for the next ones. The events of the changes are then sent to
+// watchers. The caller must hold the write lock of the searcher and its shards.
+func (s *Searcher) publish() {
+	s.logMu.Lock()
+	if s.shards != nil {
+		s.shardVersions = make([]*Searcher, len(s.shards))
+		for i, shard := range s.shards {
+			shard.publishVersion()
+			shard.logged = 0
+			s.shardVersions[i] = shard.current()
+		}
+	}
+	s.publishVersion()
+	s.logMu.Unlock()
+
+	for _, shard := range s.writeShards() {
+		shard.sendEvents()
+	}
+}
+
+// publishVersion publishes the state of the searcher, without its shards or
+// events, see publish. The caller must hold the write lock.
+func (s *Searcher) publishVersion() {
+	s.published.Store(&Searcher{state: s.state})
+	s.gen++
+}
+
+// current returns the last published version of the searcher, which searches
+// read without locking.
+func (s *Searcher) current() *Searcher {
+	return s.published.Load()
 }
 
 // AddDocument adds a document to the in-memory store.
 // If a document with the same ID already exists, it will be updated.
 // This method is safe for concurrent use.
 func (s *Searcher) AddDocument(doc Document) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	shard := s.lockShard(doc.ID)
+	defer shard.mu.Unlock()
+
+	s.logChange(walEntry{Op: walAdd, ID: doc.ID, Fields: doc.Fields})
+	shard.addDocument(doc)
+	s.publishShard(shard)
+}
 
-	if idx, exists := s.idIndex[doc.ID]; exists {
+// addDocument implements AddDocument. The caller must hold the write lock.
+func (s *Searcher) addDocument(doc Document) {
+	if shard := s.shardFor(doc.ID); shard != s {
+		shard.addDocument(doc)
+		return
+	}
+
+	s.index = s.index.edit(s.gen)
+	if idx, exists := s.idIndex.get(doc.ID); exists {
 		// Update existing document
-		s.documents[idx] = doc
+		s.unindexVocabulary(s.documents.get(idx))
+		s.index.remove(s.gen, doc.ID)
+		s.unindexFields(idx, s.documents.get(idx))
+		s.documents.set(s.gen, idx, doc)
+		s.indexFields(idx, doc)
 	} else {
 		// Add new document
-		s.idIndex[doc.ID] = len(s.documents)
-		s.documents = append(s.documents, doc)
+		s.idIndex.set(s.gen, doc.ID, s.documents.len())
+		s.documents.append(s.gen, doc)
+		s.tombstones.append(s.gen, false)
+		s.indexFields(s.documents.len()-1, doc)
 	}
+	s.indexVocabulary(doc)
+	s.index.add(s.gen, doc.ID, s.analyzeDocument(doc))
+	s.indexVectors(doc)
+	s.percolateAdded(doc)
 }
 
 // AddJSON adds a JSON document to the in-memory store by parsing the provided JSON data.
@@ -74,46 +223,141 @@ func (s *Searcher) AddJSON(id string, jsonData []byte) error {
 // Returns true if the document was found and removed, false if the document was not found.
 // This method is safe for concurrent use.
 func (s *Searcher) RemoveDocument(id string) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	shard := s.lockShard(id)
+	defer shard.mu.Unlock()
 
-	idx, exists := s.idIndex[id]
-	if !exists {
+	if !shard.contains(id) {
 		return false
 	}
+	s.logChange(walEntry{Op: walRemove, ID: id})
+	shard.removeDocument(id)
+	s.publishShard(shard)
+	return true
+}
 
-	// Remove from slice
-	s.documents = append(s.documents[:idx], s.documents[idx+1:]...)
+// removeDocument implements RemoveDocument. The caller must hold the write lock.
+func (s *Searcher) removeDocument(id string) bool {
+	if shard := s.shardFor(id); shard != s {
+		return shard.removeDocument(id)
+	}
 
-	// Rebuild index
-	delete(s.idIndex, id)
-	for i := idx; i < len(s.documents); i++ {
-		s.idIndex[s.documents[i].ID] = i
+	idx, exists := s.idIndex.get(id)
+	if !exists {
+		return false
 	}
 
+	doc := s.documents.get(idx)
+	s.index = s.index.edit(s.gen)
+	s.unindexVocabulary(doc)
+	s.index.remove(s.gen, id)
+	s.unindexFields(idx, doc)
+	s.unindexVectors(id)
+	s.percolateRemoved(doc)
+
+	// Leave a tombstone so that later documents keep their positions
+	s.documents.set(s.gen, idx, Document{})
+	s.tombstones.set(s.gen, idx, true)
+	s.removed++
+	s.idIndex.delete(s.gen, id)
+
+	if s.removed >= minCompaction && s.removed*2 > s.documents.len() {
+		s.compact()
+	}
 	return true
 }
 
+// documentByID returns the document with an ID, which must be present.
+func (s *Searcher) documentByID(id string) Document {
+	pos, _ := s.idIndex.get(id)
+	return s.documents.get(pos)
+}
+
+// minCompaction is the number of tombstones below which compact is not worth it.
+const minCompaction = 1024
+
+// compact reclaims the slots of removed documents, moving the later documents
+// back while keeping them in insertion order. It runs once tombstones make up
+// more than half of the slots, so removals take constant amortized time.
+// The caller must hold the write lock.
+func (s *Searcher) compact() {
+	moved := make([]int, s.documents.len())
+	var documents pvec[Document]
+	var tombstones pvec[bool]
+	for pos, doc := range s.documents.all() {
+		if s.tombstones.get(pos) {
+			moved[pos] = -1
+			continue
+		}
+		moved[pos] = documents.len()
+		s.idIndex.set(s.gen, doc.ID, documents.len())
+		documents.append(s.gen, doc)
+		tombstones.append(s.gen, false)
+	}
+
+	s.documents = documents
+	s.tombstones = tombstones
+	s.removed = 0
+	s.moveFieldIndexes(moved)
+}
+
 // Clear removes all documents from the store.
 // This method is safe for concurrent use.
 func (s *Searcher) Clear() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock()
+	defer s.unlock()
 
-	s.documents = make([]Document, 0)
-	s.idIndex = make(map[string]int)
+	s.logChange(walEntry{Op: walClear})
+	s.clear()
+	s.publish()
+}
+
+// clear implements Clear. The caller must hold the write lock.
+func (s *Searcher) clear() {
+	for _, shard := range s.shards {
+		shard.clear()
+	}
+	for pos, doc := range s.documents.all() {
+		if !s.tombstones.get(pos) {
+			s.percolateRemoved(doc)
+		}
+	}
+	s.documents = pvec[Document]{}
+	s.idIndex = pmap[string, int]{}
+	s.tombstones = pvec[bool]{}
+	s.removed = 0
+	s.vocabulary = trie{}
+	s.index = newInvertedIndex()
+	s.resetFieldIndexes()
+	s.resetVectorIndexes()
 }
 
 // Size returns the number of documents currently stored in the in-memory store.
 // This method is safe for concurrent use.
 func (s *Searcher) Size() int {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return len(s.documents)
+	return s.current().size()
 }
 
-// Search implements the searchx.Searcher interface.
+// size implements Size for a version of the searcher.
+func (s *Searcher) size() int {
+	if s.shardVersions == nil {
+		return s.documents.len() - s.removed
+	}
+	n := 0
+	for _, shard := range s.shardVersions {
+		n += shard.size()
+	}
+	return n
+}
+
+// Search implements the searchx.Searcher interface. It searches the documents
+// as of the last change published, without waiting for changes in progress.
+// This method is safe for concurrent use.
 func (s *Searcher) Search(ctx context.Context, query string, opts ...searchx.SearchOption) (*searchx.Results, error) {
+	return s.current().search(ctx, query, opts...)
+}
+
+// search implements Search for a published version of the searcher.
+func (s *Searcher) search(ctx context.Context, query string, opts ...searchx.SearchOption) (*searchx.Results, error) {
 	startTime := time.Now()
 
 	// Check context
@@ -134,39 +378,60 @@ func (s *Searcher) Search(ctx context.Context, query string, opts ...searchx.Sea
 		cfg.Limit = 10
 	}
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	// Filter documents
-	var matches []scoredDocument
-	for _, doc := range s.documents {
-		// Check context periodically
-		select {
-		case <-ctx.Done():
-			return nil, searchx.ErrCanceled
-		default:
+	if err := validateAggregations(cfg.Aggregations); err != nil {
+		return nil, err
+	}
+	if err := validateVectorOptions(cfg); err != nil {
+		return nil, err
+	}
+	if s.schema != nil {
+		if err := s.schema.Check(cfg); err != nil {
+			return nil, err
 		}
+	}
 
-		// Apply filters
-		if !s.matchesFilters(doc, cfg.Filters) {
-			continue
-		}
+	// Apply query rules, which may rewrite the query and add filters
+	effects, err := s.applyRules(query, cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Filters = searchx.ResolveTimes(cfg.Filters, startTime)
+	terms := s.parseQuery(effects.query)
+
+	// Only the matches up to the requested page are needed, unless they are
+	// aggregated, collapsed by the distinct field or changed by rules
+	k := -1
+	if len(cfg.Aggregations) == 0 && cfg.Distinct == nil && len(effects.hidden) == 0 && len(effects.promote) == 0 {
+		k = cfg.Offset + cfg.Limit
+	}
+	matches, dropped, err := s.shardMatches(ctx, terms, cfg, k)
+	if err != nil {
+		return nil, err
+	}
+	matches = s.hideAndPromote(matches, effects, cfg)
 
-		// Apply query matching
-		score := s.scoreDocument(doc, query)
-		if score > 0 {
-			matches = append(matches, scoredDocument{
-				document: doc,
-				score:    score,
-			})
-		}
+	// Aggregate over every match, before collapsing and pagination
+	var aggregations map[string]*searchx.Aggregation
+	if len(cfg.Aggregations) > 0 {
+		aggregations = computeAggregations(matches, cfg.Aggregations)
 	}
 
-	// Sort matches
-	s.sortMatches(matches, cfg.Sort)
+	// Sort matches, keeping only those up to the requested page unless they
+	// are collapsed by the distinct field, which needs them all. Then move
+	// pinned documents to their positions.
+	pinned, positions, matches := splitPinned(matches, effects.promote)
+	var groupCounts map[string]int64
+	total := int64(len(matches) + len(pinned) + dropped)
+	if cfg.Distinct != nil {
+		s.sortMatches(matches, cfg.Sort)
+		matches, groupCounts = collapseMatches(matches, *cfg.Distinct)
+		total = int64(len(matches) + len(pinned))
+	} else {
+		matches = s.topMatches(matches, cfg.Sort, cfg.Offset+cfg.Limit)
+	}
+	matches = insertPinned(matches, pinned, positions)
 
 	// Apply pagination
-	total := int64(len(matches))
 	start := cfg.Offset
 	end := cfg.Offset + cfg.Limit
 	if end > len(matches) {
@@ -178,10 +443,12 @@ func (s *Searcher) Search(ctx context.Context, query string, opts ...searchx.Sea
 
 	// Build results
 	results := &searchx.Results{
-		Items: make([]searchx.Result, 0, end-start),
-		Total: total,
-		Query: query,
-		Took:  time.Since(startTime).Milliseconds(),
+		Items:        make([]searchx.Result, 0, end-start),
+		Total:        total,
+		Query:        query,
+		Took:         time.Since(startTime).Milliseconds(),
+		Aggregations: aggregations,
+		GroupCounts:  groupCounts,
 	}
 
 	// Convert matches to results
@@ -199,8 +466,13 @@ func (s *Searcher) Search(ctx context.Context, query string, opts ...searchx.Sea
 	}
 	results.MaxScore = maxScore
 
+	// Suggest spelling corrections when nothing matched
+	if total == 0 {
+		results.Suggestions = s.didYouMean(query, cfg.Filters)
+	}
+
 	// Set next offset for pagination
-	if end < len(matches) {
+	if int64(end) < total {
 		nextOffset := end
 		results.NextOffset = &nextOffset
 	}
@@ -208,111 +480,217 @@ func (s *Searcher) Search(ctx context.Context, query string, opts ...searchx.Sea
 	return results, nil
 }
 
-type scoredDocument struct {
-	document Document
-	score    float64
-}
+// lexicalMatches returns the documents passing the filters and matching the
+// query terms, scored by relevance with the statistics of stats. Only the
+// candidates found in the inverted index and passing the indexed filters are
+// scored.
+func (s *Searcher) lexicalMatches(ctx context.Context, stats corpus, terms []queryTerm, cfg *searchx.SearchConfig) ([]scoredDocument, error) {
+	plan := s.planFilters(cfg.Filters)
 
-// scoreDocument calculates the relevance score for a document based on the query.
-func (s *Searcher) scoreDocument(doc Document, query string) float64 {
-	if query == "" {
-		return 1.0 // All documents match empty query
-	}
-
-	query = strings.ToLower(query)
-	terms := strings.Fields(query)
-	if len(terms) == 0 {
-		return 1.0
-	}
+	var matches []scoredDocument
+	canceled := false
+	visit := func(pos int) bool {
+		// Check context periodically
+		select {
+		case <-ctx.Done():
+			canceled = true
+			return false
+		default:
+		}
 
-	score := 0.0
-	matchedTerms := 0
+		if s.tombstones.get(pos) {
+			return true
+		}
 
-	for _, term := range terms {
-		termMatched := false
-		for _, value := range doc.Fields {
-			if s.valueContainsTerm(value, term) {
-				termMatched = true
-				score += 1.0
-			}
+		// Apply filters
+		doc := s.documents.get(pos)
+		if !plan.matches(s, pos, doc) {
+			return true
 		}
-		if termMatched {
-			matchedTerms++
+
+		// Apply query matching
+		score := s.scoreTerms(stats, s.index.documentTokens(doc.ID), terms, cfg)
+		if score > 0 {
+			score = s.applyRanking(doc, score, cfg)
+			matches = append(matches, scoredDocument{
+				document: doc,
+				score:    score,
+			})
 		}
+		return true
 	}
 
-	if matchedTerms == 0 {
-		return 0
+	switch ids := s.index.candidates(terms, cfg); {
+	case ids != nil:
+		for _, pos := range s.positionsByID(ids) {
+			if !visit(pos) {
+				break
+			}
+		}
+	case plan.allowed != nil:
+		plan.allowed.forEach(visit)
+	default:
+		for pos := range s.documents.len() {
+			if !visit(pos) {
+				break
+			}
+		}
 	}
 
-	// Boost score if all terms matched
-	if matchedTerms == len(terms) {
-		score *= 1.5
+	if canceled {
+		return nil, searchx.ErrCanceled
 	}
+	return matches, nil
+}
 
-	return score
+type scoredDocument struct {
+	document Document
+	score    float64
 }
 
-// valueContainsTerm checks if a value contains the search term.
-func (s *Searcher) valueContainsTerm(value interface{}, term string) bool {
-	switch v := value.(type) {
-	case string:
-		return strings.Contains(strings.ToLower(v), term)
-	case []interface{}:
-		for _, item := range v {
-			if s.valueContainsTerm(item, term) {
-				return true
-			}
+// parseQuery parses the query syntax described by searchx.ParseQuery into analyzed terms.
+// Consecutive plain words sharing a field and occurrence are expanded with the
+// searcher's synonyms, while quoted phrases are kept as-is. Terms the default
+// analyzer reduces to nothing, such as stopwords or lone punctuation, are dropped.
+func (s *Searcher) parseQuery(query string) []queryTerm {
+	clauses := searchx.ParseQuery(query).Clauses
+
+	var terms []queryTerm
+	for i := 0; i < len(clauses); {
+		clause := clauses[i]
+		if clause.Phrase {
+			phrase := strings.ToLower(clause.Text)
+			terms = append(terms, queryTerm{
+				text:         phrase,
+				alternatives: []string{phrase},
+				field:        clause.Field,
+				occur:        clause.Occur,
+				exact:        true,
+			})
+			i++
+			continue
 		}
-	case map[string]interface{}:
-		for _, item := range v {
-			if s.valueContainsTerm(item, term) {
-				return true
+
+		// Group the run of words synonyms may span
+		var words []string
+		for ; i < len(clauses); i++ {
+			next := clauses[i]
+			if next.Phrase || next.Field != clause.Field || next.Occur != clause.Occur {
+				break
 			}
+			words = append(words, strings.ToLower(next.Text))
+		}
+		for _, term := range s.synonyms.expand(words) {
+			term.field = clause.Field
+			term.occur = clause.Occur
+			terms = append(terms, term)
 		}
-	default:
-		// Convert to string and check
-		str := fmt.Sprintf("%v", v)
-		return strings.Contains(strings.ToLower(str), term)
 	}
-	return false
+
+	kept := terms[:0]
+	for _, term := range terms {
+		s.analyzeTerm(&term)
+		if len(term.phrases[defaultAnalyzerKey]) > 0 {
+			kept = append(kept, term)
+		}
+	}
+	return kept
 }
 
-// sortMatches sorts the matched documents according to the sort configuration.
-func (s *Searcher) sortMatches(matches []scoredDocument, sortFields []searchx.SortField) {
-	if len(sortFields) == 0 {
-		// Default: sort by score descending
-		sort.Slice(matches, func(i, j int) bool {
-			return matches[i].score > matches[j].score
-		})
-		return
+// scoreTerms calculates the relevance score for a document, given its analyzed
+// fields, based on parsed query terms. Each term matching a field adds its BM25
+// score for the field, computed with the statistics of stats, weighted by the
+// field's weight from cfg.FieldWeights, or 1.0 if unset. Fields with a weight of zero or less are not searched.
+// A term matches a field if the term or any of its synonym alternatives does.
+// When typo tolerance is enabled, occurrences that only match with typos count
+// less towards term frequency the more typos they need.
+//
+// Documents matching an excluded term or missing a required term score zero.
+// Otherwise at least one optional term must match, unless the query has
+// required terms or only excluded ones.
+func (s *Searcher) scoreTerms(stats corpus, fields fieldTokens, terms []queryTerm, cfg *searchx.SearchConfig) float64 {
+	if len(terms) == 0 {
+		return 1.0 // All documents match empty query
 	}
 
-	sort.Slice(matches, func(i, j int) bool {
-		for _, sf := range sortFields {
-			if sf.Field == "_score" {
-				if matches[i].score != matches[j].score {
-					if sf.Desc {
-						return matches[i].score > matches[j].score
-					}
-					return matches[i].score < matches[j].score
-				}
+	score := 0.0
+	matched := make([]bool, len(terms))
+
+	for field, tokens := range fields {
+		weight := fieldWeight(cfg, field)
+		if weight <= 0 {
+			continue
+		}
+		_, key := s.analyzerFor(field)
+
+		for i, term := range terms {
+			if term.field != "" && term.field != field {
 				continue
 			}
 
-			val1 := matches[i].document.Fields[sf.Field]
-			val2 := matches[j].document.Fields[sf.Field]
+			// Count the occurrences of the term, keeping the rarest match
+			tf, idf := 0.0, 0.0
+			for _, phrase := range term.phrases[key] {
+				phraseOccurrences(tokens, phrase, term.exact, func(matchedTokens []int) bool {
+					tf++
+					idf = math.Max(idf, stats.phraseIDF(field, tokens, matchedTokens))
+					return true
+				})
+			}
 
-			cmp := s.compareValues(val1, val2)
-			if cmp != 0 {
-				if sf.Desc {
-					return cmp > 0
+			// Phrases and exclusions only match exactly. Fuzzy matches take the
+			// rarity of the query term rather than of the word they matched, so
+			// that a typo landing on a rare word never outranks exact matches
+			if tf == 0 && !term.exact && term.occur != searchx.OccurMustNot {
+				if maxTypos := typoBudget(cfg, term.text); maxTypos > 0 {
+					fuzzyOccurrences(tokens, term.phrases[key], maxTypos, func(_, typos int, matched string) {
+						tf += 1 / float64(1+typos)
+						idf = math.Max(idf, stats.idf(field, matched))
+					})
 				}
-				return cmp < 0
+			}
+
+			if tf == 0 {
+				continue
+			}
+			matched[i] = true
+			if term.occur != searchx.OccurMustNot {
+				score += weight * s.bm25(stats, field, tf, idf, len(tokens))
 			}
 		}
-		return false
-	})
+	}
+
+	positiveTerms := 0
+	matchedTerms := 0
+	for i, term := range terms {
+		switch {
+		case term.occur == searchx.OccurMustNot:
+			if matched[i] {
+				return 0
+			}
+			continue
+		case term.occur == searchx.OccurMust && !matched[i]:
+			return 0
+		}
+		positiveTerms++
+		if matched[i] {
+			matchedTerms++
+		}
+	}
+
+	if positiveTerms == 0 {
+		return 1.0 // Only exclusions, all remaining documents match
+	}
+	if matchedTerms == 0 {
+		return 0
+	}
+	return score
+}
+
+// sortMatches sorts the matched documents according to the sort configuration,
+// see topMatches.
+func (s *Searcher) sortMatches(matches []scoredDocument, sortFields []searchx.SortField) {
+	copy(matches, s.topMatches(matches, sortFields, len(matches)))
 }
 
 // compareValues compares two values for sorting.
@@ -328,6 +706,11 @@ func (s *Searcher) compareValues(v1, v2 interface{}) int {
 		return 1
 	}
 
+	// Compare times chronologically
+	if t1, t2, ok := toTimes(v1, v2); ok {
+		return t1.Compare(t2)
+	}
+
 	// Try to compare as numbers
 	if f1, ok1 := toFloat64(v1); ok1 {
 		if f2, ok2 := toFloat64(v2); ok2 {
@@ -0,0 +1,397 @@
+package inmemory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// loadBatchSize is the number of documents the bulk loaders add per write lock.
+const loadBatchSize = 1000
+
+// LineError describes a line that a bulk loader could not load.
+type LineError struct {
+	// Line is the number of the line in the input, starting at 1.
+	Line int
+	// Err describes why the line could not be loaded.
+	Err error
+}
+
+// Error implements the error interface.
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e LineError) Unwrap() error {
+	return e.Err
+}
+
+// LoadError is returned by the bulk loaders when some lines could not be
+// loaded. The other lines are loaded nonetheless.
+type LoadError struct {
+	// Lines contains an error per malformed line, in input order.
+	Lines []LineError
+}
+
+// Error implements the error interface.
+func (e *LoadError) Error() string {
+	if len(e.Lines) == 1 {
+		return e.Lines[0].Error()
+	}
+	return fmt.Sprintf("%d lines could not be loaded, first %v", len(e.Lines), e.Lines[0])
+}
+
+// Unwrap returns the errors of the lines.
+func (e *LoadError) Unwrap() []error {
+	errs := make([]error, len(e.Lines))
+	for i, lineErr := range e.Lines {
+		errs[i] = lineErr
+	}
+	return errs
+}
+
+// CSVOptions configures LoadCSV.
+type CSVOptions struct {
+	// IDField is the column holding the document IDs. It is required.
+	IDField string
+	// Comma is the field delimiter. It defaults to ','.
+	Comma rune
+	// Types sets the types of columns, overriding the schema and inference.
+	Types map[string]searchx.FieldType
+}
+
+// batchLoader adds documents to a searcher in batches and collects line errors.
+type batchLoader struct {
+	s      *Searcher
+	batch  []Document
+	loaded int
+	errs   []LineError
+}
+
+// add queues a document, adding the batch once it is full.
+func (l *batchLoader) add(doc Document) {
+	l.batch = append(l.batch, doc)
+	if len(l.batch) >= loadBatchSize {
+		l.flush()
+	}
+}
+
+// fail records a line that could not be loaded.
+func (l *batchLoader) fail(line int, err error) {
+	l.errs = append(l.errs, LineError{Line: line, Err: err})
+}
+
+// flush adds the queued documents under a single write lock.
+func (l *batchLoader) flush() {
+	if len(l.batch) == 0 {
+		return
+	}
+	l.s.AddDocuments(l.batch)
+	l.loaded += len(l.batch)
+	l.batch = l.batch[:0]
+}
+
+// finish adds the remaining documents and returns the number of documents
+// loaded along with a *LoadError if some lines could not be loaded.
+func (l *batchLoader) finish() (int, error) {
+	l.flush()
+	if len(l.errs) > 0 {
+		sort.SliceStable(l.errs, func(i, j int) bool {
+			return l.errs[i].Line < l.errs[j].Line
+		})
+		return l.loaded, &LoadError{Lines: l.errs}
+	}
+	return l.loaded, nil
+}
+
+// LoadNDJSON adds the documents read from newline-delimited JSON, one object
+// per line, taking their IDs from idField. String and number IDs are accepted,
+// numbers keeping the digits they are written with.
+// Documents are added in batches, each under a single write lock, and replace
+// existing documents with the same ID.
+//
+// Blank lines are skipped. Lines that are not JSON objects or lack an ID are
+// reported in a *LoadError after the other lines are loaded. Errors reading r
+// stop the load. It returns the number of documents loaded.
+// This method is safe for concurrent use.
+func (s *Searcher) LoadNDJSON(r io.Reader, idField string) (int, error) {
+	loader := &batchLoader{s: s}
+	reader := bufio.NewReader(r)
+
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			loaded, _ := loader.finish()
+			return loaded, errors.Wrapf(err, "failed to read line %d", line)
+		}
+
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			if doc, lineErr := ndjsonDocument(data, idField); lineErr != nil {
+				loader.fail(line, lineErr)
+			} else {
+				loader.add(doc)
+			}
+		}
+
+		if err == io.EOF {
+			return loader.finish()
+		}
+	}
+}
+
+// ndjsonDocument parses a line of NDJSON into a document.
+func ndjsonDocument(data []byte, idField string) (Document, error) {
+	// Numbers are decoded as json.Number so that large numeric IDs keep their
+	// digits, and the other numbers are then converted by floatNumbers
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return Document{}, errors.Wrap(err, "failed to unmarshal JSON")
+	}
+	if decoder.More() {
+		return Document{}, errors.New("unexpected data after JSON object")
+	}
+
+	var id string
+	switch v := fields[idField].(type) {
+	case string:
+		id = v
+	case json.Number:
+		id = v.String()
+	case nil:
+		return Document{}, errors.Newf("missing ID field %q", idField)
+	default:
+		return Document{}, errors.Newf("unsupported %T ID in field %q", v, idField)
+	}
+	if id == "" {
+		return Document{}, errors.Newf("empty ID in field %q", idField)
+	}
+	for name, value := range fields {
+		fields[name] = floatNumbers(value)
+	}
+	return Document{ID: id, Fields: fields}, nil
+}
+
+// maxExactInt is the largest integer up to which float64 holds every integer.
+const maxExactInt = 1 << 53
+
+// floatNumbers converts the json.Number values within a decoded JSON value to
+// float64, as json.Unmarshal decodes them, except integers beyond 2^53, which
+// float64 cannot hold exactly, which become int64 or uint64 instead.
+func floatNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil && (i > maxExactInt || i < -maxExactInt) {
+			return i
+		}
+		if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil && u > maxExactInt {
+			return u
+		}
+		f, _ := strconv.ParseFloat(v.String(), 64)
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = floatNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = floatNumbers(item)
+		}
+	}
+	return value
+}
+
+// LoadCSV adds the documents read from CSV with a header row naming the
+// columns, taking their IDs from opts.IDField. Documents are added in batches,
+// each under a single write lock, and replace existing documents with the same ID.
+//
+// Column types come from opts.Types, then from the schema, and are otherwise
+// inferred from the first batch of rows: a column is a number, bool or time
+// when all its values in the batch are, and a string otherwise. Numbers with
+// leading zeros, such as ZIP codes, are strings. Empty values are left out of
+// documents. Values are converted with searchx.CoerceValue, numbers to int64 or
+// float64 and times, in RFC 3339 or YYYY-MM-DD format, to time.Time.
+// Inferred types hold for the whole input: in a column whose first values are
+// all numbers, a later value such as "N/A" or "12A" fails its row rather than
+// being loaded as text. Set the types of such mixed columns in opts.Types.
+//
+// Rows that cannot be parsed, have the wrong number of values, lack an ID or
+// hold values not matching their column type are reported in a *LoadError
+// after the other rows are loaded. Errors reading r or a missing header stop
+// the load. It returns the number of documents loaded.
+// This method is safe for concurrent use.
+func (s *Searcher) LoadCSV(r io.Reader, opts CSVOptions) (int, error) {
+	if opts.IDField == "" {
+		return 0, errors.Wrap(searchx.ErrInvalidOption, "CSV ID field is required")
+	}
+
+	reader := csv.NewReader(r)
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read CSV header")
+	}
+	idColumn := -1
+	for i, name := range header {
+		if name == opts.IDField {
+			idColumn = i
+		}
+	}
+	if idColumn < 0 {
+		return 0, errors.Wrapf(searchx.ErrInvalidOption, "CSV header has no ID column %q", opts.IDField)
+	}
+
+	// Read the first batch of rows to infer column types
+	type row struct {
+		line   int
+		values []string
+	}
+	loader := &batchLoader{s: s}
+	var sample []row
+	read := func() (row, bool, error) {
+		for {
+			values, err := reader.Read()
+			if err == io.EOF {
+				return row{}, false, nil
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				loader.fail(parseErr.StartLine, parseErr.Err)
+				continue
+			}
+			if err != nil {
+				return row{}, false, errors.Wrap(err, "failed to read CSV")
+			}
+			line, _ := reader.FieldPos(0)
+			return row{line: line, values: values}, true, nil
+		}
+	}
+	for len(sample) < loadBatchSize {
+		rec, ok, err := read()
+		if err != nil {
+			loaded, _ := loader.finish()
+			return loaded, err
+		}
+		if !ok {
+			break
+		}
+		sample = append(sample, rec)
+	}
+
+	types := make([]searchx.FieldType, len(header))
+	for i, name := range header {
+		if t, ok := opts.Types[name]; ok {
+			types[i] = t
+		} else if f, ok := s.schemaField(name); ok && f.Type != searchx.FieldGeo {
+			types[i] = f.Type
+		} else {
+			column := make([]string, len(sample))
+			for j, rec := range sample {
+				column[j] = rec.values[i]
+			}
+			types[i] = inferColumnType(column)
+		}
+	}
+
+	addRow := func(rec row) {
+		doc := Document{ID: rec.values[idColumn], Fields: make(map[string]interface{}, len(header))}
+		if doc.ID == "" {
+			loader.fail(rec.line, errors.Newf("empty ID in column %q", opts.IDField))
+			return
+		}
+		for i, value := range rec.values {
+			if value == "" {
+				continue
+			}
+			converted, err := searchx.CoerceValue(types[i], value)
+			if err != nil {
+				loader.fail(rec.line, errors.Wrapf(err, "column %q", header[i]))
+				return
+			}
+			doc.Fields[header[i]] = converted
+		}
+		loader.add(doc)
+	}
+
+	for _, rec := range sample {
+		addRow(rec)
+	}
+	for {
+		rec, ok, err := read()
+		if err != nil {
+			loaded, _ := loader.finish()
+			return loaded, err
+		}
+		if !ok {
+			return loader.finish()
+		}
+		addRow(rec)
+	}
+}
+
+// schemaField returns the declaration of a field in the schema, if any.
+func (s *Searcher) schemaField(name string) (searchx.Field, bool) {
+	if s.schema == nil {
+		return searchx.Field{}, false
+	}
+	return s.schema.Field(name)
+}
+
+// inferColumnType returns the narrowest type of the non-empty values of a CSV
+// column, see LoadCSV.
+func inferColumnType(values []string) searchx.FieldType {
+	var inferred searchx.FieldType
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		t := inferValueType(value)
+		if inferred == "" {
+			inferred = t
+		} else if t != inferred {
+			return searchx.FieldString
+		}
+	}
+	if inferred == "" {
+		return searchx.FieldString
+	}
+	return inferred
+}
+
+// inferValueType returns the type a CSV value looks like.
+func inferValueType(value string) searchx.FieldType {
+	digits := strings.TrimLeft(value, "+-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return searchx.FieldString // Leading zeros, such as ZIP codes
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return searchx.FieldNumber
+	}
+	switch strings.ToLower(value) {
+	case "true", "false":
+		return searchx.FieldBool
+	}
+	if _, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return searchx.FieldTime
+	}
+	if _, err := time.Parse(time.DateOnly, value); err == nil {
+		return searchx.FieldTime
+	}
+	return searchx.FieldString
+}
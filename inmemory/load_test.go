@@ -0,0 +1,262 @@
+package inmemory
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestLoadNDJSON(t *testing.T) {
+	input := strings.Join([]string{
+		`{"request_id": "user-001", "title": "Add tenant search"}`,
+		``,
+		`{"request_id": 42, "title": "Numeric ID"}`,
+		`{"title": "Missing ID"}`,
+		`not json`,
+		`{"request_id": "user-001", "title": "Replaced"}`,
+		`{"request_id": true}`,
+		`{"request_id": 9007199254740993, "year": 2018, "tags": [1.5]}`,
+		`{"request_id": "trailing"} {}`,
+	}, "\n")
+
+	searcher := New()
+	loaded, err := searcher.LoadNDJSON(strings.NewReader(input), "request_id")
+	if loaded != 4 {
+		t.Errorf("Expected 4 documents loaded, got %d", loaded)
+	}
+
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("Expected a LoadError, got %v", err)
+	}
+	var lines []int
+	for _, lineErr := range loadErr.Lines {
+		lines = append(lines, lineErr.Line)
+	}
+	if fmt.Sprint(lines) != "[4 5 7 9]" {
+		t.Errorf("Expected errors on lines [4 5 7 9], got %v", lines)
+	}
+
+	if searcher.Size() != 3 {
+		t.Fatalf("Expected 3 documents, got %d", searcher.Size())
+	}
+	if title := searcher.documentByID("user-001").Fields["title"]; title != "Replaced" {
+		t.Errorf("Expected the later line to replace the document, got %v", title)
+	}
+	if _, ok := searcher.idIndex.get("42"); !ok {
+		t.Error("Expected the numeric ID to be formatted as 42")
+	}
+	// IDs above 2^53 keep their digits and other numbers are float64
+	if _, ok := searcher.idIndex.get("9007199254740993"); !ok {
+		t.Fatal("Expected the large numeric ID to keep its digits")
+	}
+	large := searcher.documentByID("9007199254740993")
+	if year, tags := large.Fields["year"], large.Fields["tags"]; year != 2018.0 || fmt.Sprint(tags) != "[1.5]" {
+		t.Errorf("Expected float64 numbers, got %T %v and %v", year, year, tags)
+	}
+}
+
+func TestLoadNDJSONBatches(t *testing.T) {
+	var input strings.Builder
+	for i := 0; i < 2*loadBatchSize+1; i++ {
+		fmt.Fprintf(&input, "{\"id\": \"%d\"}\n", i)
+	}
+
+	searcher := New()
+	loaded, err := searcher.LoadNDJSON(strings.NewReader(input.String()), "id")
+	if err != nil {
+		t.Fatalf("LoadNDJSON failed: %v", err)
+	}
+	if loaded != 2*loadBatchSize+1 || searcher.Size() != loaded {
+		t.Errorf("Expected %d documents, loaded %d and got %d", 2*loadBatchSize+1, loaded, searcher.Size())
+	}
+}
+
+func TestLoadCSV(t *testing.T) {
+	input := strings.Join([]string{
+		`vin,make,year,price,used,listed_at,zip,trim`,
+		`1,Toyota,2018,21500.5,true,2024-03-01,02134,LE`,
+		`2,Ford,2020,30000,false,2024-03-02T10:00:00Z,94103,2`,
+		`3,"Honda, Inc.",2019,,TRUE,,10001,`,
+		`4,Kia,2021`,
+		`,Mazda,2022,1,false,2024-03-03,10002,`,
+		`5,"Bad "quote",2022,1,false,2024-03-03,10002,`,
+	}, "\n")
+
+	searcher := New()
+	loaded, err := searcher.LoadCSV(strings.NewReader(input), CSVOptions{IDField: "vin"})
+	if loaded != 3 {
+		t.Errorf("Expected 3 documents loaded, got %d", loaded)
+	}
+
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("Expected a LoadError, got %v", err)
+	}
+	var lines []int
+	for _, lineErr := range loadErr.Lines {
+		lines = append(lines, lineErr.Line)
+	}
+	if fmt.Sprint(lines) != "[5 6 7]" {
+		t.Errorf("Expected errors on lines [5 6 7], got %v", lines)
+	}
+
+	tests := map[string]struct {
+		id       string
+		field    string
+		expected interface{}
+	}{
+		"int":          {id: "1", field: "year", expected: int64(2018)},
+		"float":        {id: "1", field: "price", expected: 21500.5},
+		"int_in_float": {id: "2", field: "price", expected: int64(30000)},
+		"bool":         {id: "3", field: "used", expected: true},
+		"date":         {id: "1", field: "listed_at", expected: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		"time":         {id: "2", field: "listed_at", expected: time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)},
+		"leading_zero": {id: "1", field: "zip", expected: "02134"},
+		"mixed":        {id: "2", field: "trim", expected: "2"},
+		"quoted":       {id: "3", field: "make", expected: "Honda, Inc."},
+		"empty":        {id: "3", field: "price", expected: nil},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := searcher.documentByID(tc.id).Fields[tc.field]
+			if want, ok := tc.expected.(time.Time); ok {
+				if gotTime, ok := got.(time.Time); !ok || !gotTime.Equal(want) {
+					t.Errorf("Expected %v, got %v (%T)", want, got, got)
+				}
+				return
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %v (%T), got %v (%T)", tc.expected, tc.expected, got, got)
+			}
+		})
+	}
+}
+
+func TestLoadCSVTypes(t *testing.T) {
+	input := "id;year;zip\n1;2018;02134\n2;2020;94103\n3;2021;N/A\n"
+
+	searcher := New(WithSchema(searchx.NewSchema(
+		searchx.Field{Name: "year", Type: searchx.FieldString},
+	)))
+	loaded, err := searcher.LoadCSV(strings.NewReader(input), CSVOptions{
+		IDField: "id",
+		Comma:   ';',
+		Types:   map[string]searchx.FieldType{"zip": searchx.FieldNumber},
+	})
+	if loaded != 2 {
+		t.Errorf("Expected 2 documents loaded, got %d", loaded)
+	}
+
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || len(loadErr.Lines) != 1 || loadErr.Lines[0].Line != 4 {
+		t.Fatalf("Expected an error on line 4, got %v", err)
+	}
+	if !errors.Is(err, searchx.ErrInvalidExpression) {
+		t.Errorf("Expected the conversion error to be wrapped, got %v", err)
+	}
+
+	doc := searcher.documentByID("1")
+	if doc.Fields["year"] != "2018" {
+		t.Errorf("Expected the schema type to keep year a string, got %v (%T)", doc.Fields["year"], doc.Fields["year"])
+	}
+	if doc.Fields["zip"] != int64(2134) {
+		t.Errorf("Expected the option type to make zip a number, got %v (%T)", doc.Fields["zip"], doc.Fields["zip"])
+	}
+}
+
+func TestLoadCSVTypeChangeAfterSample(t *testing.T) {
+	// The first batch of codes are numbers and a later one is text
+	var input strings.Builder
+	input.WriteString("id,code\n")
+	for i := 0; i < loadBatchSize; i++ {
+		fmt.Fprintf(&input, "%d,%d\n", i, i)
+	}
+	fmt.Fprintf(&input, "%d,12A\n", loadBatchSize)
+
+	tests := map[string]struct {
+		types         map[string]searchx.FieldType
+		expectedLines []int
+		expectedCode  interface{}
+	}{
+		"inferred":  {expectedLines: []int{loadBatchSize + 2}, expectedCode: int64(7)},
+		"text_type": {types: map[string]searchx.FieldType{"code": searchx.FieldString}, expectedCode: "7"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			searcher := New()
+			loaded, err := searcher.LoadCSV(strings.NewReader(input.String()), CSVOptions{IDField: "id", Types: tc.types})
+
+			var lines []int
+			var loadErr *LoadError
+			if errors.As(err, &loadErr) {
+				for _, lineErr := range loadErr.Lines {
+					lines = append(lines, lineErr.Line)
+				}
+			} else if err != nil {
+				t.Fatalf("LoadCSV failed: %v", err)
+			}
+			if fmt.Sprint(lines) != fmt.Sprint(tc.expectedLines) {
+				t.Errorf("Expected errors on lines %v, got %v: %v", tc.expectedLines, lines, err)
+			}
+			if expected := loadBatchSize + 1 - len(tc.expectedLines); loaded != expected {
+				t.Errorf("Expected %d documents loaded, got %d", expected, loaded)
+			}
+			if code := searcher.documentByID("7").Fields["code"]; code != tc.expectedCode {
+				t.Errorf("Expected code %v (%T), got %v (%T)", tc.expectedCode, tc.expectedCode, code, code)
+			}
+		})
+	}
+}
+
+func TestLoadCSVErrors(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		opts  CSVOptions
+	}{
+		"missing_id_option": {input: "id\n1\n"},
+		"missing_id_column": {input: "vin\n1\n", opts: CSVOptions{IDField: "id"}},
+		"empty":             {input: "", opts: CSVOptions{IDField: "id"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			loaded, err := New().LoadCSV(strings.NewReader(tc.input), tc.opts)
+			if err == nil || loaded != 0 {
+				t.Errorf("Expected error and nothing loaded, got %d and %v", loaded, err)
+			}
+		})
+	}
+}
+
+func TestInferColumnType(t *testing.T) {
+	tests := map[string]struct {
+		values   []string
+		expected searchx.FieldType
+	}{
+		"ints":           {values: []string{"1", "-20", "300"}, expected: searchx.FieldNumber},
+		"floats":         {values: []string{"1.5", "2", "0.25"}, expected: searchx.FieldNumber},
+		"bools":          {values: []string{"true", "False"}, expected: searchx.FieldBool},
+		"dates":          {values: []string{"2024-01-31", "2024-02-01T00:00:00Z"}, expected: searchx.FieldTime},
+		"leading_zeros":  {values: []string{"02134", "94103"}, expected: searchx.FieldString},
+		"mixed":          {values: []string{"1", "true"}, expected: searchx.FieldString},
+		"empty_ignored":  {values: []string{"", "7"}, expected: searchx.FieldNumber},
+		"all_empty":      {values: []string{"", ""}, expected: searchx.FieldString},
+		"infinity":       {values: []string{"Inf"}, expected: searchx.FieldString},
+		"bool_like_ints": {values: []string{"0", "1"}, expected: searchx.FieldNumber},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := inferColumnType(tc.values); got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
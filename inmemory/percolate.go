@@ -0,0 +1,241 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// EventType is the kind of change reported by an Event.
+type EventType string
+
+const (
+	// EventMatch reports a document that started matching a watched query,
+	// because it was added or updated.
+	EventMatch EventType = "match"
+	// EventUnmatch reports a document that stopped matching a watched query,
+	// because it was updated or removed.
+	EventUnmatch EventType = "unmatch"
+)
+
+// Event is sent by Watch when a document starts or stops matching the query.
+type Event struct {
+	Type EventType
+	// Document is the document as added, or as it was before being removed.
+	Document Document
+}
+
+// watchQueueSize is the number of events queued for a watcher, past which its
+// receiver is considered gone, see Watch.
+const watchQueueSize = 10000
+
+// watcher is a query registered with Watch.
+type watcher struct {
+	terms []queryTerm
+	cfg   *searchx.SearchConfig
+
+	// queue holds the published events not taken by the receiver yet, and
+	// notify signals new ones. unsent counts the events queued or taken but not
+	// received, and overflowed reports that they were too many, ending the watch.
+	mu         sync.Mutex
+	queue      []Event
+	unsent     int
+	overflowed bool
+	notify     chan struct{}
+}
+
+// watchState is the state of a watcher in a shard, or in a searcher without
+// shards. It is guarded by the write lock of the shard.
+type watchState struct {
+	// matching holds the IDs of the documents matching the query, and pending
+	// the events of the changes not published yet.
+	matching map[string]bool
+	pending  []Event
+}
+
+// Watch registers a standing query and returns a channel receiving an event
+// whenever a document added or updated starts matching it, or a document
+// updated or removed stops matching it. Documents matching when Watch is called
+// do not cause events. Queries match as with Search, with the same query syntax
+// and filters; relative times in filters are resolved at every change. Query
+// rules, sorting, pagination and other options of the results are ignored.
+//
+// Events are sent once their change is published, so that searches made on
+// receiving them see the change. They are queued so that changes are never
+// blocked by slow receivers, up to 10,000 events: when a change would queue
+// more, its events are dropped, the channel is closed after the events queued
+// before and the query is unregistered. Receivers falling that far behind can
+// tell from ctx not being done, and Search and Watch again to catch up.
+// Otherwise the channel is closed once ctx is done, after which the query is
+// unregistered.
+// Vector queries cannot be watched and return searchx.ErrInvalidOption.
+// This method is safe for concurrent use.
+func (s *Searcher) Watch(ctx context.Context, query string, opts ...searchx.SearchOption) (<-chan Event, error) {
+	cfg := &searchx.SearchConfig{}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+	if cfg.Vector != nil {
+		return nil, errors.Wrap(searchx.ErrInvalidOption, "vector queries cannot be watched")
+	}
+	if s.schema != nil {
+		if err := s.schema.Check(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	s.lock()
+	w := &watcher{
+		terms:  s.parseQuery(query),
+		cfg:    cfg,
+		notify: make(chan struct{}, 1),
+	}
+	filters := searchx.ResolveTimes(cfg.Filters, time.Now())
+	for _, shard := range s.writeShards() {
+		state := &watchState{matching: make(map[string]bool)}
+		for pos, doc := range shard.documents.all() {
+			if !shard.tombstones.get(pos) && shard.watchMatches(w, doc, filters) {
+				state.matching[doc.ID] = true
+			}
+		}
+		if shard.watchers == nil {
+			shard.watchers = make(map[*watcher]*watchState)
+		}
+		shard.watchers[w] = state
+	}
+	s.unlock()
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer s.unwatch(w)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.notify:
+			}
+
+			queued, overflowed := w.take()
+			for _, event := range queued {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			w.received(len(queued))
+			if overflowed {
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// unwatch unregisters a watcher.
+func (s *Searcher) unwatch(w *watcher) {
+	s.lock()
+	defer s.unlock()
+	for _, shard := range s.writeShards() {
+		delete(shard.watchers, w)
+	}
+}
+
+// watchMatches reports whether a document matches a watched query, filtered
+// with the given resolved filters. The caller must hold the lock.
+func (s *Searcher) watchMatches(w *watcher, doc Document, filters []searchx.Expression) bool {
+	return s.matchesFilters(doc, filters) && s.scoreTerms(s.corpus(), s.index.documentTokens(doc.ID), w.terms, w.cfg) > 0
+}
+
+// percolateAdded records the events for a document just added or updated, to
+// send once published. The caller must hold the write lock.
+func (s *Searcher) percolateAdded(doc Document) {
+	now := time.Now()
+	for w, state := range s.watchers {
+		matches := s.watchMatches(w, doc, searchx.ResolveTimes(w.cfg.Filters, now))
+		switch {
+		case matches && !state.matching[doc.ID]:
+			state.matching[doc.ID] = true
+			state.record(Event{Type: EventMatch, Document: doc})
+		case !matches && state.matching[doc.ID]:
+			delete(state.matching, doc.ID)
+			state.record(Event{Type: EventUnmatch, Document: doc})
+		}
+	}
+}
+
+// percolateRemoved records the events for a document being removed, to send
+// once published. The caller must hold the write lock.
+func (s *Searcher) percolateRemoved(doc Document) {
+	for _, state := range s.watchers {
+		if state.matching[doc.ID] {
+			delete(state.matching, doc.ID)
+			state.record(Event{Type: EventUnmatch, Document: doc})
+		}
+	}
+}
+
+// sendEvents queues the events of the changes just published to a shard, or
+// to a searcher without shards, for the receivers. Watchers whose queue
+// overflows are left out of later changes. The caller must hold the write lock.
+func (s *Searcher) sendEvents() {
+	for w, state := range s.watchers {
+		if len(state.pending) == 0 {
+			continue
+		}
+		if !w.send(state.pending) {
+			delete(s.watchers, w)
+		}
+		state.pending = nil
+	}
+}
+
+// record adds an event to those of the changes not published yet. Events past
+// the size of the queue are left out, as they overflow it anyway.
+func (ws *watchState) record(event Event) {
+	if len(ws.pending) <= watchQueueSize {
+		ws.pending = append(ws.pending, event)
+	}
+}
+
+// send queues events for the receiver of the watcher. It returns false when
+// they overflow the queue, in which case they are dropped and the watch ends.
+func (w *watcher) send(events []Event) bool {
+	w.mu.Lock()
+	ok := !w.overflowed && w.unsent+len(events) <= watchQueueSize
+	if ok {
+		w.queue = append(w.queue, events...)
+		w.unsent += len(events)
+	} else {
+		w.overflowed = true
+	}
+	w.mu.Unlock()
+
+	select {
+	case w.notify <- struct{}{}:
+	default: // Already notified
+	}
+	return ok
+}
+
+// take returns the queued events and empties the queue, along with whether the
+// queue overflowed after them.
+func (w *watcher) take() ([]Event, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	events := w.queue
+	w.queue = nil
+	return events, w.overflowed
+}
+
+// received records that n events taken from the queue were received.
+func (w *watcher) received(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.unsent -= n
+}
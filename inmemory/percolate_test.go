@@ -0,0 +1,170 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestWatch(t *testing.T) {
+	searcher := New()
+	car := func(id, color string, price int) Document {
+		return Document{ID: id, Fields: map[string]interface{}{"model": "Ford Mustang", "color": color, "price": price}}
+	}
+	searcher.AddDocument(car("existing", "red", 25000))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := searcher.Watch(ctx, "mustang", searchx.Eq("color", "red"), searchx.Lt("price", 30000))
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	searcher.AddDocument(car("1", "red", 28000))  // Match
+	searcher.AddDocument(car("2", "blue", 20000)) // Not matching
+	searcher.AddDocument(car("1", "red", 27000))  // Still matching
+	searcher.AddDocument(car("1", "red", 32000))  // Unmatch
+	searcher.AddDocument(car("2", "red", 20000))  // Match
+	searcher.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"model": "Ford Focus", "color": "red", "price": 15000}})
+	searcher.RemoveDocument("2") // Unmatch
+	searcher.RemoveDocument("1") // Not matching
+	searcher.AddDocuments([]Document{car("4", "red", 10000), car("5", "red", 12000)})
+	searcher.Clear() // Unmatch existing, 4 and 5
+
+	expected := []string{
+		"match 1 28000", "unmatch 1 32000", "match 2 20000", "unmatch 2 20000",
+		"match 4 10000", "match 5 12000",
+		"unmatch existing 25000", "unmatch 4 10000", "unmatch 5 12000",
+	}
+	for i, want := range expected {
+		select {
+		case event := <-events:
+			got := fmt.Sprintf("%s %s %v", event.Type, event.Document.ID, event.Document.Fields["price"])
+			if got != want {
+				t.Errorf("Event %d: expected %q, got %q", i, want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Event %d: expected %q, got none", i, want)
+		}
+	}
+
+	cancel()
+	for range events {
+		t.Error("Expected no more events")
+	}
+	searcher.mu.Lock()
+	defer searcher.mu.Unlock()
+	if len(searcher.watchers) != 0 {
+		t.Errorf("Expected the watcher to be unregistered, got %d", len(searcher.watchers))
+	}
+}
+
+func TestWatchErrors(t *testing.T) {
+	searcher := New(WithSchema(searchx.NewSchema(
+		searchx.Field{Name: "price", Type: searchx.FieldNumber, Filterable: true},
+	)))
+
+	tests := map[string]struct {
+		opts     []searchx.SearchOption
+		expected error
+	}{
+		"vector":     {opts: []searchx.SearchOption{searchx.WithVector("embedding", []float32{1, 0}, 5)}, expected: searchx.ErrInvalidOption},
+		"undeclared": {opts: []searchx.SearchOption{searchx.Eq("color", "red")}, expected: searchx.ErrInvalidExpression},
+		"wrong_type": {opts: []searchx.SearchOption{searchx.Lt("price", "cheap")}, expected: searchx.ErrInvalidExpression},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := searcher.Watch(context.Background(), "", tc.opts...); !errors.Is(err, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestWatchEventsAfterPublish(t *testing.T) {
+	for name, opts := range map[string][]Option{"single": nil, "sharded": {WithShards(4)}} {
+		t.Run(name, func(t *testing.T) {
+			searcher := New(opts...)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			events, err := searcher.Watch(ctx, "mustang")
+			if err != nil {
+				t.Fatalf("Watch failed: %v", err)
+			}
+
+			// Events are held back until the change is published
+			searcher.lock()
+			searcher.addDocument(Document{ID: "1", Fields: map[string]interface{}{"model": "Ford Mustang"}})
+			for _, shard := range searcher.writeShards() {
+				for w := range shard.watchers {
+					if queued, _ := w.take(); len(queued) != 0 {
+						t.Errorf("Expected no events before publishing, got %d", len(queued))
+					}
+				}
+			}
+			searcher.publish()
+			searcher.unlock()
+
+			select {
+			case event := <-events:
+				results, err := searcher.Search(ctx, "mustang")
+				if err != nil {
+					t.Fatalf("Search failed: %v", err)
+				}
+				if event.Document.ID != "1" || results.Total != 1 {
+					t.Errorf("Expected document 1 to be found on its event, got %s and %d results", event.Document.ID, results.Total)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Expected an event for document 1")
+			}
+		})
+	}
+}
+
+func TestWatchOverflow(t *testing.T) {
+	searcher := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := searcher.Watch(ctx, "mustang")
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	car := func(id int) Document {
+		return Document{ID: fmt.Sprint(id), Fields: map[string]interface{}{"model": "Ford Mustang"}}
+	}
+	for i := 0; i < 10; i++ {
+		searcher.AddDocument(car(i))
+	}
+	// A batch overflowing the queue ends the watch without its events
+	batch := make([]Document, watchQueueSize)
+	for i := range batch {
+		batch[i] = car(10 + i)
+	}
+	searcher.AddDocuments(batch)
+	searcher.AddDocument(car(-1))
+
+	var received int
+	for event := range events {
+		if event.Document.ID != fmt.Sprint(received) {
+			t.Errorf("Event %d: expected document %d, got %s", received, received, event.Document.ID)
+		}
+		received++
+	}
+	if received != 10 {
+		t.Errorf("Expected the 10 events queued before the overflow, got %d", received)
+	}
+	if ctx.Err() != nil {
+		t.Error("Expected the channel to be closed before ctx is done")
+	}
+	searcher.mu.Lock()
+	defer searcher.mu.Unlock()
+	if len(searcher.watchers) != 0 {
+		t.Errorf("Expected the watcher to be unregistered, got %d", len(searcher.watchers))
+	}
+}
@@ -0,0 +1,317 @@
+package inmemory
+
+import (
+	"hash/maphash"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// The structures holding the documents of a searcher are persistent: copying
+// them shares their nodes, so that searches can read a published version while
+// changes are made to the next one. Changes are made in a generation, and only
+// change in place the nodes created in the same generation; nodes of earlier
+// generations, which may be shared with published versions, are copied first.
+// Publishing a version starts a new generation.
+
+// pmapSeed seeds the hashes of the keys of every pmap.
+var pmapSeed = maphash.MakeSeed()
+
+// pmapBits is the number of bits of the hash of a key consumed per level.
+const pmapBits = 5
+
+// pmap is a persistent hash map, a hash array mapped trie. The zero value is
+// an empty map.
+type pmap[K comparable, V any] struct {
+	root *pmapNode[K, V]
+	size int
+}
+
+// pmapNode is a node of a pmap. Its slots are ordered by the bits of bitmap,
+// one per child, except below the last level where they hold the keys whose
+// hashes collide, unordered.
+type pmapNode[K comparable, V any] struct {
+	gen    uint64
+	bitmap uint32
+	slots  []pmapSlot[K, V]
+}
+
+// pmapSlot holds either a child node or a key and its value.
+type pmapSlot[K comparable, V any] struct {
+	node  *pmapNode[K, V]
+	hash  uint64
+	key   K
+	value V
+}
+
+// len returns the number of keys in the map.
+func (m *pmap[K, V]) len() int {
+	return m.size
+}
+
+// get returns the value of a key.
+func (m *pmap[K, V]) get(key K) (V, bool) {
+	hash := maphash.Comparable(pmapSeed, key)
+	node := m.root
+	for shift := uint(0); node != nil; shift += pmapBits {
+		if shift >= 64 {
+			for _, slot := range node.slots {
+				if slot.key == key {
+					return slot.value, true
+				}
+			}
+			break
+		}
+
+		bit := uint32(1) << ((hash >> shift) & (1<<pmapBits - 1))
+		if node.bitmap&bit == 0 {
+			break
+		}
+		slot := &node.slots[bits.OnesCount32(node.bitmap&(bit-1))]
+		if slot.node == nil {
+			if slot.key == key {
+				return slot.value, true
+			}
+			break
+		}
+		node = slot.node
+	}
+
+	var zero V
+	return zero, false
+}
+
+// set sets the value of a key in generation gen.
+func (m *pmap[K, V]) set(gen uint64, key K, value V) {
+	root := m.root
+	if root == nil {
+		root = &pmapNode[K, V]{gen: gen}
+	}
+	var added bool
+	m.root, added = root.set(gen, 0, maphash.Comparable(pmapSeed, key), key, value)
+	if added {
+		m.size++
+	}
+}
+
+// delete removes a key in generation gen, reporting whether it was present.
+func (m *pmap[K, V]) delete(gen uint64, key K) bool {
+	if m.root == nil {
+		return false
+	}
+	root, removed := m.root.delete(gen, 0, maphash.Comparable(pmapSeed, key), key)
+	if removed {
+		m.root = root
+		m.size--
+	}
+	return removed
+}
+
+// all iterates over the keys and values of the map, in no particular order.
+func (m *pmap[K, V]) all() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if m.root != nil {
+			m.root.all(yield)
+		}
+	}
+}
+
+// edit returns the node, or a copy of it when it belongs to an earlier generation.
+func (n *pmapNode[K, V]) edit(gen uint64) *pmapNode[K, V] {
+	if n.gen == gen {
+		return n
+	}
+	return &pmapNode[K, V]{gen: gen, bitmap: n.bitmap, slots: slices.Clone(n.slots)}
+}
+
+// set implements pmap.set below this node, at the given hash shift. It returns
+// the node to replace this one with, and whether the key was added.
+func (n *pmapNode[K, V]) set(gen uint64, shift uint, hash uint64, key K, value V) (*pmapNode[K, V], bool) {
+	if shift >= 64 {
+		e := n.edit(gen)
+		for i := range e.slots {
+			if e.slots[i].key == key {
+				e.slots[i].value = value
+				return e, false
+			}
+		}
+		e.slots = append(e.slots, pmapSlot[K, V]{hash: hash, key: key, value: value})
+		return e, true
+	}
+
+	bit := uint32(1) << ((hash >> shift) & (1<<pmapBits - 1))
+	i := bits.OnesCount32(n.bitmap & (bit - 1))
+	if n.bitmap&bit == 0 {
+		e := n.edit(gen)
+		e.slots = slices.Insert(e.slots, i, pmapSlot[K, V]{hash: hash, key: key, value: value})
+		e.bitmap |= bit
+		return e, true
+	}
+
+	slot := n.slots[i]
+	switch {
+	case slot.node != nil:
+		child, added := slot.node.set(gen, shift+pmapBits, hash, key, value)
+		if child == slot.node {
+			return n, added
+		}
+		e := n.edit(gen)
+		e.slots[i].node = child
+		return e, added
+
+	case slot.key == key:
+		e := n.edit(gen)
+		e.slots[i].value = value
+		return e, false
+
+	default:
+		// Push the key already there down to a new node along with the new one
+		child := &pmapNode[K, V]{gen: gen}
+		child, _ = child.set(gen, shift+pmapBits, slot.hash, slot.key, slot.value)
+		child, _ = child.set(gen, shift+pmapBits, hash, key, value)
+		e := n.edit(gen)
+		e.slots[i] = pmapSlot[K, V]{node: child}
+		return e, true
+	}
+}
+
+// delete implements pmap.delete below this node, at the given hash shift. It
+// returns the node to replace this one with, and whether the key was removed.
+func (n *pmapNode[K, V]) delete(gen uint64, shift uint, hash uint64, key K) (*pmapNode[K, V], bool) {
+	if shift >= 64 {
+		for i := range n.slots {
+			if n.slots[i].key == key {
+				e := n.edit(gen)
+				e.slots = slices.Delete(e.slots, i, i+1)
+				return e, true
+			}
+		}
+		return n, false
+	}
+
+	bit := uint32(1) << ((hash >> shift) & (1<<pmapBits - 1))
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	i := bits.OnesCount32(n.bitmap & (bit - 1))
+	slot := n.slots[i]
+
+	if slot.node == nil {
+		if slot.key != key {
+			return n, false
+		}
+		e := n.edit(gen)
+		e.slots = slices.Delete(e.slots, i, i+1)
+		e.bitmap &^= bit
+		return e, true
+	}
+
+	child, removed := slot.node.delete(gen, shift+pmapBits, hash, key)
+	if !removed {
+		return n, false
+	}
+	e := n.edit(gen)
+	switch {
+	case len(child.slots) == 0:
+		e.slots = slices.Delete(e.slots, i, i+1)
+		e.bitmap &^= bit
+	case len(child.slots) == 1 && child.slots[0].node == nil:
+		e.slots[i] = child.slots[0] // Pull a lone key back up
+	default:
+		e.slots[i].node = child
+	}
+	return e, true
+}
+
+// all implements pmap.all below this node, reporting whether to go on.
+func (n *pmapNode[K, V]) all(yield func(K, V) bool) bool {
+	for i := range n.slots {
+		slot := &n.slots[i]
+		if slot.node != nil {
+			if !slot.node.all(yield) {
+				return false
+			}
+		} else if !yield(slot.key, slot.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// pvecChunkBits sets the number of items per chunk of a pvec.
+const pvecChunkBits = 8
+
+// pvec is a persistent vector, split in chunks copied separately. The zero
+// value is an empty vector.
+type pvec[T any] struct {
+	// gen is the generation of chunks, the slice itself.
+	gen    uint64
+	chunks []*pvecChunk[T]
+	n      int
+}
+
+// pvecChunk is a chunk of a pvec.
+type pvecChunk[T any] struct {
+	gen   uint64
+	items []T
+}
+
+// len returns the number of items in the vector.
+func (v *pvec[T]) len() int {
+	return v.n
+}
+
+// get returns the item at an index.
+func (v *pvec[T]) get(i int) T {
+	return v.chunks[i>>pvecChunkBits].items[i&(1<<pvecChunkBits-1)]
+}
+
+// set replaces the item at an index in generation gen.
+func (v *pvec[T]) set(gen uint64, i int, item T) {
+	v.chunk(gen, i>>pvecChunkBits).items[i&(1<<pvecChunkBits-1)] = item
+}
+
+// append adds an item at the end of the vector in generation gen.
+func (v *pvec[T]) append(gen uint64, item T) {
+	if v.n&(1<<pvecChunkBits-1) == 0 {
+		v.own(gen)
+		v.chunks = append(v.chunks, &pvecChunk[T]{gen: gen, items: make([]T, 0, 1<<pvecChunkBits)})
+	}
+	chunk := v.chunk(gen, len(v.chunks)-1)
+	chunk.items = append(chunk.items, item)
+	v.n++
+}
+
+// all iterates over the indexes and items of the vector in order.
+func (v *pvec[T]) all() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for c, chunk := range v.chunks {
+			for i, item := range chunk.items {
+				if !yield(c<<pvecChunkBits+i, item) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// own copies the chunk slice unless it belongs to generation gen.
+func (v *pvec[T]) own(gen uint64) {
+	if v.gen != gen {
+		v.chunks = slices.Clone(v.chunks)
+		v.gen = gen
+	}
+}
+
+// chunk returns a chunk to change in generation gen, copying it if needed.
+func (v *pvec[T]) chunk(gen uint64, c int) *pvecChunk[T] {
+	chunk := v.chunks[c]
+	if chunk.gen != gen {
+		v.own(gen)
+		items := make([]T, len(chunk.items), 1<<pvecChunkBits)
+		copy(items, chunk.items)
+		chunk = &pvecChunk[T]{gen: gen, items: items}
+		v.chunks[c] = chunk
+	}
+	return chunk
+}
@@ -0,0 +1,121 @@
+package inmemory
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestPmap(t *testing.T) {
+	tests := map[string]struct {
+		keys    int
+		ops     int
+		publish int // Operations per generation
+	}{
+		"small":             {keys: 10, ops: 200, publish: 1},
+		"large":             {keys: 5000, ops: 20000, publish: 7},
+		"single_generation": {keys: 1000, ops: 5000, publish: 5000},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			var m pmap[string, int]
+			expected := make(map[string]int)
+			gen := uint64(1)
+
+			// Versions kept along with their expected contents
+			type version struct {
+				m        pmap[string, int]
+				expected map[string]int
+			}
+			var versions []version
+
+			for op := 0; op < tc.ops; op++ {
+				key := fmt.Sprint(rng.Intn(tc.keys))
+				if rng.Intn(3) == 0 {
+					_, ok := expected[key]
+					if removed := m.delete(gen, key); removed != ok {
+						t.Fatalf("Op %d: expected delete of %s to report %v", op, key, ok)
+					}
+					delete(expected, key)
+				} else {
+					m.set(gen, key, op)
+					expected[key] = op
+				}
+
+				if op%tc.publish == 0 {
+					snapshot := make(map[string]int, len(expected))
+					for k, v := range expected {
+						snapshot[k] = v
+					}
+					versions = append(versions, version{m: m, expected: snapshot})
+					gen++
+				}
+			}
+			versions = append(versions, version{m: m, expected: expected})
+
+			// Every version keeps its contents despite later changes
+			for i, v := range versions {
+				if v.m.len() != len(v.expected) {
+					t.Fatalf("Version %d: expected %d keys, got %d", i, len(v.expected), v.m.len())
+				}
+				for key, value := range v.expected {
+					if got, ok := v.m.get(key); !ok || got != value {
+						t.Fatalf("Version %d: expected %s=%d, got %d, %v", i, key, value, got, ok)
+					}
+				}
+				seen := 0
+				for key, value := range v.m.all() {
+					if v.expected[key] != value {
+						t.Fatalf("Version %d: unexpected %s=%d", i, key, value)
+					}
+					seen++
+				}
+				if seen != len(v.expected) {
+					t.Fatalf("Version %d: expected to iterate %d keys, got %d", i, len(v.expected), seen)
+				}
+			}
+		})
+	}
+}
+
+func TestPvec(t *testing.T) {
+	var v pvec[int]
+	var expected []int
+	gen := uint64(1)
+
+	type version struct {
+		v        pvec[int]
+		expected []int
+	}
+	var versions []version
+
+	rng := rand.New(rand.NewSource(1))
+	for op := 0; op < 3000; op++ {
+		if len(expected) == 0 || rng.Intn(2) == 0 {
+			v.append(gen, op)
+			expected = append(expected, op)
+		} else {
+			i := rng.Intn(len(expected))
+			v.set(gen, i, op)
+			expected[i] = op
+		}
+		if op%5 == 0 {
+			versions = append(versions, version{v: v, expected: append([]int(nil), expected...)})
+			gen++
+		}
+	}
+	versions = append(versions, version{v: v, expected: expected})
+
+	for i, ver := range versions {
+		if ver.v.len() != len(ver.expected) {
+			t.Fatalf("Version %d: expected %d items, got %d", i, len(ver.expected), ver.v.len())
+		}
+		for j, item := range ver.v.all() {
+			if item != ver.expected[j] || ver.v.get(j) != item {
+				t.Fatalf("Version %d: expected item %d to be %d, got %d", i, j, ver.expected[j], item)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestQuerySyntax(t *testing.T) {
+	searcher := New()
+
+	searcher.AddDocument(Document{
+		ID:     "1",
+		Fields: map[string]interface{}{"title": "Learning Rust", "tags": []interface{}{"rust", "systems"}},
+	})
+	searcher.AddDocument(Document{
+		ID:     "2",
+		Fields: map[string]interface{}{"title": "Learning Go", "tags": []interface{}{"go", "systems"}},
+	})
+	searcher.AddDocument(Document{
+		ID:     "3",
+		Fields: map[string]interface{}{"title": "Go in Action", "tags": []interface{}{"go", "concurrency"}},
+	})
+	searcher.AddDocument(Document{
+		ID:     "4",
+		Fields: map[string]interface{}{"title": "Action Learning", "tags": []interface{}{"management"}},
+	})
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		query    string
+		expected []string
+	}{
+		"excluded_word": {
+			query:    "systems -rust",
+			expected: []string{"2"},
+		},
+		"only_exclusions": {
+			query:    "-go",
+			expected: []string{"1", "4"},
+		},
+		"required_word": {
+			query:    "+go learning",
+			expected: []string{"2", "3"},
+		},
+		"phrase_in_order": {
+			query:    `"learning go"`,
+			expected: []string{"2"},
+		},
+		"phrase_is_exact": {
+			query:    `"learning g"`,
+			expected: []string{},
+		},
+		"excluded_phrase": {
+			query:    `learning -"action learning"`,
+			expected: []string{"1", "2"},
+		},
+		"field_term": {
+			query:    "title:go",
+			expected: []string{"2", "3"},
+		},
+		"field_restricts_match": {
+			query:    "tags:learning",
+			expected: []string{},
+		},
+		"excluded_field_term": {
+			query:    "systems -tags:rust",
+			expected: []string{"2"},
+		},
+		"unterminated_phrase": {
+			query:    `"go in`,
+			expected: []string{"3"},
+		},
+		"lone_dash_is_ignored": {
+			query:    "concurrency -",
+			expected: []string{"3"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			ids := make([]string, 0, len(results.Items))
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+			}
+			sort.Strings(ids)
+
+			if len(ids) != len(tc.expected) {
+				t.Fatalf("Expected %v, got %v", tc.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != tc.expected[i] {
+					t.Errorf("Expected %v, got %v", tc.expected, ids)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRequiredTermsRankOptionalMatchesFirst(t *testing.T) {
+	searcher := New()
+
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"title": "Go"}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"title": "Go Concurrency"}})
+
+	results, err := searcher.Search(context.Background(), "+go concurrency", searchx.WithLimit(10))
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(results.Items) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results.Items))
+	}
+	if results.Items[0].ID != "2" {
+		t.Errorf("Expected document matching the optional term first, got %s", results.Items[0].ID)
+	}
+}
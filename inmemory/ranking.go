@@ -0,0 +1,94 @@
+package inmemory
+
+import (
+	"math"
+	"time"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// fieldWeight returns the weight configured for a field, defaulting to 1.0.
+func fieldWeight(cfg *searchx.SearchConfig, field string) float64 {
+	if cfg == nil || cfg.FieldWeights == nil {
+		return 1.0
+	}
+	if weight, ok := cfg.FieldWeights[field]; ok {
+		return weight
+	}
+	return 1.0
+}
+
+// applyRanking applies the decay functions and field boosts from the search
+// configuration to a document's relevance score.
+func (s *Searcher) applyRanking(doc Document, score float64, cfg *searchx.SearchConfig) float64 {
+	for _, decay := range cfg.Decays {
+		score *= decayFactor(doc, decay)
+	}
+
+	for _, field := range cfg.BoostBy {
+		score *= boostFactor(doc, field)
+	}
+
+	return score
+}
+
+// decayFactor computes the multiplier for a decay function.
+// The factor is 1.0 at the origin and halves with every scale of distance.
+// Documents where the field is missing or not comparable are left untouched.
+func decayFactor(doc Document, decay searchx.Decay) float64 {
+	value, ok := doc.Fields[decay.Field]
+	if !ok {
+		return 1.0
+	}
+
+	v, ok := toDecayValue(value)
+	if !ok {
+		return 1.0
+	}
+
+	origin, ok := toDecayValue(decay.Origin)
+	if !ok {
+		return 1.0
+	}
+
+	scale, ok := toDecayScale(decay.Scale)
+	if !ok || scale <= 0 {
+		return 1.0
+	}
+
+	return math.Pow(0.5, math.Abs(v-origin)/scale)
+}
+
+// boostFactor returns the multiplier for a boost field.
+// Documents where the field is missing or not a positive number are left untouched.
+func boostFactor(doc Document, field string) float64 {
+	value, ok := toFloat64(doc.Fields[field])
+	if !ok || value <= 0 {
+		return 1.0
+	}
+	return value
+}
+
+// toDecayValue converts a numeric or date value to a float64. Dates are parsed
+// with searchx.ParseTime, and expressed in seconds since the Unix epoch.
+func toDecayValue(v interface{}) (float64, bool) {
+	switch v.(type) {
+	case time.Time, string:
+		t, ok := searchx.ParseTime(v, time.Time{})
+		if !ok {
+			return 0, false
+		}
+		return float64(t.UnixNano()) / float64(time.Second), true
+	default:
+		return toFloat64(v)
+	}
+}
+
+// toDecayScale converts a decay scale to a float64.
+// Durations are expressed in seconds to match toDecayValue.
+func toDecayScale(v interface{}) (float64, bool) {
+	if d, ok := v.(time.Duration); ok {
+		return d.Seconds(), true
+	}
+	return toFloat64(v)
+}
@@ -0,0 +1,176 @@
+package inmemory
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestFieldWeights(t *testing.T) {
+	searcher := New()
+
+	searcher.AddDocument(Document{
+		ID: "1",
+		Fields: map[string]interface{}{
+			"title":       "Family sedan",
+			"description": "Reliable Toyota with low mileage",
+		},
+	})
+	searcher.AddDocument(Document{
+		ID: "2",
+		Fields: map[string]interface{}{
+			"title":       "Toyota Camry",
+			"description": "Family sedan",
+		},
+	})
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		opts          []searchx.SearchOption
+		expectedOrder []string
+		expectedTotal int64
+	}{
+		"title_weighted_higher": {
+			opts:          []searchx.SearchOption{searchx.WithFieldWeights(map[string]float64{"title": 3})},
+			expectedOrder: []string{"2", "1"},
+			expectedTotal: 2,
+		},
+		"description_weighted_higher": {
+			opts:          []searchx.SearchOption{searchx.WithFieldWeights(map[string]float64{"description": 3})},
+			expectedOrder: []string{"1", "2"},
+			expectedTotal: 2,
+		},
+		"zero_weight_excludes_field": {
+			opts:          []searchx.SearchOption{searchx.WithFieldWeights(map[string]float64{"description": 0})},
+			expectedOrder: []string{"2"},
+			expectedTotal: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(ctx, "toyota", tc.opts...)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			if results.Total != tc.expectedTotal {
+				t.Errorf("Expected %d results, got %d", tc.expectedTotal, results.Total)
+			}
+
+			for i, id := range tc.expectedOrder {
+				if i >= len(results.Items) {
+					t.Fatalf("Expected result at index %d with ID %s, but got no result", i, id)
+				}
+				if results.Items[i].ID != id {
+					t.Errorf("At index %d: expected ID %s, got %s", i, id, results.Items[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyRanking(t *testing.T) {
+	searcher := New()
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	doc := Document{
+		ID: "1",
+		Fields: map[string]interface{}{
+			"year":       2015,
+			"listed_at":  now.Add(-7 * 24 * time.Hour).Format(time.RFC3339),
+			"popularity": 3.0,
+			"featured":   "yes",
+			"built_on":   now.Add(-14 * 24 * time.Hour).Format(time.DateOnly),
+		},
+	}
+
+	tests := map[string]struct {
+		opts     []searchx.SearchOption
+		expected float64
+	}{
+		"no_ranking": {
+			expected: 2.0,
+		},
+		"numeric_decay": {
+			opts:     []searchx.SearchOption{searchx.WithDecay("year", 2025, 5)},
+			expected: 0.5,
+		},
+		"date_decay_from_rfc3339": {
+			opts:     []searchx.SearchOption{searchx.WithDecay("listed_at", now, 7*24*time.Hour)},
+			expected: 1.0,
+		},
+		"date_decay_from_date_only": {
+			opts:     []searchx.SearchOption{searchx.WithDecay("built_on", now, 7*24*time.Hour)},
+			expected: 0.5,
+		},
+		"decay_missing_field": {
+			opts:     []searchx.SearchOption{searchx.WithDecay("missing", 2025, 5)},
+			expected: 2.0,
+		},
+		"boost_by_numeric_field": {
+			opts:     []searchx.SearchOption{searchx.WithBoostBy("popularity")},
+			expected: 6.0,
+		},
+		"boost_by_non_numeric_field": {
+			opts:     []searchx.SearchOption{searchx.WithBoostBy("featured")},
+			expected: 2.0,
+		},
+		"decay_and_boost_combined": {
+			opts: []searchx.SearchOption{
+				searchx.WithDecay("year", 2025, 5),
+				searchx.WithBoostBy("popularity"),
+			},
+			expected: 1.5,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := &searchx.SearchConfig{}
+			for _, opt := range tc.opts {
+				opt.Apply(cfg)
+			}
+
+			score := searcher.applyRanking(doc, 2.0, cfg)
+			if math.Abs(score-tc.expected) > 1e-9 {
+				t.Errorf("Expected score %f, got %f", tc.expected, score)
+			}
+		})
+	}
+}
+
+func TestRankingSurfacesNewerListings(t *testing.T) {
+	searcher := New()
+	now := time.Now()
+
+	searcher.AddDocument(Document{
+		ID: "old",
+		Fields: map[string]interface{}{
+			"title":     "Ford Mustang",
+			"listed_at": now.Add(-60 * 24 * time.Hour).Format(time.RFC3339),
+		},
+	})
+	searcher.AddDocument(Document{
+		ID: "new",
+		Fields: map[string]interface{}{
+			"title":     "Ford Mustang",
+			"listed_at": now.Add(-24 * time.Hour).Format(time.RFC3339),
+		},
+	})
+
+	results, err := searcher.Search(context.Background(), "mustang",
+		searchx.WithDecay("listed_at", now, 30*24*time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(results.Items) != 2 || results.Items[0].ID != "new" {
+		t.Errorf("Expected newer listing first, got %+v", results.Items)
+	}
+}
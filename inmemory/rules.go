@@ -0,0 +1,270 @@
+package inmemory
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// SaveRules adds query rules applied by Search.
+// A rule with the same ID as an existing one replaces it.
+// This method is safe for concurrent use.
+func (s *Searcher) SaveRules(rules ...searchx.Rule) {
+	s.lock()
+	defer s.unlock()
+
+	// Published versions share the rules
+	s.rules = slices.Clone(s.rules)
+	for _, rule := range rules {
+		replaced := false
+		if rule.ID != "" {
+			for i, existing := range s.rules {
+				if existing.ID == rule.ID {
+					s.rules[i] = rule
+					replaced = true
+					break
+				}
+			}
+		}
+		if !replaced {
+			s.rules = append(s.rules, rule)
+		}
+	}
+	s.publish()
+}
+
+// DeleteRule removes a query rule by ID.
+// Returns true if the rule was found and removed.
+// This method is safe for concurrent use.
+func (s *Searcher) DeleteRule(id string) bool {
+	s.lock()
+	defer s.unlock()
+
+	for i, rule := range s.rules {
+		if rule.ID == id {
+			s.rules = slices.Delete(slices.Clone(s.rules), i, i+1)
+			s.publish()
+			return true
+		}
+	}
+	return false
+}
+
+// ruleEffects holds the combined consequences of the rules applying to a search.
+type ruleEffects struct {
+	// query is the query to run, possibly rewritten.
+	query string
+	// promote contains the pinned documents by ascending position, at most once per ID.
+	promote []searchx.Promotion
+	// hidden contains the IDs of hidden documents.
+	hidden map[string]bool
+}
+
+// applyRules evaluates the rules against the query and filters of a search,
+// adding the filters of the matching rules to cfg. Rules are evaluated in the
+// order they were saved: the first rewrite of the query wins, as does the first
+// position of a document pinned by several rules.
+//
+// With a schema, the filters of the rules are coerced like those of the search,
+// which the caller must have checked: a condition filter that cannot be coerced
+// never matches, and an added filter that cannot be coerced returns
+// ErrInvalidExpression.
+func (s *Searcher) applyRules(query string, cfg *searchx.SearchConfig) (ruleEffects, error) {
+	effects := ruleEffects{query: query}
+	if len(s.rules) == 0 {
+		return effects, nil
+	}
+
+	words := strings.Fields(strings.ToLower(query))
+	filters := cfg.Filters
+	rewritten := false
+	pinned := make(map[string]bool)
+	for _, rule := range s.rules {
+		condition := rule.Condition
+		if s.schema != nil {
+			coerced, err := s.schema.CoerceFilters(condition.Filters)
+			if err != nil {
+				continue
+			}
+			condition.Filters = coerced
+		}
+		if !ruleMatches(condition, words, filters) {
+			continue
+		}
+
+		consequence := rule.Consequence
+		if consequence.Query != nil && !rewritten {
+			effects.query = *consequence.Query
+			rewritten = true
+		}
+		added := consequence.Filters
+		if s.schema != nil {
+			var err error
+			if added, err = s.schema.CoerceFilters(added); err != nil {
+				return ruleEffects{}, errors.Wrapf(err, "rule %s", rule.ID)
+			}
+		}
+		cfg.Filters = append(cfg.Filters, added...)
+		for _, id := range consequence.Hide {
+			if effects.hidden == nil {
+				effects.hidden = make(map[string]bool)
+			}
+			effects.hidden[id] = true
+		}
+		for _, promotion := range consequence.Promote {
+			if !pinned[promotion.ID] {
+				pinned[promotion.ID] = true
+				effects.promote = append(effects.promote, promotion)
+			}
+		}
+	}
+
+	sort.SliceStable(effects.promote, func(i, j int) bool {
+		return effects.promote[i].Position < effects.promote[j].Position
+	})
+	return effects, nil
+}
+
+// ruleMatches reports whether a rule condition holds for the query words and
+// the filters of a search.
+func ruleMatches(condition searchx.RuleCondition, words []string, filters []searchx.Expression) bool {
+	for _, required := range condition.Filters {
+		found := false
+		for _, filter := range filters {
+			if reflect.DeepEqual(filter, required) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if condition.Anchoring == "" {
+		return true
+	}
+
+	pattern := strings.Fields(strings.ToLower(condition.Pattern))
+	switch condition.Anchoring {
+	case searchx.AnchoringIs:
+		return equalWords(words, pattern)
+	case searchx.AnchoringStartsWith:
+		return len(words) >= len(pattern) && equalWords(words[:len(pattern)], pattern)
+	case searchx.AnchoringEndsWith:
+		return len(words) >= len(pattern) && equalWords(words[len(words)-len(pattern):], pattern)
+	case searchx.AnchoringContains:
+		for i := 0; i+len(pattern) <= len(words); i++ {
+			if equalWords(words[i:i+len(pattern)], pattern) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// equalWords reports whether two word lists are equal.
+func equalWords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hideAndPromote removes the hidden documents from matches and adds the pinned
+// documents that did not match the query but pass the filters, so that they are
+// counted and aggregated like other results.
+func (s *Searcher) hideAndPromote(matches []scoredDocument, effects ruleEffects, cfg *searchx.SearchConfig) []scoredDocument {
+	if len(effects.hidden) == 0 && len(effects.promote) == 0 {
+		return matches
+	}
+
+	kept := make([]scoredDocument, 0, len(matches)+len(effects.promote))
+	present := make(map[string]bool, len(matches))
+	for _, match := range matches {
+		if effects.hidden[match.document.ID] {
+			continue
+		}
+		kept = append(kept, match)
+		present[match.document.ID] = true
+	}
+
+	for _, promotion := range effects.promote {
+		doc, ok := s.lookup(promotion.ID)
+		if !ok || present[promotion.ID] || effects.hidden[promotion.ID] {
+			continue
+		}
+		if !s.matchesFilters(doc, cfg.Filters) {
+			continue
+		}
+		kept = append(kept, scoredDocument{document: doc})
+		present[promotion.ID] = true
+	}
+	return kept
+}
+
+// splitPinned separates the pinned documents from the other matches,
+// returning them by ascending position along with their positions.
+func splitPinned(matches []scoredDocument, promote []searchx.Promotion) (pinned []scoredDocument, positions []int, rest []scoredDocument) {
+	if len(promote) == 0 {
+		return nil, nil, matches
+	}
+
+	byID := make(map[string]scoredDocument, len(promote))
+	rest = make([]scoredDocument, 0, len(matches))
+	for _, match := range matches {
+		if containsPromotion(promote, match.document.ID) {
+			byID[match.document.ID] = match
+		} else {
+			rest = append(rest, match)
+		}
+	}
+
+	for _, promotion := range promote {
+		if match, ok := byID[promotion.ID]; ok {
+			pinned = append(pinned, match)
+			positions = append(positions, promotion.Position)
+		}
+	}
+	return pinned, positions, rest
+}
+
+// containsPromotion reports whether a document is pinned.
+func containsPromotion(promote []searchx.Promotion, id string) bool {
+	for _, promotion := range promote {
+		if promotion.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// insertPinned inserts pinned documents at their positions, given in ascending
+// order. Positions past the end of the results append the document.
+func insertPinned(matches, pinned []scoredDocument, positions []int) []scoredDocument {
+	if len(pinned) == 0 {
+		return matches
+	}
+
+	result := make([]scoredDocument, 0, len(matches)+len(pinned))
+	next := 0
+	for i, match := range pinned {
+		for len(result) < positions[i] && next < len(matches) {
+			result = append(result, matches[next])
+			next++
+		}
+		result = append(result, match)
+	}
+	return append(result, matches[next:]...)
+}
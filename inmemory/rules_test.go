@@ -0,0 +1,191 @@
+package inmemory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestRules(t *testing.T) {
+	docs := []Document{
+		{ID: "1", Fields: map[string]interface{}{"title": "Tesla Model 3", "fuel": "electric", "price": 40000}},
+		{ID: "2", Fields: map[string]interface{}{"title": "Nissan Leaf", "fuel": "electric", "price": 28000}},
+		{ID: "3", Fields: map[string]interface{}{"title": "Toyota Camry", "fuel": "gas", "price": 25000}},
+		{ID: "4", Fields: map[string]interface{}{"title": "Chevrolet Bolt", "fuel": "electric", "price": 19000}},
+		{ID: "5", Fields: map[string]interface{}{"title": "Honda Civic", "fuel": "gas", "price": 18000}},
+	}
+	empty := ""
+	electric := "electric"
+
+	tests := map[string]struct {
+		rules         []searchx.Rule
+		query         string
+		filters       []searchx.SearchOption
+		expectedOrder []string
+	}{
+		"no_rules": {
+			query:         "electric",
+			expectedOrder: []string{"4", "2", "1"},
+		},
+		"pin_match": {
+			rules:         []searchx.Rule{searchx.PinRule("sponsored", "electric", "1")},
+			query:         "electric",
+			expectedOrder: []string{"1", "4", "2"},
+		},
+		"pin_non_match": {
+			rules:         []searchx.Rule{searchx.PinRule("sponsored", "electric", "3")},
+			query:         "Electric",
+			expectedOrder: []string{"3", "4", "2", "1"},
+		},
+		"pin_position": {
+			rules: []searchx.Rule{{
+				ID:          "sponsored",
+				Condition:   searchx.RuleCondition{Pattern: "electric", Anchoring: searchx.AnchoringIs},
+				Consequence: searchx.RuleConsequence{Promote: []searchx.Promotion{{ID: "3", Position: 1}}},
+			}},
+			query:         "electric",
+			expectedOrder: []string{"4", "3", "2", "1"},
+		},
+		"pin_past_end": {
+			rules: []searchx.Rule{{
+				ID:          "sponsored",
+				Condition:   searchx.RuleCondition{Pattern: "electric", Anchoring: searchx.AnchoringIs},
+				Consequence: searchx.RuleConsequence{Promote: []searchx.Promotion{{ID: "3", Position: 10}}},
+			}},
+			query:         "electric",
+			expectedOrder: []string{"4", "2", "1", "3"},
+		},
+		"pin_respects_filters": {
+			rules:         []searchx.Rule{searchx.PinRule("sponsored", "electric", "3")},
+			query:         "electric",
+			filters:       []searchx.SearchOption{searchx.Eq("fuel", "electric")},
+			expectedOrder: []string{"4", "2", "1"},
+		},
+		"hide": {
+			rules:         []searchx.Rule{searchx.HideRule("recalled", "electric", "2")},
+			query:         "electric",
+			expectedOrder: []string{"4", "1"},
+		},
+		"hide_wins_over_pin": {
+			rules:         []searchx.Rule{searchx.PinRule("sponsored", "electric", "2"), searchx.HideRule("recalled", "electric", "2")},
+			query:         "electric",
+			expectedOrder: []string{"4", "1"},
+		},
+		"rewrite": {
+			rules: []searchx.Rule{{
+				ID:          "ev",
+				Condition:   searchx.RuleCondition{Pattern: "ev", Anchoring: searchx.AnchoringIs},
+				Consequence: searchx.RuleConsequence{Query: &electric},
+			}},
+			query:         "EV",
+			expectedOrder: []string{"4", "2", "1"},
+		},
+		"add_filters": {
+			rules: []searchx.Rule{{
+				ID:        "cheap",
+				Condition: searchx.RuleCondition{Pattern: "cheap", Anchoring: searchx.AnchoringStartsWith},
+				Consequence: searchx.RuleConsequence{
+					Filters: []searchx.Expression{searchx.Lt("price", 20000)},
+					Query:   &empty,
+				},
+			}},
+			query:         "cheap cars",
+			expectedOrder: []string{"5", "4"},
+		},
+		"anchoring_mismatch": {
+			rules: []searchx.Rule{{
+				ID:          "sponsored",
+				Condition:   searchx.RuleCondition{Pattern: "electric", Anchoring: searchx.AnchoringStartsWith},
+				Consequence: searchx.RuleConsequence{Promote: []searchx.Promotion{{ID: "3"}}},
+			}},
+			query:         "used electric",
+			expectedOrder: []string{"4", "2", "1"},
+		},
+		"filter_context": {
+			rules: []searchx.Rule{{
+				ID:          "featured",
+				Condition:   searchx.RuleCondition{Filters: []searchx.Expression{searchx.Eq("fuel", "electric")}},
+				Consequence: searchx.RuleConsequence{Promote: []searchx.Promotion{{ID: "1"}}},
+			}},
+			filters:       []searchx.SearchOption{searchx.Eq("fuel", "electric")},
+			expectedOrder: []string{"1", "4", "2"},
+		},
+		"filter_context_missing": {
+			rules: []searchx.Rule{{
+				ID:          "featured",
+				Condition:   searchx.RuleCondition{Filters: []searchx.Expression{searchx.Eq("fuel", "electric")}},
+				Consequence: searchx.RuleConsequence{Promote: []searchx.Promotion{{ID: "1"}}},
+			}},
+			query:         "electric",
+			expectedOrder: []string{"4", "2", "1"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			searcher := New()
+			for _, doc := range docs {
+				searcher.AddDocument(doc)
+			}
+			searcher.SaveRules(tc.rules...)
+
+			opts := append([]searchx.SearchOption{searchx.WithSort("price", false)}, tc.filters...)
+			results, err := searcher.Search(context.Background(), tc.query, opts...)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			ids := make([]string, 0, len(results.Items))
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.expectedOrder) {
+				t.Errorf("Expected %v, got %v", tc.expectedOrder, ids)
+			}
+			if results.Total != int64(len(tc.expectedOrder)) {
+				t.Errorf("Expected total %d, got %d", len(tc.expectedOrder), results.Total)
+			}
+		})
+	}
+}
+
+func TestSaveRules(t *testing.T) {
+	searcher := New()
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"title": "Tesla Model 3"}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"title": "Tesla Model Y"}})
+
+	search := func() []string {
+		results, err := searcher.Search(context.Background(), "tesla", searchx.WithSort("title", false))
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		var ids []string
+		for _, item := range results.Items {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	searcher.SaveRules(searchx.PinRule("featured", "tesla", "2"))
+	if ids := search(); !reflect.DeepEqual(ids, []string{"2", "1"}) {
+		t.Errorf("Expected pinned document first, got %v", ids)
+	}
+
+	// Saving a rule with the same ID replaces it
+	searcher.SaveRules(searchx.HideRule("featured", "tesla", "2"))
+	if ids := search(); !reflect.DeepEqual(ids, []string{"1"}) {
+		t.Errorf("Expected replaced rule to hide document 2, got %v", ids)
+	}
+
+	if !searcher.DeleteRule("featured") {
+		t.Error("Expected DeleteRule to find the rule")
+	}
+	if searcher.DeleteRule("featured") {
+		t.Error("Expected DeleteRule to report a missing rule")
+	}
+	if ids := search(); !reflect.DeepEqual(ids, []string{"1", "2"}) {
+		t.Errorf("Expected no rules applied, got %v", ids)
+	}
+}
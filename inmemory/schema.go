@@ -0,0 +1,12 @@
+package inmemory
+
+import "github.com/letmevibethatforyou/searchx"
+
+// WithSchema validates searches against a schema: filters and sorts must use
+// fields it allows, filter values are coerced to the declared types, and only
+// searchable fields are matched against the query.
+func WithSchema(schema *searchx.Schema) Option {
+	return func(s *Searcher) {
+		s.schema = schema
+	}
+}
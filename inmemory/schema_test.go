@@ -0,0 +1,160 @@
+package inmemory
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestSchema(t *testing.T) {
+	schema := searchx.NewSchema(
+		searchx.Field{Name: "title", Type: searchx.FieldString, Searchable: true},
+		searchx.Field{Name: "make", Type: searchx.FieldString, Filterable: true},
+		searchx.Field{Name: "year", Type: searchx.FieldNumber, Filterable: true, Sortable: true},
+		searchx.Field{Name: "certified", Type: searchx.FieldBool, Filterable: true},
+		searchx.Field{Name: "listed_at", Type: searchx.FieldTime, Filterable: true},
+		searchx.Field{Name: "location", Type: searchx.FieldGeo, Filterable: true},
+		searchx.Field{Name: "notes", Type: searchx.FieldString},
+	)
+
+	searcher := New(WithSchema(schema))
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{
+		"title": "Toyota Camry", "make": "toyota", "year": 2018, "certified": true,
+		"listed_at": "2024-03-01T00:00:00Z", "notes": "honda trade-in",
+	}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{
+		"title": "Honda Civic", "make": "honda", "year": 2020.0, "certified": false,
+		"listed_at": "2024-03-05T00:00:00Z",
+	}})
+
+	empty := ""
+	searcher.SaveRules(
+		searchx.Rule{
+			ID:        "recent",
+			Condition: searchx.RuleCondition{Pattern: "recent", Anchoring: searchx.AnchoringIs},
+			Consequence: searchx.RuleConsequence{
+				Query:   &empty,
+				Filters: []searchx.Expression{searchx.Gt("listed_at", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC).Unix())},
+			},
+		},
+		searchx.Rule{
+			ID:          "recalled",
+			Condition:   searchx.RuleCondition{Filters: []searchx.Expression{searchx.Eq("certified", "true")}},
+			Consequence: searchx.RuleConsequence{Hide: []string{"1"}},
+		},
+		searchx.Rule{
+			ID:        "red",
+			Condition: searchx.RuleCondition{Pattern: "red", Anchoring: searchx.AnchoringIs},
+			Consequence: searchx.RuleConsequence{
+				Filters: []searchx.Expression{searchx.Eq("color", "red")},
+			},
+		},
+	)
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		query       string
+		opts        []searchx.SearchOption
+		expectedIDs []string
+		expectedErr error
+	}{
+		"numeric_string": {
+			opts:        []searchx.SearchOption{searchx.Eq("year", "2018")},
+			expectedIDs: []string{"1"},
+		},
+		"numeric_string_comparison": {
+			opts:        []searchx.SearchOption{searchx.Gte("year", "2019")},
+			expectedIDs: []string{"2"},
+		},
+		"bool_string": {
+			opts:        []searchx.SearchOption{searchx.Eq("certified", "false")},
+			expectedIDs: []string{"2"},
+		},
+		"date_string": {
+			opts:        []searchx.SearchOption{searchx.Lt("listed_at", "2024-03-02")},
+			expectedIDs: []string{"1"},
+		},
+		"epoch_seconds": {
+			opts:        []searchx.SearchOption{searchx.Gt("listed_at", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC).Unix())},
+			expectedIDs: []string{"2"},
+		},
+		"nested": {
+			opts:        []searchx.SearchOption{searchx.Or(searchx.Eq("year", "2020"), searchx.Not(searchx.Exists("make")))},
+			expectedIDs: []string{"2"},
+		},
+		"searchable_fields_only": {
+			query:       "honda",
+			expectedIDs: []string{"2"},
+		},
+		"undeclared_field": {
+			opts:        []searchx.SearchOption{searchx.Eq("color", "red")},
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+		"not_filterable": {
+			opts:        []searchx.SearchOption{searchx.Exists("notes")},
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+		"nested_undeclared_field": {
+			opts:        []searchx.SearchOption{searchx.And(searchx.Eq("make", "honda"), searchx.Eq("color", "red"))},
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+		"not_a_number": {
+			opts:        []searchx.SearchOption{searchx.Eq("year", "twenty")},
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+		"bool_comparison": {
+			opts:        []searchx.SearchOption{searchx.Gt("certified", false)},
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+		"geo_equality": {
+			opts:        []searchx.SearchOption{searchx.Eq("location", "48.8,2.3")},
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+		"rule_filter": {
+			query:       "recent",
+			expectedIDs: []string{"2"},
+		},
+		"rule_condition": {
+			opts:        []searchx.SearchOption{searchx.Eq("certified", true)},
+			expectedIDs: []string{},
+		},
+		"rule_filter_undeclared_field": {
+			query:       "red",
+			expectedErr: searchx.ErrInvalidExpression,
+		},
+		"not_sortable": {
+			opts:        []searchx.SearchOption{searchx.WithSort("title", false)},
+			expectedErr: searchx.ErrInvalidOption,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(ctx, tc.query, tc.opts...)
+			if tc.expectedErr != nil {
+				if !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("Expected %v, got: %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			ids := make([]string, 0, len(results.Items))
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+			}
+			sort.Strings(ids)
+			if !reflect.DeepEqual(ids, tc.expectedIDs) {
+				t.Errorf("Expected %v, got %v", tc.expectedIDs, ids)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package inmemory
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 	"time"
@@ -10,7 +11,7 @@ import (
 	"github.com/letmevibethatforyou/searchx"
 )
 
-func TestScoreDocument(t *testing.T) {
+func TestScoreTerms(t *testing.T) {
 	searcher := New()
 
 	doc := Document{
@@ -27,6 +28,8 @@ func TestScoreDocument(t *testing.T) {
 		},
 	}
 
+	// The document is not indexed, so each field match scores the BM25 of a
+	// single occurrence of an unseen term: ln 2, without length normalization.
 	tests := map[string]struct {
 		query    string
 		expected float64
@@ -41,19 +44,19 @@ func TestScoreDocument(t *testing.T) {
 		},
 		"single_term_match": {
 			query:    "go",
-			expected: 4.5, // Found in multiple fields, boosted
+			expected: 3 * math.Ln2, // Found in title, description and tags ("golang")
 		},
 		"single_term_case_insensitive": {
 			query:    "GO",
-			expected: 4.5,
+			expected: 3 * math.Ln2,
 		},
 		"multiple_terms_all_match": {
 			query:    "go programming",
-			expected: 9.0, // Both terms match, with boost
+			expected: 6 * math.Ln2, // Both terms match three fields
 		},
 		"multiple_terms_partial_match": {
 			query:    "go python",
-			expected: 3.0, // Only "go" matches
+			expected: 3 * math.Ln2, // Only "go" matches
 		},
 		"no_match": {
 			query:    "javascript react",
@@ -61,39 +64,37 @@ func TestScoreDocument(t *testing.T) {
 		},
 		"match_in_array": {
 			query:    "golang",
-			expected: 1.5, // Single term match with boost
+			expected: math.Ln2,
 		},
 		"match_in_nested": {
 			query:    "john",
-			expected: 1.5,
+			expected: math.Ln2,
 		},
 		"numeric_match": {
 			query:    "2023",
-			expected: 1.5,
+			expected: math.Ln2,
 		},
 		"float_match": {
 			query:    "4.5",
-			expected: 1.5,
+			expected: math.Ln2,
 		},
 		"partial_word_match": {
 			query:    "program",
-			expected: 4.5, // Matches "programming" in multiple places
+			expected: 3 * math.Ln2, // Matches "programming" in multiple places
 		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			score := searcher.scoreDocument(doc, tc.query)
-			if score != tc.expected {
+			score := searcher.scoreTerms(searcher.corpus(), searcher.analyzeDocument(doc), searcher.parseQuery(tc.query), &searchx.SearchConfig{})
+			if math.Abs(score-tc.expected) > 1e-9 {
 				t.Errorf("Expected score %f, got %f for query %q", tc.expected, score, tc.query)
 			}
 		})
 	}
 }
 
-func TestValueContainsTerm(t *testing.T) {
-	searcher := New()
-
+func TestSearchValueTypes(t *testing.T) {
 	tests := map[string]struct {
 		value    interface{}
 		term     string
@@ -177,9 +178,15 @@ func TestValueContainsTerm(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			result := searcher.valueContainsTerm(tc.value, tc.term)
-			if result != tc.expected {
-				t.Errorf("Expected %v, got %v for term %q in value %v", tc.expected, result, tc.term, tc.value)
+			searcher := New()
+			searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"value": tc.value}})
+
+			results, err := searcher.Search(context.Background(), tc.term)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if found := len(results.Items) == 1; found != tc.expected {
+				t.Errorf("Expected %v, got %v for term %q in value %v", tc.expected, found, tc.term, tc.value)
 			}
 		})
 	}
@@ -921,7 +928,7 @@ func TestSearchMultipleWords(t *testing.T) {
 	}{
 		"two_words_both_match": {
 			query:         "quick fox",
-			expectedOrder: []string{"1", "2"}, // Both have both terms
+			expectedOrder: []string{"2", "1"}, // Both have both terms, 2 has quick twice
 		},
 		"three_words": {
 			query:         "quick brown fox",
@@ -929,7 +936,7 @@ func TestSearchMultipleWords(t *testing.T) {
 		},
 		"repeated_word_boosts_score": {
 			query:         "quick quick",
-			expectedOrder: []string{"1", "2"}, // Both match, but scoring is based on all terms
+			expectedOrder: []string{"2", "1"}, // Both match, 2 has quick twice
 		},
 	}
 
@@ -0,0 +1,238 @@
+package inmemory
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+	"sync"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// WithShards partitions the documents into n shards by hash of their IDs, each
+// with its own indexes. Searches run on every shard in parallel, each shard
+// selecting its best matches, which are then merged. Relevance is scored with
+// the term statistics of all the shards added up, so scores are those of a
+// single index.
+//
+// Each shard has its own write lock, so that documents added or removed one at
+// a time in different shards are indexed in parallel, only numbering and
+// logging the changes in turn. Batches, Clear and changes to the rules and
+// synonyms lock every shard, so that they are still published at once.
+// Searches read published versions without locking.
+// Values of n below 2 keep the documents in a single index.
+func WithShards(n int) Option {
+	return func(s *Searcher) {
+		s.shardCount = n
+	}
+}
+
+// newShards creates the shards of a searcher configured with WithShards, sharing
+// its configuration.
+func (s *Searcher) newShards() {
+	if s.shardCount < 2 {
+		return
+	}
+	s.shards = make([]*Searcher, s.shardCount)
+	for i := range s.shards {
+		shard := &Searcher{state: s.state}
+		shard.index = newInvertedIndex()
+		shard.resetFieldIndexes()
+		shard.resetVectorIndexes()
+		s.shards[i] = shard
+	}
+}
+
+// shardIndex returns the index of the shard holding the document with an ID,
+// among n shards.
+func shardIndex(id string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() % uint32(n))
+}
+
+// shardFor returns the shard holding the document with an ID, or the searcher
+// itself when it has no shards. Shards are never replaced, so no lock is needed.
+func (s *Searcher) shardFor(id string) *Searcher {
+	if s.shards == nil {
+		return s
+	}
+	return s.shards[shardIndex(id, len(s.shards))]
+}
+
+// lock acquires the write lock of the searcher and of each of its shards, for
+// changes to the documents of several shards or to the configuration.
+func (s *Searcher) lock() {
+	s.mu.Lock()
+	for _, shard := range s.shards {
+		shard.mu.Lock()
+	}
+}
+
+// unlock releases the locks acquired by lock.
+func (s *Searcher) unlock() {
+	for _, shard := range s.shards {
+		shard.mu.Unlock()
+	}
+	s.mu.Unlock()
+}
+
+// lockShard acquires the write lock of the shard holding the document with an
+// ID, or of the searcher itself when it has no shards, and returns the shard.
+// It is the only lock needed to change that document.
+func (s *Searcher) lockShard(id string) *Searcher {
+	shard := s.shardFor(id)
+	shard.mu.Lock()
+	return shard
+}
+
+// publishShard publishes the changes made to a shard locked with lockShard
+// along with the last versions of the other shards, then sends their events to
+// watchers. The caller must hold the write lock of the shard.
+func (s *Searcher) publishShard(shard *Searcher) {
+	if shard == s {
+		s.publish()
+		return
+	}
+
+	s.logMu.Lock()
+	shard.publishVersion()
+	shard.logged = 0
+	// Published versions share the list of shards
+	s.shardVersions = slices.Clone(s.shardVersions)
+	s.shardVersions[slices.Index(s.shards, shard)] = shard.current()
+	version := &Searcher{state: s.state}
+	version.sequence = s.publishedSequence()
+	s.published.Store(version)
+	s.logMu.Unlock()
+
+	shard.sendEvents()
+}
+
+// publishedSequence returns the number of the last change such that it and
+// every change before it are published, changes to other shards being
+// published concurrently. The caller must hold logMu.
+func (s *Searcher) publishedSequence() uint64 {
+	sequence := s.sequence
+	for _, shard := range s.shards {
+		if shard.logged != 0 && shard.logged <= sequence {
+			sequence = shard.logged - 1
+		}
+	}
+	return sequence
+}
+
+// writeShards returns the shards changes are made to, or the searcher itself
+// when it has no shards. The caller must hold the write lock.
+func (s *Searcher) writeShards() []*Searcher {
+	if s.shards == nil {
+		return []*Searcher{s}
+	}
+	return s.shards
+}
+
+// readShards returns the versions of the shards of a published version, or the
+// version itself when it has no shards.
+func (s *Searcher) readShards() []*Searcher {
+	if s.shardVersions == nil {
+		return []*Searcher{s}
+	}
+	return s.shardVersions
+}
+
+// contains reports whether the searcher holds a document.
+// The caller must hold the write lock.
+func (s *Searcher) contains(id string) bool {
+	_, ok := s.shardFor(id).idIndex.get(id)
+	return ok
+}
+
+// lookup returns the document with an ID in a published version.
+func (s *Searcher) lookup(id string) (Document, bool) {
+	if s.shardVersions != nil {
+		return s.shardVersions[shardIndex(id, len(s.shardVersions))].lookup(id)
+	}
+	pos, ok := s.idIndex.get(id)
+	if !ok {
+		return Document{}, false
+	}
+	return s.documents.get(pos), true
+}
+
+// shardResult holds the matches found in a shard.
+type shardResult struct {
+	// matches holds the lexical matches and hits the vector hits.
+	matches []scoredDocument
+	hits    []scoredDocument
+	// dropped counts the lexical matches left out past the first k.
+	dropped int
+	err     error
+}
+
+// searchShard finds the matches of a shard, see shardMatches.
+func (s *Searcher) searchShard(ctx context.Context, stats corpus, terms []queryTerm, cfg *searchx.SearchConfig, k int) shardResult {
+	var m shardResult
+	if cfg.Vector != nil {
+		if m.hits, m.err = s.vectorHits(ctx, terms, cfg); m.err != nil || cfg.HybridAlpha == nil || len(terms) == 0 {
+			return m
+		}
+	}
+
+	if m.matches, m.err = s.lexicalMatches(ctx, stats, terms, cfg); m.err != nil {
+		return m
+	}
+	if k >= 0 && cfg.Vector == nil && len(m.matches) > k {
+		m.dropped = len(m.matches) - k
+		m.matches = s.topMatches(m.matches, cfg.Sort, k)
+	}
+	return m
+}
+
+// shardMatches returns the matches of the query terms and vector query of cfg
+// in every shard, searched in parallel. When k is not negative, lexical
+// matches are selected per shard with topMatches, keeping the first k of each
+// shard and counting the others in dropped. The first shard to fail cancels the
+// others through the context.
+func (s *Searcher) shardMatches(ctx context.Context, terms []queryTerm, cfg *searchx.SearchConfig, k int) ([]scoredDocument, int, error) {
+	shards := s.readShards()
+	stats := s.corpus()
+	found := make([]shardResult, len(shards))
+	if len(shards) == 1 {
+		found[0] = shards[0].searchShard(ctx, stats, terms, cfg, k)
+	} else {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for i, shard := range shards {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if found[i] = shard.searchShard(ctx, stats, terms, cfg, k); found[i].err != nil {
+					cancel()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	var matches, hits []scoredDocument
+	dropped := 0
+	for _, m := range found {
+		if m.err != nil {
+			return nil, 0, m.err
+		}
+		matches = append(matches, m.matches...)
+		hits = append(hits, m.hits...)
+		dropped += m.dropped
+	}
+
+	if cfg.Vector != nil {
+		return vectorMatches(hits, matches, cfg), 0, nil
+	}
+	if k >= 0 && len(matches) > k {
+		dropped += len(matches) - k
+		matches = s.topMatches(matches, cfg.Sort, k)
+	}
+	return matches, dropped, nil
+}
@@ -0,0 +1,420 @@
+package inmemory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// shardedPair returns a searcher with a single index and one with shards,
+// holding the same documents and rules.
+func shardedPair(n int) (*Searcher, *Searcher) {
+	colors := []string{"red", "blue", "green"}
+	docs := make([]Document, n)
+	for i := range docs {
+		docs[i] = Document{ID: strconv.Itoa(i), Fields: map[string]interface{}{
+			"title":     fmt.Sprintf("%s car %d", colors[i%3], i%11),
+			"brand":     fmt.Sprint("brand", i%5),
+			"price":     i,
+			"embedding": []float32{1, float32(i) / 10},
+		}}
+	}
+
+	var pair [2]*Searcher
+	for i, opts := range [][]Option{nil, {WithShards(4)}} {
+		s := New(opts...)
+		s.AddDocuments(docs)
+		s.RemoveDocuments([]string{"5", "6", "7"})
+		s.SaveRules(
+			searchx.PinRule("pin", "pinned", "100", "11"),
+			searchx.HideRule("hide", "pinned", "0", "3"),
+		)
+		pair[i] = s
+	}
+	return pair[0], pair[1]
+}
+
+func TestShardedSearch(t *testing.T) {
+	single, sharded := shardedPair(500)
+
+	// Matches with the same relevance are merged in shard order, so results
+	// ranked by relevance are compared as sets, and results ranked otherwise
+	// in order, see TestShardedRelevance
+	tests := map[string]struct {
+		query   string
+		opts    []searchx.SearchOption
+		ordered bool
+	}{
+		"relevance":       {query: "red", opts: []searchx.SearchOption{searchx.WithLimit(500)}},
+		"sorted_page":     {query: "red", opts: []searchx.SearchOption{searchx.WithSort("price", true), searchx.WithOffset(10), searchx.WithLimit(20)}, ordered: true},
+		"filtered":        {opts: []searchx.SearchOption{searchx.Gte("price", 400), searchx.WithSort("price", false), searchx.WithLimit(15)}, ordered: true},
+		"aggregations":    {query: "car", opts: []searchx.SearchOption{searchx.WithStats("price"), searchx.WithLimit(500)}},
+		"distinct":        {query: "blue", opts: []searchx.SearchOption{searchx.WithDistinct("brand", 2), searchx.WithSort("price", false)}, ordered: true},
+		"rules":           {query: "pinned car", opts: []searchx.SearchOption{searchx.WithSort("price", false), searchx.WithLimit(5)}, ordered: true},
+		"vector":          {opts: []searchx.SearchOption{searchx.WithVector("embedding", []float32{1, 0}, 25)}, ordered: true},
+		"filtered_vector": {opts: []searchx.SearchOption{searchx.WithVector("embedding", []float32{1, 0}, 10), searchx.Eq("brand", "brand2")}, ordered: true},
+		"typos":           {query: "grene", opts: []searchx.SearchOption{searchx.WithLimit(500)}},
+		"misspelled":      {query: "grene", opts: []searchx.SearchOption{searchx.WithTypoTolerance(false)}},
+		"no_matches":      {query: "truck", opts: []searchx.SearchOption{searchx.WithSort("price", false)}},
+	}
+
+	ctx := context.Background()
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			expected, err := single.Search(ctx, tc.query, tc.opts...)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			got, err := sharded.Search(ctx, tc.query, tc.opts...)
+			if err != nil {
+				t.Fatalf("Sharded search failed: %v", err)
+			}
+
+			want, have := resultIDs(expected), resultIDs(got)
+			if !tc.ordered {
+				want, have = sortedResultIDs(expected), sortedResultIDs(got)
+			}
+			if have != want {
+				t.Errorf("Expected %s, got %s", want, have)
+			}
+			if !reflect.DeepEqual(got.Aggregations, expected.Aggregations) {
+				t.Errorf("Expected aggregations %v, got %v", expected.Aggregations, got.Aggregations)
+			}
+			if !reflect.DeepEqual(got.GroupCounts, expected.GroupCounts) {
+				t.Errorf("Expected group counts %v, got %v", expected.GroupCounts, got.GroupCounts)
+			}
+			if !slices.Equal(got.Suggestions, expected.Suggestions) {
+				t.Errorf("Expected suggestions %v, got %v", expected.Suggestions, got.Suggestions)
+			}
+		})
+	}
+
+	t.Run("suggest", func(t *testing.T) {
+		expected, _ := single.Suggest(ctx, "gr")
+		got, err := sharded.Suggest(ctx, "gr")
+		if err != nil {
+			t.Fatalf("Suggest failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+}
+
+func TestShardedRelevance(t *testing.T) {
+	words := []string{"red", "blue", "green", "sedan", "coupe", "wagon", "hybrid"}
+	docs := make([]Document, 300)
+	for i := range docs {
+		// Vary term frequencies and field lengths, so that scores depend on
+		// the statistics of every document
+		title := make([]string, 0, i%9+1)
+		for j := 0; j <= i%9; j++ {
+			title = append(title, words[(i*j+i/7)%len(words)])
+		}
+		docs[i] = Document{ID: strconv.Itoa(i), Fields: map[string]interface{}{
+			"title": strings.Join(title, " "),
+			"brand": fmt.Sprint("brand", i%13),
+		}}
+	}
+
+	searchers := make(map[int]*Searcher)
+	for _, n := range []int{1, 2, 4, 8} {
+		searchers[n] = New(WithShards(n))
+		searchers[n].AddDocuments(docs)
+		searchers[n].RemoveDocuments([]string{"10", "20", "30"})
+	}
+
+	// Ties are merged in shard order, so matches are ranked by score then ID.
+	// Scores are rounded, fields being summed in map order.
+	ranking := func(results *searchx.Results) []string {
+		items := slices.Clone(results.Items)
+		for i := range items {
+			items[i].Score = math.Round(items[i].Score*1e9) / 1e9
+		}
+		slices.SortStableFunc(items, func(a, b searchx.Result) int {
+			if a.Score != b.Score {
+				return cmp.Compare(b.Score, a.Score)
+			}
+			return strings.Compare(a.ID, b.ID)
+		})
+		ranked := make([]string, len(items))
+		for i, item := range items {
+			ranked[i] = fmt.Sprintf("%s:%g", item.ID, item.Score)
+		}
+		return ranked
+	}
+
+	ctx := context.Background()
+	for _, query := range []string{"red", "green sedan", "hybrid wagon brand3", "\"blue coupe\"", "sedn"} {
+		expected, err := searchers[1].Search(ctx, query, searchx.WithLimit(300))
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(expected.Items) == 0 {
+			t.Fatalf("Expected matches for %q", query)
+		}
+		for _, n := range []int{2, 4, 8} {
+			t.Run(fmt.Sprintf("%s/%d_shards", query, n), func(t *testing.T) {
+				got, err := searchers[n].Search(ctx, query, searchx.WithLimit(300))
+				if err != nil {
+					t.Fatalf("Search failed: %v", err)
+				}
+				if want, have := ranking(expected), ranking(got); !slices.Equal(have, want) {
+					t.Errorf("Expected %v, got %v", want, have)
+				}
+			})
+		}
+	}
+}
+
+func TestShardedChanges(t *testing.T) {
+	_, searcher := shardedPair(500)
+	if searcher.Size() != 497 {
+		t.Fatalf("Expected 497 documents, got %d", searcher.Size())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := searcher.Watch(ctx, "truck")
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	view := searcher.View()
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"title": "truck", "price": 1}})
+	if !searcher.RemoveDocument("2") || searcher.RemoveDocument("2") {
+		t.Error("Expected document 2 to be removed once")
+	}
+	if view.Size() != 497 || searcher.Size() != 496 {
+		t.Errorf("Expected sizes 497 and 496, got %d and %d", view.Size(), searcher.Size())
+	}
+
+	select {
+	case event := <-events:
+		if event.Type != EventMatch || event.Document.ID != "1" {
+			t.Errorf("Expected document 1 to match, got %s %s", event.Type, event.Document.ID)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected an event for document 1")
+	}
+
+	var snapshot bytes.Buffer
+	if err := searcher.Snapshot(&snapshot); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	loaded, err := Load(&snapshot, WithShards(3))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	for _, query := range []string{"truck", "red", ""} {
+		expected, _ := searcher.Search(context.Background(), query, searchx.WithLimit(500))
+		got, _ := loaded.Search(context.Background(), query, searchx.WithLimit(500))
+		if sortedResultIDs(got) != sortedResultIDs(expected) {
+			t.Errorf("Query %q: expected %s, got %s", query, sortedResultIDs(expected), sortedResultIDs(got))
+		}
+	}
+
+	searcher.Clear()
+	if searcher.Size() != 0 {
+		t.Errorf("Expected no documents after Clear, got %d", searcher.Size())
+	}
+}
+
+func TestShardedSearchCanceled(t *testing.T) {
+	_, searcher := shardedPair(100)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, opts := range [][]searchx.SearchOption{
+		nil,
+		{searchx.WithVector("embedding", []float32{1, 0}, 5)},
+	} {
+		if _, err := searcher.Search(ctx, "car", opts...); !errors.Is(err, searchx.ErrCanceled) {
+			t.Errorf("Expected ErrCanceled, got %v", err)
+		}
+	}
+}
+
+// idsInShards returns an ID in each of two different shards among n.
+func idsInShards(n int) (string, string) {
+	first := "0"
+	for i := 1; ; i++ {
+		if id := strconv.Itoa(i); shardIndex(id, n) != shardIndex(first, n) {
+			return first, id
+		}
+	}
+}
+
+func TestShardWriteLocks(t *testing.T) {
+	var wal bytes.Buffer
+	searcher := New(WithShards(4), WithWAL(&wal))
+	locked, other := idsInShards(4)
+	searcher.AddDocument(Document{ID: locked, Fields: map[string]interface{}{"title": "red car"}})
+
+	// A change to a shard in progress does not block those to other shards
+	shard := searcher.lockShard(locked)
+	searcher.logChange(walEntry{Op: walAdd, ID: locked, Fields: map[string]interface{}{"title": "blue car"}})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		searcher.AddDocument(Document{ID: other, Fields: map[string]interface{}{"title": "green car"}})
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a change to another shard to complete")
+	}
+
+	// Snapshots taken meanwhile stop before the change in progress, which
+	// replaying the log applies along with the changes after it
+	var snapshot bytes.Buffer
+	if err := searcher.Snapshot(&snapshot); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if sequence := searcher.current().sequence; sequence != 1 {
+		t.Errorf("Expected the published changes to stop at 1, got %d", sequence)
+	}
+	shard.addDocument(Document{ID: locked, Fields: map[string]interface{}{"title": "blue car"}})
+	searcher.publishShard(shard)
+	shard.mu.Unlock()
+	if sequence := searcher.current().sequence; sequence != 3 {
+		t.Errorf("Expected the published changes to reach 3, got %d", sequence)
+	}
+
+	restored, err := Load(&snapshot, WithShards(4))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := restored.ReplayWAL(&wal); err != nil {
+		t.Fatalf("ReplayWAL failed: %v", err)
+	}
+	for _, query := range []string{"blue", "green", "red"} {
+		expected, _ := searcher.Search(context.Background(), query)
+		got, _ := restored.Search(context.Background(), query)
+		if resultIDs(got) != resultIDs(expected) {
+			t.Errorf("Query %q: expected %s, got %s", query, resultIDs(expected), resultIDs(got))
+		}
+	}
+}
+
+func TestShardedConcurrentChanges(t *testing.T) {
+	var wal bytes.Buffer
+	searcher := New(WithShards(4), WithWAL(&wal))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := searcher.Watch(ctx, "car")
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	// The events up to that of a last document describe the documents matching
+	received := make(chan []string)
+	go func() {
+		matching := make(map[string]bool)
+		for event := range events {
+			if event.Document.ID == "last" {
+				break
+			}
+			matching[event.Document.ID] = event.Type == EventMatch
+		}
+		var ids []string
+		for id, matches := range matching {
+			if matches {
+				ids = append(ids, id)
+			}
+		}
+		slices.Sort(ids)
+		received <- ids
+	}()
+
+	// Writers change their own documents one at a time and in batches while
+	// searches run
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				id := fmt.Sprintf("%d-%d", w, i%20)
+				if i%3 == 2 {
+					searcher.RemoveDocument(id)
+				} else {
+					searcher.AddDocument(Document{ID: id, Fields: map[string]interface{}{"title": "car", "price": i}})
+				}
+			}
+			searcher.AddDocuments([]Document{{ID: fmt.Sprint("batch-", w), Fields: map[string]interface{}{"title": "car"}}})
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if _, err := searcher.Search(ctx, "car", searchx.WithSort("price", true)); err != nil {
+					t.Errorf("Search failed: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	searcher.AddDocument(Document{ID: "last", Fields: map[string]interface{}{"title": "car"}})
+	searcher.RemoveDocument("last")
+
+	replayed := New(WithShards(2))
+	if err := replayed.ReplayWAL(&wal); err != nil {
+		t.Fatalf("ReplayWAL failed: %v", err)
+	}
+	expected, _ := searcher.Search(ctx, "car", searchx.WithLimit(200))
+	got, _ := replayed.Search(ctx, "car", searchx.WithLimit(200))
+	if sortedResultIDs(got) != sortedResultIDs(expected) {
+		t.Errorf("Expected the log to replay to %s, got %s", sortedResultIDs(expected), sortedResultIDs(got))
+	}
+
+	select {
+	case ids := <-received:
+		if got := fmt.Sprint(len(ids), ids); got != sortedResultIDs(expected) {
+			t.Errorf("Expected events for %s, got %s", sortedResultIDs(expected), got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an event for the last document")
+	}
+}
+
+// sortedResultIDs formats the total and the IDs of the results sorted.
+func sortedResultIDs(results *searchx.Results) string {
+	ids := make([]string, len(results.Items))
+	for i, item := range results.Items {
+		ids[i] = item.ID
+	}
+	slices.Sort(ids)
+	return fmt.Sprint(results.Total, ids)
+}
+
+func BenchmarkSearchShards(b *testing.B) {
+	docs := make([]Document, 100000)
+	for i := range docs {
+		docs[i] = Document{ID: fmt.Sprint(i), Fields: map[string]interface{}{
+			"title": fmt.Sprintf("used sedan %d", i%100),
+			"price": float64((i * 7919) % 50000),
+		}}
+	}
+
+	ctx := context.Background()
+	for _, n := range []int{1, 4, 16} {
+		searcher := New(WithShards(n))
+		searcher.AddDocuments(docs)
+		b.Run(fmt.Sprint("shards_", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = searcher.Search(ctx, "sedan", searchx.WithSort("price", true), searchx.WithLimit(20))
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package inmemory
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/cockroachdb/errors"
+)
+
+// snapshotVersion is the version of the snapshot format written by Snapshot.
+const snapshotVersion = 1
+
+// snapshotHeader is the first line of a snapshot.
+type snapshotHeader struct {
+	Version int `json:"version"`
+	// Sequence is the number of the last change contained in the snapshot.
+	Sequence uint64 `json:"seq"`
+	// Documents is the number of documents following the header.
+	Documents int `json:"documents"`
+}
+
+// snapshotDocument is a document of a snapshot, one per line after the header.
+type snapshotDocument struct {
+	ID     string                 `json:"id"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// Snapshot writes the documents of the searcher to w, in insertion order within
+// each shard, as lines of JSON. Load restores a searcher from a snapshot.
+//
+// Only documents are written: synonyms, rules and options are configuration,
+// set again when loading. Field values are written as JSON, so they are loaded
+// back as JSON values, numbers becoming float64 and times RFC 3339 strings,
+// like documents added with AddJSON, except integers beyond 2^53, which keep
+// their digits as int64 or uint64. The documents are those of the last change
+// published, changes made while writing being left to the write-ahead log. With
+// shards, a snapshot may also hold changes published after one still in
+// progress in another shard, which replaying the log applies again to the same
+// effect.
+// This method is safe for concurrent use.
+func (s *Searcher) Snapshot(w io.Writer) error {
+	version := s.current()
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+
+	header := snapshotHeader{Version: snapshotVersion, Sequence: version.sequence, Documents: version.size()}
+	if err := encoder.Encode(header); err != nil {
+		return errors.Wrap(err, "failed to write snapshot header")
+	}
+	for _, shard := range version.readShards() {
+		for pos, doc := range shard.documents.all() {
+			if shard.tombstones.get(pos) {
+				continue
+			}
+			if err := encoder.Encode(snapshotDocument{ID: doc.ID, Fields: doc.Fields}); err != nil {
+				return errors.Wrapf(err, "failed to write document %s", doc.ID)
+			}
+		}
+	}
+
+	if err := buf.Flush(); err != nil {
+		return errors.Wrap(err, "failed to write snapshot")
+	}
+	return nil
+}
+
+// Load creates a searcher configured with the given options and restores the
+// documents of a snapshot written by Snapshot. Changes logged since the
+// snapshot was taken can then be applied with ReplayWAL.
+func Load(r io.Reader, opts ...Option) (*Searcher, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	decoder.UseNumber()
+
+	var header snapshotHeader
+	if err := decoder.Decode(&header); err != nil {
+		return nil, errors.Wrap(err, "failed to read snapshot header")
+	}
+	if header.Version != snapshotVersion {
+		return nil, errors.Newf("unsupported snapshot version %d", header.Version)
+	}
+
+	s := New(opts...)
+	for i := 0; i < header.Documents; i++ {
+		var doc snapshotDocument
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, errors.Wrapf(err, "failed to read document %d of %d", i+1, header.Documents)
+		}
+		for name, value := range doc.Fields {
+			doc.Fields[name] = floatNumbers(value)
+		}
+		s.addDocument(Document{ID: doc.ID, Fields: doc.Fields})
+	}
+	s.sequence = header.Sequence
+	s.publish()
+	return s, nil
+}
@@ -0,0 +1,66 @@
+package inmemory
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotLoad(t *testing.T) {
+	original := New()
+	original.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"make": "Toyota", "year": 2018, "serial": int64(9007199254740993)}})
+	original.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"make": "Ford", "tags": []interface{}{"red"}}})
+	original.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"make": "Honda"}})
+	original.RemoveDocument("2")
+
+	var snapshot bytes.Buffer
+	if err := original.Snapshot(&snapshot); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	loaded, err := Load(&snapshot)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if loaded.Size() != 2 || loaded.sequence != original.sequence {
+		t.Fatalf("Expected 2 documents up to change %d, got %d up to %d", original.sequence, loaded.Size(), loaded.sequence)
+	}
+	for i, id := range []string{"1", "3"} {
+		if loaded.documents.get(i).ID != id {
+			t.Errorf("At index %d: expected ID %s, got %s", i, id, loaded.documents.get(i).ID)
+		}
+	}
+	if year := loaded.documents.get(0).Fields["year"]; year != float64(2018) {
+		t.Errorf("Expected year to be loaded as float64 2018, got %v (%T)", year, year)
+	}
+	if serial := loaded.documents.get(0).Fields["serial"]; serial != int64(9007199254740993) {
+		t.Errorf("Expected serial to keep its digits, got %v (%T)", serial, serial)
+	}
+
+	results, err := loaded.Search(context.Background(), "toyota")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results.Items) != 1 || results.Items[0].ID != "1" {
+		t.Errorf("Expected the loaded documents to be indexed, got %v", results.Items)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty":             "",
+		"unknown_version":   `{"version":99,"seq":0,"documents":0}`,
+		"missing_documents": `{"version":1,"seq":2,"documents":2}` + "\n" + `{"id":"1","fields":{}}`,
+		"corrupt_document":  `{"version":1,"seq":1,"documents":1}` + "\n" + `{"id":`,
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(strings.NewReader(data)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// Store holds named indexes, the way an Algolia application does, so that a
+// single process can stand in for the whole application in integration tests
+// and local development. The index names are those of Algolia, which are the
+// sort keys of the DynamoDB records synced to it.
+type Store struct {
+	mu      sync.RWMutex
+	indexes map[string]*Searcher
+
+	// opts configure every index, and indexOpts the named ones after opts.
+	opts      []Option
+	indexOpts map[string][]Option
+}
+
+// StoreOption configures a Store.
+type StoreOption func(*Store)
+
+// WithIndexOptions sets options for every index of the store.
+func WithIndexOptions(opts ...Option) StoreOption {
+	return func(s *Store) {
+		s.opts = append(s.opts, opts...)
+	}
+}
+
+// WithIndex sets options for the index with the given name, applied after
+// those set with WithIndexOptions. Options writing to an io.Writer, such as
+// WithWAL, must be given per index.
+func WithIndex(name string, opts ...Option) StoreOption {
+	return func(s *Store) {
+		s.indexOpts[name] = append(s.indexOpts[name], opts...)
+	}
+}
+
+// NewStore creates a store without indexes configured with the given options.
+// The store is safe for concurrent use.
+func NewStore(opts ...StoreOption) *Store {
+	s := &Store{
+		indexes:   make(map[string]*Searcher),
+		indexOpts: make(map[string][]Option),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Index returns the index with the given name, creating it empty on first use
+// like Algolia's InitIndex. This method is safe for concurrent use.
+func (s *Store) Index(name string) *Searcher {
+	s.mu.RLock()
+	index, ok := s.indexes[name]
+	s.mu.RUnlock()
+	if ok {
+		return index
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index, ok := s.indexes[name]; ok {
+		return index
+	}
+	opts := append(append([]Option(nil), s.opts...), s.indexOpts[name]...)
+	index = New(opts...)
+	s.indexes[name] = index
+	return index
+}
+
+// Indexes returns the names of the indexes in the store, sorted.
+// This method is safe for concurrent use.
+func (s *Store) Indexes() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.indexes))
+	for name := range s.indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DeleteIndex removes an index and its documents from the store. Searchers
+// previously returned by Index keep working but are no longer part of it.
+// It returns false when there is no such index.
+// This method is safe for concurrent use.
+func (s *Store) DeleteIndex(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.indexes[name]; !ok {
+		return false
+	}
+	delete(s.indexes, name)
+	return true
+}
+
+// SaveObject adds an object to an index, replacing the object with the same
+// objectID, like algolia.Client.SaveObject. The objectID becomes the ID of the
+// document and is not kept among its fields.
+// This method is safe for concurrent use.
+func (s *Store) SaveObject(ctx context.Context, indexName string, object map[string]interface{}) error {
+	doc, err := objectDocument(object)
+	if err != nil {
+		return err
+	}
+	s.Index(indexName).AddDocument(doc)
+	return nil
+}
+
+// DeleteObject removes an object from an index, like algolia.Client.DeleteObject.
+// Deleting a missing object is not an error.
+// This method is safe for concurrent use.
+func (s *Store) DeleteObject(ctx context.Context, indexName string, objectID string) error {
+	s.Index(indexName).RemoveDocument(objectID)
+	return nil
+}
+
+// BatchSaveObjects adds objects to an index under a single lock, like
+// algolia.Client.BatchSaveObjects. Nothing is saved when an object lacks its
+// objectID. This method is safe for concurrent use.
+func (s *Store) BatchSaveObjects(ctx context.Context, indexName string, objects []map[string]interface{}) error {
+	docs := make([]Document, len(objects))
+	for i, object := range objects {
+		doc, err := objectDocument(object)
+		if err != nil {
+			return errors.Wrapf(err, "object %d", i)
+		}
+		docs[i] = doc
+	}
+	s.Index(indexName).AddDocuments(docs)
+	return nil
+}
+
+// BatchDeleteObjects removes objects from an index under a single lock, like
+// algolia.Client.BatchDeleteObjects. This method is safe for concurrent use.
+func (s *Store) BatchDeleteObjects(ctx context.Context, indexName string, objectIDs []string) error {
+	s.Index(indexName).RemoveDocuments(objectIDs)
+	return nil
+}
+
+// objectDocument converts an Algolia object into a document.
+func objectDocument(object map[string]interface{}) (Document, error) {
+	id, _ := object["objectID"].(string)
+	if id == "" {
+		return Document{}, errors.Wrap(searchx.ErrInvalidOption, "object has no objectID")
+	}
+
+	fields := make(map[string]interface{}, len(object)-1)
+	for field, value := range object {
+		if field != "objectID" {
+			fields[field] = value
+		}
+	}
+	return Document{ID: id, Fields: fields}, nil
+}
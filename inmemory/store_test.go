@@ -0,0 +1,118 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore(
+		WithIndexOptions(WithBM25(1.5, 0.5)),
+		WithIndex("cars", WithSchema(searchx.NewSchema(
+			searchx.Field{Name: "make", Type: searchx.FieldString, Searchable: true, Filterable: true},
+		))),
+	)
+	ctx := context.Background()
+
+	if err := store.SaveObject(ctx, "cars", map[string]interface{}{"objectID": "1", "make": "Toyota"}); err != nil {
+		t.Fatalf("SaveObject failed: %v", err)
+	}
+	if err := store.BatchSaveObjects(ctx, "trucks", []map[string]interface{}{
+		{"objectID": "1", "make": "Ford"},
+		{"objectID": "2", "make": "Toyota"},
+	}); err != nil {
+		t.Fatalf("BatchSaveObjects failed: %v", err)
+	}
+
+	if store.Index("cars") != store.Index("cars") {
+		t.Error("Expected Index to return the same searcher")
+	}
+	if got := fmt.Sprint(store.Indexes()); got != "[cars trucks]" {
+		t.Errorf("Expected indexes [cars trucks], got %s", got)
+	}
+
+	// Options apply per index
+	cars, trucks := store.Index("cars"), store.Index("trucks")
+	if cars.k1 != 1.5 || trucks.k1 != 1.5 {
+		t.Errorf("Expected the store options on every index, got k1 %v and %v", cars.k1, trucks.k1)
+	}
+	if cars.schema == nil || trucks.schema != nil {
+		t.Error("Expected the schema on the cars index only")
+	}
+
+	tests := map[string]struct {
+		index    string
+		query    string
+		expected string
+	}{
+		"cars":        {index: "cars", query: "toyota", expected: "[1]"},
+		"trucks":      {index: "trucks", query: "toyota", expected: "[2]"},
+		"no_match":    {index: "cars", query: "ford", expected: "[]"},
+		"new_index":   {index: "vans", query: "", expected: "[]"},
+		"id_not_kept": {index: "trucks", query: "2", expected: "[]"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := store.Index(tc.index).Search(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			ids := []string{}
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+			}
+			if fmt.Sprint(ids) != tc.expected {
+				t.Errorf("Expected %s, got %v", tc.expected, ids)
+			}
+		})
+	}
+
+	if err := store.DeleteObject(ctx, "cars", "1"); err != nil || cars.Size() != 0 {
+		t.Errorf("Expected DeleteObject to empty cars, got size %d and %v", cars.Size(), err)
+	}
+	if err := store.BatchDeleteObjects(ctx, "trucks", []string{"1", "2", "3"}); err != nil || trucks.Size() != 0 {
+		t.Errorf("Expected BatchDeleteObjects to empty trucks, got size %d and %v", trucks.Size(), err)
+	}
+	if !store.DeleteIndex("cars") || store.DeleteIndex("cars") {
+		t.Error("Expected DeleteIndex to remove cars once")
+	}
+	if got := fmt.Sprint(store.Indexes()); got != "[trucks vans]" {
+		t.Errorf("Expected indexes [trucks vans], got %s", got)
+	}
+}
+
+func TestStoreObjectErrors(t *testing.T) {
+	store := NewStore()
+	ctx := context.Background()
+
+	tests := map[string]func() error{
+		"missing_id": func() error {
+			return store.SaveObject(ctx, "cars", map[string]interface{}{"make": "Toyota"})
+		},
+		"numeric_id": func() error {
+			return store.SaveObject(ctx, "cars", map[string]interface{}{"objectID": 1})
+		},
+		"batch_missing_id": func() error {
+			return store.BatchSaveObjects(ctx, "cars", []map[string]interface{}{
+				{"objectID": "1"},
+				{"make": "Toyota"},
+			})
+		},
+	}
+
+	for name, save := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := save(); !errors.Is(err, searchx.ErrInvalidOption) {
+				t.Errorf("Expected ErrInvalidOption, got %v", err)
+			}
+			if size := store.Index("cars").Size(); size != 0 {
+				t.Errorf("Expected nothing saved, got %d documents", size)
+			}
+		})
+	}
+}
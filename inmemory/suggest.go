@@ -0,0 +1,125 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/letmevibethatforyou/searchx"
+	"github.com/letmevibethatforyou/searchx/analysis"
+)
+
+// Suggest implements the searchx.Suggester interface.
+// It completes the last word of prefix, analyzed with the default analyzer, from
+// the terms of the searchable fields of indexed documents, see documentTerms,
+// keeping the words typed before it. Frequencies count the documents containing
+// the completed word. With filters, only the words of the documents passing
+// them are completed, like the spelling corrections of didYouMean, which
+// analyzes the documents again.
+// This method is safe for concurrent use.
+func (s *Searcher) Suggest(ctx context.Context, prefix string, opts ...searchx.SuggestOption) ([]searchx.Suggestion, error) {
+	// Check context
+	select {
+	case <-ctx.Done():
+		return nil, searchx.ErrCanceled
+	default:
+	}
+
+	// Parse options
+	cfg := &searchx.SuggestConfig{}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+
+	// Set defaults
+	if cfg.Limit <= 0 {
+		cfg.Limit = 10
+	}
+
+	version := s.current()
+	filters := cfg.Filters
+	if version.schema != nil {
+		var err error
+		if filters, err = version.schema.CoerceFilters(filters); err != nil {
+			return nil, err
+		}
+	}
+	filters = searchx.ResolveTimes(filters, time.Now())
+
+	suggestions := make([]searchx.Suggestion, 0, cfg.Limit)
+
+	tokens := s.analyzer.Analyze(prefix)
+	if len(tokens) == 0 {
+		return suggestions, nil
+	}
+
+	// Words typed before the one being completed are kept as-is
+	lead := strings.Join(analysis.Terms(tokens[:len(tokens)-1]), " ")
+	if lead != "" {
+		lead += " "
+	}
+
+	last := tokens[len(tokens)-1].Term
+	found := make(map[string]int)
+	suggest := func(term string, freq int) {
+		if freq == 0 {
+			return
+		}
+		if i, ok := found[term]; ok {
+			suggestions[i].Frequency += int64(freq)
+			return
+		}
+		found[term] = len(suggestions)
+		suggestions = append(suggestions, searchx.Suggestion{
+			Text:      lead + term,
+			Frequency: int64(freq),
+		})
+	}
+
+	if len(filters) > 0 {
+		for term, freq := range version.filteredVocabulary(filters, cfg.Fields) {
+			if strings.HasPrefix(term, last) {
+				suggest(term, freq)
+			}
+		}
+	} else {
+		// Shards have their own vocabularies, whose frequencies add up
+		for _, shard := range version.readShards() {
+			if node := shard.vocabulary.find(last); node != nil {
+				node.walk(last, func(term string, n *trieNode) {
+					suggest(term, n.frequency(cfg.Fields))
+				})
+			}
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Frequency != suggestions[j].Frequency {
+			return suggestions[i].Frequency > suggestions[j].Frequency
+		}
+		return suggestions[i].Text < suggestions[j].Text
+	})
+
+	if len(suggestions) > cfg.Limit {
+		suggestions = suggestions[:cfg.Limit]
+	}
+
+	return suggestions, nil
+}
+
+// indexVocabulary adds the terms of a document to the vocabulary.
+// The caller must hold the write lock.
+func (s *Searcher) indexVocabulary(doc Document) {
+	for term, fields := range s.documentTerms(doc) {
+		s.vocabulary.add(s.gen, term, fields)
+	}
+}
+
+// unindexVocabulary removes the terms of a document from the vocabulary.
+// The caller must hold the write lock.
+func (s *Searcher) unindexVocabulary(doc Document) {
+	for term, fields := range s.documentTerms(doc) {
+		s.vocabulary.remove(s.gen, term, fields)
+	}
+}
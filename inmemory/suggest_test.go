@@ -0,0 +1,213 @@
+package inmemory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+	"github.com/letmevibethatforyou/searchx/analysis"
+)
+
+func TestSuggest(t *testing.T) {
+	searcher := New()
+
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"make": "Toyota", "model": "Camry", "year": 2020}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"make": "Toyota", "model": "Corolla", "year": 2021}})
+	searcher.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"make": "Tesla", "model": "Model 3", "year": 2020}})
+	searcher.AddDocument(Document{ID: "4", Fields: map[string]interface{}{"make": "Citroën", "model": "C4", "electric": true}})
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		prefix   string
+		opts     []searchx.SuggestOption
+		expected []searchx.Suggestion
+	}{
+		"most_frequent_first": {
+			prefix:   "t",
+			expected: []searchx.Suggestion{{Text: "toyota", Frequency: 2}, {Text: "tesla", Frequency: 1}},
+		},
+		"case_insensitive": {
+			prefix:   "CO",
+			expected: []searchx.Suggestion{{Text: "corolla", Frequency: 1}},
+		},
+		"diacritics_folded": {
+			prefix:   "citroe",
+			expected: []searchx.Suggestion{{Text: "citroen", Frequency: 1}},
+		},
+		"numbers": {
+			prefix:   "202",
+			expected: []searchx.Suggestion{{Text: "2020", Frequency: 2}, {Text: "2021", Frequency: 1}},
+		},
+		"completes_last_word": {
+			prefix:   "toyota ca",
+			expected: []searchx.Suggestion{{Text: "toyota camry", Frequency: 1}},
+		},
+		"limit": {
+			prefix:   "t",
+			opts:     []searchx.SuggestOption{searchx.WithSuggestLimit(1)},
+			expected: []searchx.Suggestion{{Text: "toyota", Frequency: 2}},
+		},
+		"restricted_to_fields": {
+			prefix:   "c",
+			opts:     []searchx.SuggestOption{searchx.WithSuggestFields("make")},
+			expected: []searchx.Suggestion{{Text: "citroen", Frequency: 1}},
+		},
+		"filtered": {
+			prefix:   "t",
+			opts:     []searchx.SuggestOption{searchx.WithSuggestFilters(searchx.Eq("year", 2020))},
+			expected: []searchx.Suggestion{{Text: "tesla", Frequency: 1}, {Text: "toyota", Frequency: 1}},
+		},
+		"filtered_and_restricted_to_fields": {
+			prefix:   "c",
+			opts:     []searchx.SuggestOption{searchx.WithSuggestFilters(searchx.Gt("year", 2020)), searchx.WithSuggestFields("model")},
+			expected: []searchx.Suggestion{{Text: "corolla", Frequency: 1}},
+		},
+		"booleans_not_suggested": {
+			prefix:   "tr",
+			expected: []searchx.Suggestion{},
+		},
+		"no_match": {
+			prefix:   "xyz",
+			expected: []searchx.Suggestion{},
+		},
+		"empty_prefix": {
+			prefix:   "  ",
+			expected: []searchx.Suggestion{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			suggestions, err := searcher.Suggest(ctx, tc.prefix, tc.opts...)
+			if err != nil {
+				t.Fatalf("Suggest failed: %v", err)
+			}
+			if !reflect.DeepEqual(suggestions, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, suggestions)
+			}
+		})
+	}
+}
+
+func TestSuggestAnalysis(t *testing.T) {
+	schema := searchx.NewSchema(
+		searchx.Field{Name: "title", Type: searchx.FieldString, Searchable: true, Filterable: true},
+		searchx.Field{Name: "description", Type: searchx.FieldString, Searchable: true},
+		searchx.Field{Name: "vin", Type: searchx.FieldString, Searchable: true},
+		searchx.Field{Name: "notes", Type: searchx.FieldString},
+	)
+	searcher := New(
+		WithSchema(schema),
+		WithFieldAnalyzer("description", analysis.English()),
+		WithFieldAnalyzer("vin", analysis.Keyword()),
+	)
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{
+		"title":       "Towing package",
+		"description": "Towing trucks",
+		"vin":         "JT2BF22K1W0123456",
+		"notes":       "secret discount",
+	}})
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		prefix   string
+		opts     []searchx.SuggestOption
+		expected []searchx.Suggestion
+	}{
+		"field_analyzer": {
+			prefix:   "tow",
+			expected: []searchx.Suggestion{{Text: "tow", Frequency: 1}, {Text: "towing", Frequency: 1}},
+		},
+		"stems": {
+			prefix:   "truc",
+			expected: []searchx.Suggestion{{Text: "truck", Frequency: 1}},
+		},
+		"keyword_field": {
+			prefix:   "jt2",
+			expected: []searchx.Suggestion{{Text: "jt2bf22k1w0123456", Frequency: 1}},
+		},
+		"restricted_to_fields": {
+			prefix:   "tow",
+			opts:     []searchx.SuggestOption{searchx.WithSuggestFields("description")},
+			expected: []searchx.Suggestion{{Text: "tow", Frequency: 1}},
+		},
+		"not_searchable": {
+			prefix:   "sec",
+			expected: []searchx.Suggestion{},
+		},
+		"filtered_not_searchable": {
+			prefix:   "sec",
+			opts:     []searchx.SuggestOption{searchx.WithSuggestFilters(searchx.Exists("title"))},
+			expected: []searchx.Suggestion{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			suggestions, err := searcher.Suggest(ctx, tc.prefix, tc.opts...)
+			if err != nil {
+				t.Fatalf("Suggest failed: %v", err)
+			}
+			if !reflect.DeepEqual(suggestions, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, suggestions)
+			}
+		})
+	}
+}
+
+func TestSuggestFollowsDocumentChanges(t *testing.T) {
+	searcher := New()
+	ctx := context.Background()
+
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"model": "Camry"}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"model": "Camry"}})
+
+	// Updating a document replaces its terms
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"model": "Camaro"}})
+	suggestions, err := searcher.Suggest(ctx, "cam")
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	expected := []searchx.Suggestion{{Text: "camaro", Frequency: 1}, {Text: "camry", Frequency: 1}}
+	if !reflect.DeepEqual(suggestions, expected) {
+		t.Errorf("After update: expected %v, got %v", expected, suggestions)
+	}
+
+	// Removing a document forgets its terms
+	searcher.RemoveDocument("1")
+	suggestions, err = searcher.Suggest(ctx, "cam")
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	expected = []searchx.Suggestion{{Text: "camaro", Frequency: 1}}
+	if !reflect.DeepEqual(suggestions, expected) {
+		t.Errorf("After removal: expected %v, got %v", expected, suggestions)
+	}
+	if node := searcher.vocabulary.find("camr"); node != nil {
+		t.Error("Expected removed terms to be pruned from the vocabulary")
+	}
+
+	// Clearing empties the vocabulary
+	searcher.Clear()
+	suggestions, err = searcher.Suggest(ctx, "cam")
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	if len(suggestions) != 0 {
+		t.Errorf("After clear: expected no suggestions, got %v", suggestions)
+	}
+}
+
+func TestSuggestWithCanceledContext(t *testing.T) {
+	searcher := New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := searcher.Suggest(ctx, "cam"); err != searchx.ErrCanceled {
+		t.Errorf("Expected ErrCanceled, got: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package inmemory
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/letmevibethatforyou/searchx"
+	"github.com/letmevibethatforyou/searchx/analysis"
+)
+
+// queryTerm is a query term along with the alternatives it expands to through synonyms.
+type queryTerm struct {
+	// text is the lowercased term as it appears in the query.
+	text string
+	// alternatives contains text followed by its synonym expansions.
+	alternatives []string
+	// field restricts the term to a top-level field when not empty.
+	field string
+	// occur describes whether the term is optional, required or excluded.
+	occur searchx.Occur
+	// exact is true for quoted phrases, which must match every word exactly.
+	exact bool
+	// phrases contains the analyzed alternatives keyed by analyzer, see analyzerFor.
+	phrases map[string][][]analysis.Token
+}
+
+// synonymDictionary holds the synonyms of a searcher compiled for query expansion.
+type synonymDictionary struct {
+	// synonyms contains the saved synonyms in insertion order.
+	synonyms []searchx.Synonym
+	// expansions maps a lowercased word or phrase to the words it also matches.
+	expansions map[string][]string
+	// maxWords is the number of words in the longest expandable phrase.
+	maxWords int
+}
+
+// SaveSynonyms adds synonyms to the dictionary applied to queries.
+// A synonym with the same ID as an existing one replaces it.
+// This method is safe for concurrent use.
+func (s *Searcher) SaveSynonyms(synonyms ...searchx.Synonym) {
+	s.lock()
+	defer s.unlock()
+
+	// Published versions share the synonyms, compile replaces the expansions
+	s.synonyms.synonyms = slices.Clone(s.synonyms.synonyms)
+	for _, syn := range synonyms {
+		replaced := false
+		if syn.ID != "" {
+			for i, existing := range s.synonyms.synonyms {
+				if existing.ID == syn.ID {
+					s.synonyms.synonyms[i] = syn
+					replaced = true
+					break
+				}
+			}
+		}
+		if !replaced {
+			s.synonyms.synonyms = append(s.synonyms.synonyms, syn)
+		}
+	}
+
+	s.synonyms.compile()
+	s.publish()
+}
+
+// ClearSynonyms removes all synonyms from the dictionary.
+// This method is safe for concurrent use.
+func (s *Searcher) ClearSynonyms() {
+	s.lock()
+	defer s.unlock()
+
+	s.synonyms = synonymDictionary{}
+	s.publish()
+}
+
+// compile rebuilds the expansion table from the saved synonyms.
+func (d *synonymDictionary) compile() {
+	d.expansions = make(map[string][]string)
+	d.maxWords = 0
+
+	add := func(from string, to ...string) {
+		key := normalizePhrase(from)
+		if key == "" {
+			return
+		}
+		for _, alt := range to {
+			alt = normalizePhrase(alt)
+			if alt == "" || alt == key || containsString(d.expansions[key], alt) {
+				continue
+			}
+			d.expansions[key] = append(d.expansions[key], alt)
+		}
+		if n := len(strings.Fields(key)); n > d.maxWords {
+			d.maxWords = n
+		}
+	}
+
+	for _, syn := range d.synonyms {
+		switch syn.Type {
+		case searchx.SynonymTwoWay:
+			for _, word := range syn.Synonyms {
+				add(word, syn.Synonyms...)
+			}
+		case searchx.SynonymOneWay:
+			add(syn.Input, syn.Synonyms...)
+		case searchx.SynonymPlaceholder:
+			for _, replacement := range syn.Synonyms {
+				add(replacement, syn.Placeholder)
+			}
+		}
+	}
+}
+
+// expand groups query words into terms, attaching synonym alternatives.
+// The longest phrase with an expansion wins when phrases overlap.
+func (d *synonymDictionary) expand(words []string) []queryTerm {
+	terms := make([]queryTerm, 0, len(words))
+
+	for i := 0; i < len(words); {
+		matched := false
+		for n := min(d.maxWords, len(words)-i); n >= 1; n-- {
+			phrase := strings.Join(words[i:i+n], " ")
+			if alts, ok := d.expansions[phrase]; ok {
+				terms = append(terms, queryTerm{
+					text:         phrase,
+					alternatives: append([]string{phrase}, alts...),
+				})
+				i += n
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			terms = append(terms, queryTerm{text: words[i], alternatives: []string{words[i]}})
+			i++
+		}
+	}
+
+	return terms
+}
+
+// normalizePhrase lowercases a phrase and collapses its whitespace.
+func normalizePhrase(phrase string) string {
+	return strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+}
+
+// containsString reports whether a slice contains a string.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestSynonymSearch(t *testing.T) {
+	searcher := New()
+
+	searcher.AddDocument(Document{
+		ID:     "1",
+		Fields: map[string]interface{}{"make": "Chevrolet", "model": "Malibu"},
+	})
+	searcher.AddDocument(Document{
+		ID:     "2",
+		Fields: map[string]interface{}{"make": "Volkswagen", "model": "Golf"},
+	})
+	searcher.AddDocument(Document{
+		ID:     "3",
+		Fields: map[string]interface{}{"make": "Mercedes-Benz", "model": "C-Class <trim>"},
+	})
+	searcher.AddDocument(Document{
+		ID:     "4",
+		Fields: map[string]interface{}{"make": "Ford", "model": "F-150", "body": "pickup"},
+	})
+
+	searcher.SaveSynonyms(
+		searchx.TwoWaySynonym("chevy", "chevy", "chevrolet"),
+		searchx.OneWaySynonym("vw", "vw", "volkswagen"),
+		searchx.TwoWaySynonym("merc", "merc", "mercedes benz"),
+		searchx.OneWaySynonym("truck", "truck", "pickup"),
+		searchx.PlaceholderSynonym("trim", "<trim>", "amg", "sport"),
+	)
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		query    string
+		expected []string
+	}{
+		"two_way_from_alias":         {query: "chevy", expected: []string{"1"}},
+		"two_way_from_canonical":     {query: "Chevrolet malibu", expected: []string{"1"}},
+		"one_way_from_input":         {query: "vw", expected: []string{"2"}},
+		"one_way_not_reversed":       {query: "pickup truck", expected: []string{"4"}},
+		"multi_word_phrase":          {query: "mercedes benz", expected: []string{"3"}},
+		"placeholder_replacement":    {query: "amg", expected: []string{"3"}},
+		"no_synonym_no_match":        {query: "corvette", expected: []string{}},
+		"synonym_combined_with_term": {query: "vw golf", expected: []string{"2"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(ctx, tc.query)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			ids := make([]string, 0, len(results.Items))
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+			}
+			sort.Strings(ids)
+
+			if len(ids) != len(tc.expected) {
+				t.Fatalf("Expected %v, got %v", tc.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != tc.expected[i] {
+					t.Errorf("Expected %v, got %v", tc.expected, ids)
+				}
+			}
+		})
+	}
+}
+
+func TestSynonymExpansion(t *testing.T) {
+	searcher := New()
+	searcher.SaveSynonyms(
+		searchx.OneWaySynonym("vw", "vw", "volkswagen"),
+		searchx.TwoWaySynonym("ny", "ny", "new york"),
+	)
+
+	terms := searcher.parseQuery("VW dealers in New  York")
+
+	expected := []queryTerm{
+		{text: "vw", alternatives: []string{"vw", "volkswagen"}},
+		{text: "dealers", alternatives: []string{"dealers"}},
+		{text: "in", alternatives: []string{"in"}},
+		{text: "new york", alternatives: []string{"new york", "ny"}},
+	}
+
+	if len(terms) != len(expected) {
+		t.Fatalf("Expected %d terms, got %d: %+v", len(expected), len(terms), terms)
+	}
+	for i := range expected {
+		if terms[i].text != expected[i].text {
+			t.Errorf("Term %d: expected text %q, got %q", i, expected[i].text, terms[i].text)
+		}
+		if len(terms[i].alternatives) != len(expected[i].alternatives) {
+			t.Errorf("Term %d: expected alternatives %v, got %v", i, expected[i].alternatives, terms[i].alternatives)
+			continue
+		}
+		for j := range expected[i].alternatives {
+			if terms[i].alternatives[j] != expected[i].alternatives[j] {
+				t.Errorf("Term %d: expected alternatives %v, got %v", i, expected[i].alternatives, terms[i].alternatives)
+			}
+		}
+	}
+}
+
+func TestSaveAndClearSynonyms(t *testing.T) {
+	searcher := New()
+	searcher.AddDocument(Document{
+		ID:     "1",
+		Fields: map[string]interface{}{"make": "Chevrolet"},
+	})
+
+	ctx := context.Background()
+
+	count := func(query string) int64 {
+		t.Helper()
+		results, err := searcher.Search(ctx, query)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		return results.Total
+	}
+
+	searcher.SaveSynonyms(searchx.OneWaySynonym("alias", "chevy", "chevrolet"))
+	if got := count("chevy"); got != 1 {
+		t.Errorf("Expected 1 result with synonym, got %d", got)
+	}
+
+	// Saving a synonym with the same ID replaces it
+	searcher.SaveSynonyms(searchx.OneWaySynonym("alias", "bowtie", "chevrolet"))
+	if got := count("chevy"); got != 0 {
+		t.Errorf("Expected replaced synonym to stop matching, got %d results", got)
+	}
+	if got := count("bowtie"); got != 1 {
+		t.Errorf("Expected 1 result with replacement synonym, got %d", got)
+	}
+
+	searcher.ClearSynonyms()
+	if got := count("bowtie"); got != 0 {
+		t.Errorf("Expected 0 results after clearing synonyms, got %d", got)
+	}
+}
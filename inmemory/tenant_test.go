@@ -0,0 +1,205 @@
+package inmemory
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestTenantSearcher(t *testing.T) {
+	inner := New()
+	inner.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"dealer": "a", "title": "Toyota Camry"}})
+	inner.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"dealer": "a", "title": "Honda Civic"}})
+	inner.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"dealer": "b", "title": "Toyota Corolla"}})
+	inner.AddDocument(Document{ID: "4", Fields: map[string]interface{}{"title": "Toyota Prius"}})
+	inner.SaveRules(searchx.PinRule("sponsored", "toyota", "3"))
+
+	searcher := searchx.NewTenantSearcher(inner, "dealer")
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		query       string
+		opts        []searchx.SearchOption
+		expectedIDs []string
+	}{
+		"all_documents": {
+			opts:        []searchx.SearchOption{searchx.WithTenant("a")},
+			expectedIDs: []string{"1", "2"},
+		},
+		"query": {
+			query:       "toyota",
+			opts:        []searchx.SearchOption{searchx.WithTenant("b")},
+			expectedIDs: []string{"3"},
+		},
+		"pinned_from_other_tenant": {
+			query:       "toyota",
+			opts:        []searchx.SearchOption{searchx.WithTenant("a")},
+			expectedIDs: []string{"1"},
+		},
+		"or_filter": {
+			opts:        []searchx.SearchOption{searchx.WithTenant("a"), searchx.Or(searchx.Eq("dealer", "b"), searchx.Eq("dealer", "a"))},
+			expectedIDs: []string{"1", "2"},
+		},
+		"missing_field_filter": {
+			opts:        []searchx.SearchOption{searchx.WithTenant("a"), searchx.Not(searchx.Exists("dealer"))},
+			expectedIDs: []string{},
+		},
+		"field_syntax": {
+			query:       "dealer:b",
+			opts:        []searchx.SearchOption{searchx.WithTenant("a")},
+			expectedIDs: []string{},
+		},
+		"last_tenant_wins": {
+			opts:        []searchx.SearchOption{searchx.WithTenant("a"), searchx.WithTenant("b")},
+			expectedIDs: []string{"3"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(ctx, tc.query, tc.opts...)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			ids := make([]string, 0, len(results.Items))
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+			}
+			sort.Strings(ids)
+			if !reflect.DeepEqual(ids, tc.expectedIDs) {
+				t.Errorf("Expected %v, got %v", tc.expectedIDs, ids)
+			}
+		})
+	}
+
+	_, err := searcher.Search(ctx, "toyota")
+	if !errors.Is(err, searchx.ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption without a tenant, got: %v", err)
+	}
+}
+
+func TestTenantSpellingSuggestions(t *testing.T) {
+	inner := New()
+	inner.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"dealer": "a", "title": "Toyota Camry"}})
+	inner.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"dealer": "b", "title": "Toyota Corolla secretmodelx"}})
+	inner.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"dealer": "b", "title": "Toyota Camaro"}})
+
+	searcher := searchx.NewTenantSearcher(inner, "dealer")
+	ctx := context.Background()
+
+	// Typos matching documents of other tenants leave no results to correct
+	tests := map[string]struct {
+		query    string
+		opts     []searchx.SearchOption
+		expected []string
+	}{
+		"other_tenant_term": {
+			query: "secretmodel",
+			opts:  []searchx.SearchOption{searchx.WithTenant("a")},
+		},
+		"other_tenant_term_without_typos": {
+			query: "corola",
+			opts:  []searchx.SearchOption{searchx.WithTenant("a"), searchx.WithTypoTolerance(false)},
+		},
+		"own_term": {
+			query:    "camri",
+			opts:     []searchx.SearchOption{searchx.WithTenant("a"), searchx.WithTypoTolerance(false)},
+			expected: []string{"camry"},
+		},
+		"own_terms_only": {
+			query:    "camro",
+			opts:     []searchx.SearchOption{searchx.WithTenant("b"), searchx.WithTypoTolerance(false)},
+			expected: []string{"camaro"},
+		},
+		"other_tenant_word_is_unknown": {
+			query:    "+toyota +corolla +camri",
+			opts:     []searchx.SearchOption{searchx.WithTenant("a"), searchx.WithTypoTolerance(false)},
+			expected: []string{"+toyota +corolla +camry"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(ctx, tc.query, tc.opts...)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if results.Total != 0 {
+				t.Fatalf("Expected no results, got %d", results.Total)
+			}
+			if !reflect.DeepEqual(results.Suggestions, tc.expected) {
+				t.Errorf("Expected suggestions %q, got %q", tc.expected, results.Suggestions)
+			}
+		})
+	}
+}
+
+func TestTenantSuggest(t *testing.T) {
+	inner := New(WithShards(2))
+	inner.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"dealer": "a", "title": "Toyota Camry"}})
+	inner.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"dealer": "b", "title": "Toyota Corolla secretmodelx"}})
+	inner.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"dealer": "b", "title": "Toyota Camaro"}})
+
+	searcher := searchx.NewTenantSearcher(inner, "dealer")
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		prefix   string
+		opts     []searchx.SuggestOption
+		expected []searchx.Suggestion
+	}{
+		"own_terms": {
+			prefix:   "toyota c",
+			opts:     []searchx.SuggestOption{searchx.WithSuggestTenant("a")},
+			expected: []searchx.Suggestion{{Text: "toyota camry", Frequency: 1}},
+		},
+		"own_frequencies": {
+			prefix:   "to",
+			opts:     []searchx.SuggestOption{searchx.WithSuggestTenant("b")},
+			expected: []searchx.Suggestion{{Text: "toyota", Frequency: 2}},
+		},
+		"other_tenant_term": {
+			prefix:   "secret",
+			opts:     []searchx.SuggestOption{searchx.WithSuggestTenant("a")},
+			expected: []searchx.Suggestion{},
+		},
+		"filter_cannot_widen": {
+			prefix:   "c",
+			opts:     []searchx.SuggestOption{searchx.WithSuggestTenant("a"), searchx.WithSuggestFilters(searchx.Eq("dealer", "b"))},
+			expected: []searchx.Suggestion{},
+		},
+		"other_tenant_id": {
+			prefix:   "b",
+			opts:     []searchx.SuggestOption{searchx.WithSuggestTenant("a")},
+			expected: []searchx.Suggestion{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			suggestions, err := searcher.Suggest(ctx, tc.prefix, tc.opts...)
+			if err != nil {
+				t.Fatalf("Suggest failed: %v", err)
+			}
+			if !reflect.DeepEqual(suggestions, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, suggestions)
+			}
+		})
+	}
+
+	_, err := searcher.Suggest(ctx, "toyota")
+	if !errors.Is(err, searchx.ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption without a tenant, got: %v", err)
+	}
+
+	search := searchx.NewTenantSearcher(searchx.SearcherFunc(inner.Search), "dealer")
+	_, err = search.Suggest(ctx, "toyota", searchx.WithSuggestTenant("a"))
+	if !errors.Is(err, searchx.ErrNotImplemented) {
+		t.Errorf("Expected ErrNotImplemented without a Suggester, got: %v", err)
+	}
+}
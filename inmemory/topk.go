@@ -0,0 +1,197 @@
+package inmemory
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// sortKey is the value of a sort field converted once per match, so that
+// comparing matches neither converts nor formats values. It compares the way
+// compareValues does.
+type sortKey struct {
+	present bool
+	// t is set when isTime, the value being a time.
+	t      time.Time
+	isTime bool
+	// num is set when isNum, the value being a number.
+	num   float64
+	isNum bool
+	// str is the formatted value, set when a value of the field is neither a
+	// time nor a number.
+	str string
+}
+
+// newSortKey converts a value of a sort field.
+func newSortKey(value interface{}, now time.Time) sortKey {
+	if value == nil {
+		return sortKey{}
+	}
+	key := sortKey{present: true}
+	key.t, key.isTime = searchx.ParseTime(value, now)
+	key.num, key.isNum = toFloat64(value)
+	return key
+}
+
+// compareSortKeys compares two sort keys like compareValues compares their values.
+func compareSortKeys(a, b *sortKey) int {
+	switch {
+	case !a.present && !b.present:
+		return 0
+	case !a.present:
+		return -1
+	case !b.present:
+		return 1
+	}
+
+	// Compare times chronologically, numbers as seconds since the epoch
+	switch {
+	case a.isTime && b.isTime:
+		return a.t.Compare(b.t)
+	case a.isTime && b.isNum:
+		return a.t.Compare(epochTime(b.num))
+	case a.isNum && b.isTime:
+		return epochTime(a.num).Compare(b.t)
+	}
+
+	if a.isNum && b.isNum {
+		if a.num < b.num {
+			return -1
+		} else if a.num > b.num {
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a.str, b.str)
+}
+
+// rankedMatch is a match with the keys it is sorted by.
+type rankedMatch struct {
+	match scoredDocument
+	keys  []sortKey
+	// order is the position of the match before sorting, breaking ties.
+	order int
+}
+
+// rankMatches converts the sort fields of the matches into sort keys.
+func rankMatches(matches []scoredDocument, sortFields []searchx.SortField) []rankedMatch {
+	ranked := make([]rankedMatch, len(matches))
+	keys := make([]sortKey, len(matches)*len(sortFields))
+	for i, match := range matches {
+		ranked[i] = rankedMatch{match: match, keys: keys[i*len(sortFields) : (i+1)*len(sortFields)], order: i}
+	}
+
+	now := time.Now()
+	for f, sf := range sortFields {
+		if sf.Field == "_score" {
+			continue
+		}
+
+		// Values that are neither times nor numbers compare as strings with
+		// any value, so format the whole field once
+		formatted := false
+		for i := range ranked {
+			key := newSortKey(ranked[i].match.document.Fields[sf.Field], now)
+			if key.present && !key.isTime && !key.isNum {
+				formatted = true
+			}
+			ranked[i].keys[f] = key
+		}
+		if formatted {
+			for i := range ranked {
+				if key := &ranked[i].keys[f]; key.present {
+					key.str = fmt.Sprintf("%v", ranked[i].match.document.Fields[sf.Field])
+				}
+			}
+		}
+	}
+	return ranked
+}
+
+// rankBefore reports whether a match sorts before another. Matches without
+// sort fields are sorted by score descending, and ties keep their order.
+func rankBefore(a, b *rankedMatch, sortFields []searchx.SortField) bool {
+	if len(sortFields) == 0 && a.match.score != b.match.score {
+		return a.match.score > b.match.score
+	}
+
+	for f, sf := range sortFields {
+		var cmp int
+		if sf.Field == "_score" {
+			switch {
+			case a.match.score < b.match.score:
+				cmp = -1
+			case a.match.score > b.match.score:
+				cmp = 1
+			}
+		} else {
+			cmp = compareSortKeys(&a.keys[f], &b.keys[f])
+		}
+
+		if cmp != 0 {
+			if sf.Desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+	}
+	return a.order < b.order
+}
+
+// rankHeap keeps the k best matches seen, the worst of them on top.
+type rankHeap struct {
+	items      []*rankedMatch
+	sortFields []searchx.SortField
+}
+
+func (h rankHeap) Len() int { return len(h.items) }
+
+func (h rankHeap) Less(i, j int) bool {
+	return rankBefore(h.items[j], h.items[i], h.sortFields)
+}
+
+func (h rankHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *rankHeap) Push(x interface{}) { h.items = append(h.items, x.(*rankedMatch)) }
+
+func (h *rankHeap) Pop() interface{} {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// topMatches returns the first k matches according to the sort configuration,
+// in order. Only k matches are kept while selecting them, so that matches past
+// the requested page are never sorted.
+func (s *Searcher) topMatches(matches []scoredDocument, sortFields []searchx.SortField, k int) []scoredDocument {
+	if k > len(matches) {
+		k = len(matches)
+	}
+	ranked := rankMatches(matches, sortFields)
+
+	h := &rankHeap{items: make([]*rankedMatch, 0, k), sortFields: sortFields}
+	for i := range ranked {
+		switch {
+		case k == len(ranked):
+			h.items = append(h.items, &ranked[i]) // Everything is kept
+		case h.Len() < k:
+			heap.Push(h, &ranked[i])
+		case k > 0 && rankBefore(&ranked[i], h.items[0], sortFields):
+			h.items[0] = &ranked[i]
+			heap.Fix(h, 0)
+		}
+	}
+
+	sort.Slice(h.items, func(i, j int) bool {
+		return rankBefore(h.items[i], h.items[j], sortFields)
+	})
+	top := make([]scoredDocument, len(h.items))
+	for i, item := range h.items {
+		top[i] = item.match
+	}
+	return top
+}
@@ -0,0 +1,168 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestTopMatches(t *testing.T) {
+	listed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Numbers compare with times as seconds since the epoch, and with strings
+	// as strings, so codes mix a single number with strings to keep an order
+	values := []interface{}{
+		30, 12.5, nil, 12.5, listed, float64(listed.Unix() - 60),
+		listed.Add(time.Hour).Format(time.RFC3339), 7, int64(-3),
+	}
+	codes := []interface{}{"b", 7, nil, "10", "a", "b"}
+	matches := make([]scoredDocument, 48)
+	for i := range matches {
+		matches[i] = scoredDocument{
+			document: Document{ID: fmt.Sprint(i), Fields: map[string]interface{}{
+				"mixed":  values[i%len(values)],
+				"code":   codes[i%len(codes)],
+				"number": i % 5,
+				"time":   listed.Add(time.Duration(i%7) * time.Minute),
+			}},
+			score: float64(i % 3),
+		}
+	}
+
+	tests := map[string][]searchx.SortField{
+		"score":       nil,
+		"number":      {{Field: "number"}, {Field: "_score", Desc: true}},
+		"time_desc":   {{Field: "time", Desc: true}},
+		"mixed":       {{Field: "mixed"}},
+		"mixed_desc":  {{Field: "mixed", Desc: true}, {Field: "number"}},
+		"code":        {{Field: "code"}, {Field: "number", Desc: true}},
+		"missing":     {{Field: "missing"}},
+		"score_asc":   {{Field: "_score"}},
+		"score_field": {{Field: "_score", Desc: true}, {Field: "time"}},
+	}
+
+	searcher := New()
+	for name, sortFields := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Sorting with compareValues, ties keeping their order
+			expected := make([]scoredDocument, len(matches))
+			copy(expected, matches)
+			for i := 1; i < len(expected); i++ {
+				for j := i; j > 0 && referenceBefore(searcher, expected[j], expected[j-1], sortFields); j-- {
+					expected[j], expected[j-1] = expected[j-1], expected[j]
+				}
+			}
+
+			for _, k := range []int{0, 1, 5, 20, len(matches), 100} {
+				top := searcher.topMatches(matches, sortFields, k)
+				want := expected[:min(k, len(expected))]
+				if matchIDs(top) != matchIDs(want) {
+					t.Errorf("k=%d: expected %v, got %v", k, matchIDs(want), matchIDs(top))
+				}
+			}
+		})
+	}
+}
+
+// referenceBefore compares matches field by field with compareValues.
+func referenceBefore(s *Searcher, a, b scoredDocument, sortFields []searchx.SortField) bool {
+	if len(sortFields) == 0 {
+		return a.score > b.score
+	}
+	for _, sf := range sortFields {
+		cmp := 0
+		if sf.Field == "_score" {
+			switch {
+			case a.score < b.score:
+				cmp = -1
+			case a.score > b.score:
+				cmp = 1
+			}
+		} else {
+			cmp = s.compareValues(a.document.Fields[sf.Field], b.document.Fields[sf.Field])
+		}
+		if cmp != 0 {
+			return (cmp < 0) != sf.Desc
+		}
+	}
+	return false
+}
+
+// matchIDs formats the IDs of matches.
+func matchIDs(matches []scoredDocument) string {
+	ids := make([]string, len(matches))
+	for i, match := range matches {
+		ids[i] = match.document.ID
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestSearchSortedPages(t *testing.T) {
+	searcher := New()
+	for i := 0; i < 25; i++ {
+		searcher.AddDocument(Document{ID: fmt.Sprint(i), Fields: map[string]interface{}{"rank": 25 - i}})
+	}
+
+	tests := map[string]struct {
+		offset   int
+		limit    int
+		expected string
+		next     int // -1 without a next page
+	}{
+		"first_page": {limit: 10, expected: "[24 23 22 21 20 19 18 17 16 15]", next: 10},
+		"last_page":  {offset: 20, limit: 10, expected: "[4 3 2 1 0]", next: -1},
+		"exact_end":  {offset: 15, limit: 10, expected: "[9 8 7 6 5 4 3 2 1 0]", next: -1},
+		"past_end":   {offset: 30, limit: 10, expected: "[]", next: -1},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(context.Background(), "",
+				searchx.WithSort("rank", false),
+				searchx.WithOffset(tc.offset),
+				searchx.WithLimit(tc.limit),
+			)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			var ids []string
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+			}
+			if fmt.Sprint(ids) != tc.expected {
+				t.Errorf("Expected %s, got %v", tc.expected, ids)
+			}
+			if results.Total != 25 {
+				t.Errorf("Expected total 25, got %d", results.Total)
+			}
+			next := -1
+			if results.NextOffset != nil {
+				next = *results.NextOffset
+			}
+			if next != tc.next {
+				t.Errorf("Expected next offset %d, got %d", tc.next, next)
+			}
+		})
+	}
+}
+
+func BenchmarkSearchTopK(b *testing.B) {
+	searcher := New()
+	docs := make([]Document, 100000)
+	for i := range docs {
+		docs[i] = Document{ID: fmt.Sprint(i), Fields: map[string]interface{}{
+			"title": "used sedan",
+			"price": float64((i * 7919) % 50000),
+		}}
+	}
+	searcher.AddDocuments(docs)
+
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = searcher.Search(ctx, "sedan", searchx.WithSort("price", true), searchx.WithLimit(20))
+	}
+}
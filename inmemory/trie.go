@@ -0,0 +1,238 @@
+package inmemory
+
+import (
+	"fmt"
+	"maps"
+)
+
+// trie is a prefix tree of the terms found in indexed documents. It is
+// persistent, see pmap: changes copy the nodes on the path to the term.
+type trie struct {
+	root *trieNode
+}
+
+// trieNode is a node of a trie. A node ends a term when docs is above zero.
+type trieNode struct {
+	gen      uint64
+	children map[rune]*trieNode
+	// docs is the number of documents containing the term ending at this node.
+	docs int
+	// fields counts the documents containing the term per field.
+	fields map[string]int
+}
+
+// edit returns the node to change in generation gen, copying it if needed.
+// A nil node is replaced by a new one.
+func (n *trieNode) edit(gen uint64) *trieNode {
+	switch {
+	case n == nil:
+		return &trieNode{gen: gen}
+	case n.gen == gen:
+		return n
+	}
+	return &trieNode{gen: gen, children: maps.Clone(n.children), docs: n.docs, fields: maps.Clone(n.fields)}
+}
+
+// add records a document containing term in the given fields, in generation gen.
+func (t *trie) add(gen uint64, term string, fields []string) {
+	t.root = t.root.edit(gen)
+	node := t.root
+	for _, r := range term {
+		child := node.children[r].edit(gen)
+		if node.children == nil {
+			node.children = make(map[rune]*trieNode)
+		}
+		node.children[r] = child
+		node = child
+	}
+
+	node.docs++
+	if node.fields == nil {
+		node.fields = make(map[string]int, len(fields))
+	}
+	for _, field := range fields {
+		node.fields[field]++
+	}
+}
+
+// remove forgets a document containing term in the given fields, in generation
+// gen, pruning nodes that no longer lead to any term.
+func (t *trie) remove(gen uint64, term string, fields []string) {
+	if t.root != nil {
+		t.root, _ = t.root.remove(gen, []rune(term), fields)
+	}
+}
+
+// remove implements trie.remove below this node. It returns the node to
+// replace this one with, and whether it became empty and can be pruned.
+func (n *trieNode) remove(gen uint64, term []rune, fields []string) (*trieNode, bool) {
+	if len(term) == 0 {
+		n = n.edit(gen)
+		if n.docs > 0 {
+			n.docs--
+		}
+		for _, field := range fields {
+			if n.fields[field]--; n.fields[field] <= 0 {
+				delete(n.fields, field)
+			}
+		}
+	} else if child, ok := n.children[term[0]]; ok {
+		child, empty := child.remove(gen, term[1:], fields)
+		n = n.edit(gen)
+		if empty {
+			delete(n.children, term[0])
+		} else {
+			n.children[term[0]] = child
+		}
+	}
+	return n, n.docs == 0 && len(n.children) == 0
+}
+
+// find returns the node reached by following prefix, or nil if there is none.
+func (t *trie) find(prefix string) *trieNode {
+	node := t.root
+	if node == nil {
+		return nil
+	}
+	for _, r := range prefix {
+		child, ok := node.children[r]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+	return node
+}
+
+// walk calls fn for every term at or below this node, prefix being the term of the node.
+func (n *trieNode) walk(prefix string, fn func(term string, node *trieNode)) {
+	if n.docs > 0 {
+		fn(prefix, n)
+	}
+	for r, child := range n.children {
+		child.walk(prefix+string(r), fn)
+	}
+}
+
+// fuzzy calls fn for every term within maxDist edits of target, measured as
+// the optimal string alignment distance like damerauLevenshtein. Branches are
+// pruned as soon as no term below them can be close enough.
+func (t *trie) fuzzy(target string, maxDist int, fn func(term string, distance int, node *trieNode)) {
+	if t.root == nil {
+		return
+	}
+	runes := []rune(target)
+
+	// Distances between the empty word and every prefix of the target
+	row := make([]int, len(runes)+1)
+	for j := range row {
+		row[j] = j
+	}
+
+	word := make([]rune, 0, len(runes)+maxDist)
+	for r, child := range t.root.children {
+		child.fuzzy(runes, append(word, r), nil, row, maxDist, fn)
+	}
+}
+
+// fuzzy implements trie.fuzzy for the node reached by word. prev and prev2 are
+// the distance rows of the parent and grandparent nodes.
+func (n *trieNode) fuzzy(target, word []rune, prev2, prev []int, maxDist int, fn func(string, int, *trieNode)) {
+	i := len(word)
+	r := word[i-1]
+
+	curr := make([]int, len(target)+1)
+	curr[0] = i
+	rowMin := curr[0]
+	for j := 1; j <= len(target); j++ {
+		cost := 1
+		if target[j-1] == r {
+			cost = 0
+		}
+
+		curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		if i > 1 && j > 1 && r == target[j-2] && word[i-2] == target[j-1] {
+			curr[j] = min(curr[j], prev2[j-2]+1)
+		}
+		rowMin = min(rowMin, curr[j])
+	}
+
+	if n.docs > 0 && curr[len(target)] <= maxDist {
+		fn(string(word), curr[len(target)], n)
+	}
+
+	// Rows below only grow from this row, or from the previous one through a transposition
+	if rowMin > maxDist && minInt(prev)+1 > maxDist {
+		return
+	}
+	for next, child := range n.children {
+		child.fuzzy(target, append(word, next), prev, curr, maxDist, fn)
+	}
+}
+
+// minInt returns the smallest value of a non-empty slice.
+func minInt(values []int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		m = min(m, v)
+	}
+	return m
+}
+
+// frequency returns the number of documents containing the term of this node
+// in any of the given fields, or in any field when fields is empty. Documents
+// containing the term in several of the fields are counted once per field.
+func (n *trieNode) frequency(fields []string) int {
+	if len(fields) == 0 {
+		return n.docs
+	}
+	total := 0
+	for _, field := range fields {
+		total += n.fields[field]
+	}
+	return total
+}
+
+// documentTerms returns the vocabulary terms of a document along with the
+// fields containing each of them. The vocabulary holds the terms searchable
+// fields are indexed with, as analyzed by the analyzer of each field, so a
+// stemming analyzer makes stems part of it. Only text and numbers are part of
+// the vocabulary.
+func (s *Searcher) documentTerms(doc Document) map[string][]string {
+	terms := make(map[string][]string)
+	for field, value := range doc.Fields {
+		if s.schema != nil && !s.schema.Searchable(field) {
+			continue
+		}
+		analyzer, _ := s.analyzerFor(field)
+		seen := make(map[string]bool)
+		visitText(value, func(text string) {
+			for _, token := range analyzer.Analyze(text) {
+				if !seen[token.Term] {
+					seen[token.Term] = true
+					terms[token.Term] = append(terms[token.Term], field)
+				}
+			}
+		})
+	}
+	return terms
+}
+
+// visitText calls fn for every string and number in a value, descending into
+// arrays and nested objects.
+func visitText(value interface{}, fn func(text string)) {
+	switch v := value.(type) {
+	case string:
+		fn(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		fn(fmt.Sprintf("%v", v))
+	case []interface{}:
+		for _, item := range v {
+			visitText(item, fn)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			visitText(item, fn)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package inmemory
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// WithVectorSimilarity sets how the vectors of a field are compared.
+// Fields use cosine similarity by default.
+func WithVectorSimilarity(field string, similarity searchx.Similarity) Option {
+	return func(s *Searcher) {
+		if s.similarities == nil {
+			s.similarities = make(map[string]searchx.Similarity)
+		}
+		s.similarities[field] = similarity
+	}
+}
+
+// WithHNSW indexes the vectors of a field in an HNSW graph, making vector
+// searches approximate but sublinear. Without it every document is compared
+// with the query vector.
+func WithHNSW(field string, cfg HNSWConfig) Option {
+	return func(s *Searcher) {
+		if s.hnswConfigs == nil {
+			s.hnswConfigs = make(map[string]HNSWConfig)
+		}
+		s.hnswConfigs[field] = cfg
+	}
+}
+
+// SearchVector implements the searchx.VectorSearcher interface.
+func (s *Searcher) SearchVector(ctx context.Context, query searchx.VectorQuery, opts ...searchx.SearchOption) (*searchx.Results, error) {
+	return s.Search(ctx, "", append(opts, query)...)
+}
+
+// similarityFor returns the similarity used for a vector field.
+func (s *Searcher) similarityFor(field string) searchx.Similarity {
+	if similarity, ok := s.similarities[field]; ok {
+		return similarity
+	}
+	return searchx.SimilarityCosine
+}
+
+// resetVectorIndexes creates empty HNSW graphs for the indexed vector fields.
+func (s *Searcher) resetVectorIndexes() {
+	s.vectorIndexes = make(map[string]*hnswIndex, len(s.hnswConfigs))
+	for field, cfg := range s.hnswConfigs {
+		s.vectorIndexes[field] = newHNSWIndex(cfg, s.similarityFor(field))
+	}
+}
+
+// editVectorIndexes prepares the HNSW graphs for changes in the current
+// generation, copying them unless they were copied in it already.
+// The caller must hold the write lock.
+func (s *Searcher) editVectorIndexes() {
+	for _, index := range s.vectorIndexes {
+		if index.gen != s.gen {
+			indexes := make(map[string]*hnswIndex, len(s.vectorIndexes))
+			for field, index := range s.vectorIndexes {
+				indexes[field] = index.edit(s.gen)
+			}
+			s.vectorIndexes = indexes
+		}
+		return // The graphs are all copied at once
+	}
+}
+
+// indexVectors adds the vectors of a document to the HNSW graphs.
+// The caller must hold the write lock.
+func (s *Searcher) indexVectors(doc Document) {
+	s.editVectorIndexes()
+	for field, index := range s.vectorIndexes {
+		if vector, ok := toVector(doc.Fields[field]); ok {
+			index.add(s.gen, doc.ID, vector)
+		} else {
+			index.remove(s.gen, doc.ID)
+		}
+	}
+}
+
+// unindexVectors removes the vectors of a document from the HNSW graphs.
+// The caller must hold the write lock.
+func (s *Searcher) unindexVectors(id string) {
+	s.editVectorIndexes()
+	for _, index := range s.vectorIndexes {
+		index.remove(s.gen, id)
+	}
+}
+
+// validateVectorOptions checks the vector and hybrid options before searching.
+func validateVectorOptions(cfg *searchx.SearchConfig) error {
+	if cfg.HybridAlpha != nil {
+		if cfg.Vector == nil {
+			return errors.Wrap(searchx.ErrInvalidOption, "hybrid search requires a vector query")
+		}
+		if alpha := *cfg.HybridAlpha; alpha < 0 || alpha > 1 || math.IsNaN(alpha) {
+			return errors.Wrapf(searchx.ErrInvalidOption, "hybrid alpha must be between 0 and 1, got %v", alpha)
+		}
+	}
+	if cfg.Vector != nil {
+		if cfg.Vector.K <= 0 {
+			return errors.Wrapf(searchx.ErrInvalidOption, "vector k must be positive, got %d", cfg.Vector.K)
+		}
+		if len(cfg.Vector.Vector) == 0 {
+			return errors.Wrap(searchx.ErrInvalidOption, "vector query is empty")
+		}
+	}
+	return nil
+}
+
+// vectorMatches runs the vector query of cfg over the hits of every shard,
+// see vectorHits, along with the lexical matches for hybrid search. Without
+// hybrid search the k hits most similar to the query vector are returned,
+// scored by similarity. With hybrid search the k most similar hits are merged
+// with the lexical matches and scored by fusing both normalized scores.
+func vectorMatches(hits, lexical []scoredDocument, cfg *searchx.SearchConfig) []scoredDocument {
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].score > hits[j].score
+	})
+	if len(hits) > cfg.Vector.K {
+		hits = hits[:cfg.Vector.K]
+	}
+
+	if cfg.HybridAlpha == nil {
+		return hits
+	}
+	return fuseScores(hits, lexical, *cfg.HybridAlpha)
+}
+
+// vectorHits returns the k nearest documents scored by similarity to the query
+// vector of cfg. Without hybrid search they must pass the filters and match the
+// query terms, and with hybrid search only pass the filters.
+func (s *Searcher) vectorHits(ctx context.Context, terms []queryTerm, cfg *searchx.SearchConfig) ([]scoredDocument, error) {
+	hybrid := cfg.HybridAlpha != nil
+	plan := s.planFilters(cfg.Filters)
+	accept := func(doc Document) bool {
+		if pos, _ := s.idIndex.get(doc.ID); !plan.matches(s, pos, doc) {
+			return false
+		}
+		return hybrid || len(terms) == 0 || s.scoreTerms(s.corpus(), s.index.documentTokens(doc.ID), terms, cfg) > 0
+	}
+
+	hits, err := s.nearest(ctx, *cfg.Vector, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]scoredDocument, len(hits))
+	for i, hit := range hits {
+		matches[i] = scoredDocument{document: s.documentByID(hit.id), score: hit.score}
+	}
+	return matches, nil
+}
+
+// nearest returns the k documents most similar to the query vector among those
+// accepted, using the field's HNSW graph when there is one. Query vectors whose
+// dimension differs from that of every vector of the field return
+// searchx.ErrInvalidOption.
+func (s *Searcher) nearest(ctx context.Context, query searchx.VectorQuery, accept func(Document) bool) ([]vectorHit, error) {
+	if index, ok := s.vectorIndexes[query.Field]; ok {
+		return index.search(query.Vector, query.K, func(id string) bool {
+			return accept(s.documentByID(id))
+		})
+	}
+
+	// Brute force: compare the query with every document
+	similarity := s.similarityFor(query.Field)
+	vector := prepareVector(query.Vector, similarity)
+	if vector == nil {
+		return nil, nil
+	}
+
+	// dims is the number of dimensions of the vectors of the field when none
+	// has as many as the query vector, reported as an error
+	var hits []vectorHit
+	dims, matched := 0, false
+	for pos, doc := range s.documents.all() {
+		select {
+		case <-ctx.Done():
+			return nil, searchx.ErrCanceled
+		default:
+		}
+
+		if s.tombstones.get(pos) {
+			continue
+		}
+
+		docVector, ok := toVector(doc.Fields[query.Field])
+		if !ok {
+			continue
+		}
+		if len(docVector) != len(vector) {
+			dims = len(docVector)
+			continue
+		}
+		matched = true
+		if !accept(doc) {
+			continue
+		}
+		if docVector = prepareVector(docVector, similarity); docVector == nil {
+			continue
+		}
+		hits = append(hits, vectorHit{id: doc.ID, score: dot(vector, docVector)})
+	}
+	if !matched && dims != 0 {
+		return nil, errors.Wrapf(searchx.ErrInvalidOption, "vector has %d dimensions, index has %d", len(vector), dims)
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].score > hits[j].score
+	})
+	if len(hits) > query.K {
+		hits = hits[:query.K]
+	}
+	return hits, nil
+}
+
+// fuseScores merges vector hits with lexical matches, scoring each document
+// alpha*vector + (1-alpha)*lexical with both scores min-max normalized to [0, 1].
+// Documents missing from one of the lists score zero for it.
+func fuseScores(hits, lexical []scoredDocument, alpha float64) []scoredDocument {
+	vectorScores := make([]float64, len(hits))
+	for i, hit := range hits {
+		vectorScores[i] = hit.score
+	}
+	lexicalScores := make([]float64, len(lexical))
+	for i, match := range lexical {
+		lexicalScores[i] = match.score
+	}
+	normalize(vectorScores)
+	normalize(lexicalScores)
+
+	fused := make(map[string]*scoredDocument, len(hits)+len(lexical))
+	var order []string
+	add := func(doc Document, score float64) {
+		if match, ok := fused[doc.ID]; ok {
+			match.score += score
+			return
+		}
+		fused[doc.ID] = &scoredDocument{document: doc, score: score}
+		order = append(order, doc.ID)
+	}
+
+	for i, hit := range hits {
+		add(hit.document, alpha*vectorScores[i])
+	}
+	for i, match := range lexical {
+		add(match.document, (1-alpha)*lexicalScores[i])
+	}
+
+	matches := make([]scoredDocument, len(order))
+	for i, id := range order {
+		matches[i] = *fused[id]
+	}
+	return matches
+}
+
+// normalize rescales scores to [0, 1] in place. Equal scores all become 1.
+func normalize(scores []float64) {
+	if len(scores) == 0 {
+		return
+	}
+	lo, hi := scores[0], scores[0]
+	for _, score := range scores {
+		lo = min(lo, score)
+		hi = max(hi, score)
+	}
+	for i, score := range scores {
+		if hi == lo {
+			scores[i] = 1
+		} else {
+			scores[i] = (score - lo) / (hi - lo)
+		}
+	}
+}
+
+// toVector converts a field value to a vector. It accepts []float32, []float64
+// and arrays of numbers as decoded from JSON.
+func toVector(value interface{}) ([]float32, bool) {
+	switch v := value.(type) {
+	case []float32:
+		return v, len(v) > 0
+	case []float64:
+		vector := make([]float32, len(v))
+		for i, f := range v {
+			vector[i] = float32(f)
+		}
+		return vector, len(v) > 0
+	case []interface{}:
+		vector := make([]float32, len(v))
+		for i, item := range v {
+			f, ok := toFloat64(item)
+			if !ok {
+				return nil, false
+			}
+			vector[i] = float32(f)
+		}
+		return vector, len(v) > 0
+	default:
+		return nil, false
+	}
+}
+
+// prepareVector prepares a vector for comparison with dot. For cosine
+// similarity it returns a normalized copy, or nil for a zero vector.
+func prepareVector(vector []float32, similarity searchx.Similarity) []float32 {
+	if similarity == searchx.SimilarityDotProduct {
+		return vector
+	}
+
+	norm := math.Sqrt(dot(vector, vector))
+	if norm == 0 {
+		return nil
+	}
+	normalized := make([]float32, len(vector))
+	for i, v := range vector {
+		normalized[i] = float32(float64(v) / norm)
+	}
+	return normalized
+}
+
+// dot returns the dot product of two vectors of the same length.
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
@@ -0,0 +1,205 @@
+package inmemory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestVectorSearch(t *testing.T) {
+	searcher := New(WithVectorSimilarity("raw", searchx.SimilarityDotProduct))
+
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"make": "toyota", "embedding": []float32{1, 0}, "raw": []float32{1, 0}}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"make": "toyota", "embedding": []float64{0.9, 0.2}, "raw": []float32{3, 0}}})
+	searcher.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"make": "honda", "embedding": []float32{0, 1}, "raw": []float32{0, 1}}})
+	searcher.AddDocument(Document{ID: "5", Fields: map[string]interface{}{"make": "honda"}})
+	if err := searcher.AddJSON("4", []byte(`{"make": "honda", "embedding": [-1, 0]}`)); err != nil {
+		t.Fatalf("AddJSON failed: %v", err)
+	}
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		query          string
+		opts           []searchx.SearchOption
+		expectedOrder  []string
+		expectedScores []float64
+	}{
+		"nearest": {
+			opts:          []searchx.SearchOption{searchx.WithVector("embedding", []float32{1, 0.05}, 2)},
+			expectedOrder: []string{"1", "2"},
+		},
+		"k_larger_than_documents": {
+			opts:          []searchx.SearchOption{searchx.WithVector("embedding", []float32{1, 0}, 10)},
+			expectedOrder: []string{"1", "2", "3", "4"},
+		},
+		"filtered": {
+			opts:          []searchx.SearchOption{searchx.WithVector("embedding", []float32{1, 0}, 2), searchx.Eq("make", "honda")},
+			expectedOrder: []string{"3", "4"},
+		},
+		"restricted_by_query": {
+			query:         "honda",
+			opts:          []searchx.SearchOption{searchx.WithVector("embedding", []float32{1, 0}, 1)},
+			expectedOrder: []string{"3"},
+		},
+		"dot_product": {
+			opts:           []searchx.SearchOption{searchx.WithVector("raw", []float32{1, 0}, 2)},
+			expectedOrder:  []string{"2", "1"},
+			expectedScores: []float64{3, 1},
+		},
+		"unknown_field": {
+			opts:          []searchx.SearchOption{searchx.WithVector("missing", []float32{1, 0}, 2)},
+			expectedOrder: []string{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(ctx, tc.query, tc.opts...)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			ids := make([]string, 0, len(results.Items))
+			scores := make([]float64, 0, len(results.Items))
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+				scores = append(scores, item.Score)
+			}
+			if !reflect.DeepEqual(ids, tc.expectedOrder) {
+				t.Errorf("Expected %v, got %v", tc.expectedOrder, ids)
+			}
+			if tc.expectedScores != nil && !reflect.DeepEqual(scores, tc.expectedScores) {
+				t.Errorf("Expected scores %v, got %v", tc.expectedScores, scores)
+			}
+		})
+	}
+}
+
+func TestHybridSearch(t *testing.T) {
+	searcher := New()
+
+	searcher.AddDocument(Document{ID: "a", Fields: map[string]interface{}{"title": "red car", "embedding": []float32{0, 1}}})
+	searcher.AddDocument(Document{ID: "b", Fields: map[string]interface{}{"title": "blue car", "embedding": []float32{1, 0}}})
+	searcher.AddDocument(Document{ID: "c", Fields: map[string]interface{}{"title": "red truck", "embedding": []float32{0.8, 0.6}}})
+	searcher.AddDocument(Document{ID: "d", Fields: map[string]interface{}{"title": "green bike"}})
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		query         string
+		alpha         float64
+		k             int
+		expectedOrder []string
+	}{
+		"mostly_vector": {
+			query:         "red",
+			alpha:         0.7,
+			k:             3,
+			expectedOrder: []string{"c", "b", "a"},
+		},
+		"mostly_lexical": {
+			query:         "red",
+			alpha:         0.2,
+			k:             3,
+			expectedOrder: []string{"c", "a", "b"},
+		},
+		"vector_only": {
+			query:         "red",
+			alpha:         1,
+			k:             3,
+			expectedOrder: []string{"b", "c", "a"},
+		},
+		"union_of_both": {
+			query:         "green",
+			alpha:         0.5,
+			k:             1,
+			expectedOrder: []string{"b", "d"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, err := searcher.Search(ctx, tc.query,
+				searchx.WithVector("embedding", []float32{1, 0}, tc.k),
+				searchx.WithHybrid(tc.alpha),
+			)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+
+			ids := make([]string, 0, len(results.Items))
+			for _, item := range results.Items {
+				ids = append(ids, item.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.expectedOrder) {
+				t.Errorf("Expected %v, got %v", tc.expectedOrder, ids)
+			}
+		})
+	}
+}
+
+func TestSearchVector(t *testing.T) {
+	var searcher searchx.VectorSearcher = New()
+	searcher.(*Searcher).AddDocument(Document{ID: "1", Fields: map[string]interface{}{"embedding": []float32{1, 0}}})
+	searcher.(*Searcher).AddDocument(Document{ID: "2", Fields: map[string]interface{}{"embedding": []float32{0, 1}}})
+
+	results, err := searcher.SearchVector(context.Background(), searchx.VectorQuery{Field: "embedding", Vector: []float32{0, 1}, K: 1})
+	if err != nil {
+		t.Fatalf("SearchVector failed: %v", err)
+	}
+	if len(results.Items) != 1 || results.Items[0].ID != "2" {
+		t.Errorf("Expected document 2, got %v", results.Items)
+	}
+}
+
+func TestVectorDimensionMismatch(t *testing.T) {
+	tests := map[string][]Option{
+		"brute_force": nil,
+		"hnsw":        {WithHNSW("embedding", HNSWConfig{})},
+		"sharded":     {WithShards(3)},
+	}
+
+	ctx := context.Background()
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			searcher := New(opts...)
+			searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"embedding": []float32{1, 0}}})
+			searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"embedding": []float32{0, 1}}})
+
+			_, err := searcher.Search(ctx, "", searchx.WithVector("embedding", []float32{1, 0, 0}, 2))
+			if !errors.Is(err, searchx.ErrInvalidOption) {
+				t.Errorf("Expected ErrInvalidOption, got: %v", err)
+			}
+			results, err := searcher.Search(ctx, "", searchx.WithVector("embedding", []float32{1, 0}, 2))
+			if err != nil || results.Total != 2 {
+				t.Errorf("Expected 2 results, got %v and error %v", results, err)
+			}
+		})
+	}
+}
+
+func TestVectorSearchValidation(t *testing.T) {
+	searcher := New()
+	ctx := context.Background()
+
+	tests := map[string][]searchx.SearchOption{
+		"zero_k":         {searchx.WithVector("embedding", []float32{1}, 0)},
+		"empty_vector":   {searchx.WithVector("embedding", nil, 5)},
+		"alpha_too_high": {searchx.WithVector("embedding", []float32{1}, 5), searchx.WithHybrid(1.5)},
+		"negative_alpha": {searchx.WithVector("embedding", []float32{1}, 5), searchx.WithHybrid(-0.1)},
+		"hybrid_only":    {searchx.WithHybrid(0.5)},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := searcher.Search(ctx, "", opts...)
+			if !errors.Is(err, searchx.ErrInvalidOption) {
+				t.Errorf("Expected ErrInvalidOption, got: %v", err)
+			}
+		})
+	}
+}
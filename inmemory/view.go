@@ -0,0 +1,39 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+// View is a read-only version of a searcher, as it was when the view was
+// taken. Searches of a view never see later changes, so that paging through
+// its results with Offset or NextOffset neither skips nor repeats documents
+// while the searcher keeps changing. Taking a view copies nothing: it keeps the
+// documents of its version in memory until it is no longer referenced.
+type View struct {
+	s *Searcher
+}
+
+// View returns a view of the documents as of the last change published.
+// This method is safe for concurrent use.
+func (s *Searcher) View() *View {
+	return &View{s: s.current()}
+}
+
+// Search implements the searchx.Searcher interface.
+// This method is safe for concurrent use.
+func (v *View) Search(ctx context.Context, query string, opts ...searchx.SearchOption) (*searchx.Results, error) {
+	return v.s.search(ctx, query, opts...)
+}
+
+// SearchVector implements the searchx.VectorSearcher interface.
+// This method is safe for concurrent use.
+func (v *View) SearchVector(ctx context.Context, query searchx.VectorQuery, opts ...searchx.SearchOption) (*searchx.Results, error) {
+	return v.Search(ctx, "", append(opts, query)...)
+}
+
+// Size returns the number of documents in the view.
+func (v *View) Size() int {
+	return v.s.size()
+}
@@ -0,0 +1,218 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/letmevibethatforyou/searchx"
+)
+
+func TestView(t *testing.T) {
+	newSearcher := func() *Searcher {
+		s := New(
+			WithSchema(searchx.NewSchema(
+				searchx.Field{Name: "title", Type: searchx.FieldString, Searchable: true},
+				searchx.Field{Name: "price", Type: searchx.FieldNumber, Filterable: true, Sortable: true},
+			)),
+			WithHNSW("embedding", HNSWConfig{EfConstruction: 32, Seed: 1}),
+		)
+		docs := make([]Document, 2000)
+		for i := range docs {
+			docs[i] = Document{ID: strconv.Itoa(i), Fields: map[string]interface{}{
+				"title":     fmt.Sprintf("car %d", i%7),
+				"price":     i,
+				"embedding": []float32{1, float32(i % 10)},
+			}}
+		}
+		s.AddDocuments(docs)
+		s.SaveSynonyms(searchx.Synonym{ID: "auto", Type: searchx.SynonymTwoWay, Synonyms: []string{"car", "auto"}})
+		return s
+	}
+
+	searches := map[string][]searchx.SearchOption{
+		"lexical":  {searchx.WithLimit(50)},
+		"filtered": {searchx.Gte("price", 1500), searchx.WithLimit(50)},
+		"sorted":   {searchx.WithSort("price", true), searchx.WithLimit(50)},
+		"vector":   {searchx.VectorQuery{Field: "embedding", Vector: []float32{1, 3}, K: 20}},
+	}
+
+	tests := map[string]func(s *Searcher){
+		"add": func(s *Searcher) {
+			for i := 2000; i < 2100; i++ {
+				s.AddDocument(Document{ID: strconv.Itoa(i), Fields: map[string]interface{}{
+					"title": "car 1", "price": i, "embedding": []float32{1, 3},
+				}})
+			}
+		},
+		"update": func(s *Searcher) {
+			for i := 0; i < 2000; i += 3 {
+				s.AddDocument(Document{ID: strconv.Itoa(i), Fields: map[string]interface{}{
+					"title": "truck", "price": -i, "embedding": []float32{3, 1},
+				}})
+			}
+		},
+		"remove_and_compact": func(s *Searcher) {
+			for i := 0; i < 1500; i++ {
+				s.RemoveDocument(strconv.Itoa(i))
+			}
+		},
+		"clear": func(s *Searcher) {
+			s.Clear()
+		},
+		"synonyms_and_rules": func(s *Searcher) {
+			s.ClearSynonyms()
+			s.SaveRules(searchx.Rule{ID: "hide", Consequence: searchx.RuleConsequence{Hide: []string{"1", "2", "3"}}})
+		},
+	}
+
+	ctx := context.Background()
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			searcher := newSearcher()
+			view := searcher.View()
+
+			expected := make(map[string]string)
+			for search, opts := range searches {
+				results, err := view.Search(ctx, "auto", opts...)
+				if err != nil {
+					t.Fatalf("Search %s failed: %v", search, err)
+				}
+				expected[search] = resultIDs(results)
+			}
+
+			change(searcher)
+
+			if view.Size() != 2000 {
+				t.Errorf("Expected the view to keep 2000 documents, got %d", view.Size())
+			}
+			changed := false
+			for search, opts := range searches {
+				results, err := view.Search(ctx, "auto", opts...)
+				if err != nil {
+					t.Fatalf("Search %s failed: %v", search, err)
+				}
+				if got := resultIDs(results); got != expected[search] {
+					t.Errorf("Search %s: expected %s, got %s", search, expected[search], got)
+				}
+				if current, _ := searcher.Search(ctx, "auto", opts...); resultIDs(current) != expected[search] {
+					changed = true
+				}
+			}
+			if !changed {
+				t.Error("Expected the searcher to see the change")
+			}
+		})
+	}
+}
+
+func TestViewPagination(t *testing.T) {
+	searcher := New()
+	for i := 0; i < 100; i++ {
+		searcher.AddDocument(Document{ID: strconv.Itoa(i), Fields: map[string]interface{}{"title": "car"}})
+	}
+
+	// Changes between pages neither shift nor repeat results
+	view := searcher.View()
+	seen := make(map[string]bool)
+	offset := 0
+	for page := 0; ; page++ {
+		results, err := view.Search(context.Background(), "car", searchx.WithLimit(30), searchx.WithOffset(offset))
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		for _, item := range results.Items {
+			if seen[item.ID] {
+				t.Errorf("Page %d: document %s seen twice", page, item.ID)
+			}
+			seen[item.ID] = true
+		}
+		searcher.RemoveDocument(strconv.Itoa(page))
+		searcher.AddDocument(Document{ID: "new" + strconv.Itoa(page), Fields: map[string]interface{}{"title": "car"}})
+
+		if results.NextOffset == nil {
+			break
+		}
+		offset = *results.NextOffset
+	}
+	if len(seen) != 100 {
+		t.Errorf("Expected 100 documents over all pages, got %d", len(seen))
+	}
+}
+
+func TestConcurrentSearches(t *testing.T) {
+	for _, shards := range []int{1, 4} {
+		t.Run(fmt.Sprint("shards_", shards), func(t *testing.T) {
+			searcher := New(WithShards(shards), WithSchema(searchx.NewSchema(
+				searchx.Field{Name: "title", Type: searchx.FieldString, Searchable: true},
+				searchx.Field{Name: "pair", Type: searchx.FieldNumber, Filterable: true},
+			)))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// Documents are added and removed in pairs, so searches always see an
+			// even number of them
+			var writers sync.WaitGroup
+			for w := 0; w < 2; w++ {
+				writers.Add(1)
+				go func() {
+					defer writers.Done()
+					for i := 0; i < 300; i++ {
+						a, b := fmt.Sprintf("%d-%d-a", w, i), fmt.Sprintf("%d-%d-b", w, i)
+						searcher.AddDocuments([]Document{
+							{ID: a, Fields: map[string]interface{}{"title": "pair", "pair": i}},
+							{ID: b, Fields: map[string]interface{}{"title": "pair", "pair": i}},
+						})
+						if i%2 == 0 {
+							searcher.RemoveDocuments([]string{a, b})
+						}
+					}
+				}()
+			}
+
+			var readers sync.WaitGroup
+			errs := make(chan error, 4)
+			for r := 0; r < 4; r++ {
+				readers.Add(1)
+				go func() {
+					defer readers.Done()
+					for ctx.Err() == nil {
+						results, err := searcher.Search(ctx, "pair", searchx.Gte("pair", 10))
+						if err != nil {
+							if ctx.Err() == nil {
+								errs <- err
+							}
+							return
+						}
+						if results.Total%2 != 0 {
+							errs <- fmt.Errorf("expected an even number of matches, got %d", results.Total)
+							return
+						}
+					}
+				}()
+			}
+
+			writers.Wait()
+			cancel()
+			readers.Wait()
+			close(errs)
+			for err := range errs {
+				t.Error(err)
+			}
+			if searcher.Size() != 600 {
+				t.Errorf("Expected 600 documents, got %d", searcher.Size())
+			}
+		})
+	}
+}
+
+// resultIDs formats the total and the IDs of the results in order.
+func resultIDs(results *searchx.Results) string {
+	ids := make([]string, len(results.Items))
+	for i, item := range results.Items {
+		ids[i] = item.ID
+	}
+	return fmt.Sprint(results.Total, ids)
+}
@@ -0,0 +1,133 @@
+package inmemory
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/cockroachdb/errors"
+)
+
+// walOp is the kind of change recorded by a WAL entry.
+type walOp string
+
+const (
+	walAdd    walOp = "add"
+	walRemove walOp = "remove"
+	walClear  walOp = "clear"
+)
+
+// walEntry is a change recorded in the write-ahead log, one JSON object per line.
+type walEntry struct {
+	// Sequence numbers the change, see Searcher.sequence.
+	Sequence uint64 `json:"seq"`
+	Op       walOp  `json:"op"`
+	// ID and Fields describe the document added or removed.
+	ID     string                 `json:"id,omitempty"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// WithWAL logs every change to the documents to w before applying it, so that
+// a searcher can be restored after a crash by loading its last snapshot and
+// replaying the log with ReplayWAL. Each change is written as a single line with
+// one call to w.Write. Writes are not synced: wrap w to sync them when changes
+// must survive the machine, rather than only the process, crashing.
+//
+// Entries are numbered, and snapshots record the number of the last change they
+// contain, so the log can be truncated at any time after a snapshot is taken.
+// When writing to w fails, later changes are still applied but no longer
+// logged, and WALErr returns the error.
+func WithWAL(w io.Writer) Option {
+	return func(s *Searcher) {
+		s.wal = w
+	}
+}
+
+// WALErr returns the first error writing to the write-ahead log, if any.
+// This method is safe for concurrent use.
+func (s *Searcher) WALErr() error {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+	return s.walErr
+}
+
+// logChange numbers a change and appends it to the write-ahead log, if there
+// is one. The caller must hold the write lock of the shard changed.
+func (s *Searcher) logChange(entry walEntry) {
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+
+	s.sequence++
+	if shard := s.shardFor(entry.ID); entry.Op != walClear && shard != s && shard.logged == 0 {
+		shard.logged = s.sequence
+	}
+	if s.wal == nil || s.walErr != nil {
+		return
+	}
+
+	entry.Sequence = s.sequence
+	line, err := json.Marshal(entry)
+	if err != nil {
+		s.walErr = errors.Wrapf(err, "failed to encode change %d", entry.Sequence)
+		return
+	}
+	if _, err := s.wal.Write(append(line, '\n')); err != nil {
+		s.walErr = errors.Wrapf(err, "failed to log change %d", entry.Sequence)
+	}
+}
+
+// ReplayWAL applies the changes read from a write-ahead log written by WithWAL,
+// skipping those the searcher already contains, such as the changes made before
+// the snapshot it was loaded from. A last entry cut short, as by a crash in the
+// middle of a write, is ignored. Replayed changes are not logged again. Once
+// replayed, take a new snapshot and start a new log: changes appended after an
+// entry cut short could not be read back. Field values are decoded as by Load.
+//
+// When an entry cannot be decoded or applied, the changes before it are kept,
+// and published with the error: the searcher then holds the log up to the
+// entry, which a later call can resume from.
+// This method is safe for concurrent use.
+func (s *Searcher) ReplayWAL(r io.Reader) error {
+	s.lock()
+	defer s.unlock()
+	defer s.publish()
+
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for {
+		var entry walEntry
+		err := decoder.Decode(&entry)
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to decode change after %d", s.sequence)
+		}
+		if entry.Sequence <= s.sequence {
+			continue
+		}
+		for name, value := range entry.Fields {
+			entry.Fields[name] = floatNumbers(value)
+		}
+
+		if !s.applyChange(entry) {
+			return errors.Newf("unknown operation %q in change %d", entry.Op, entry.Sequence)
+		}
+		s.sequence = entry.Sequence
+	}
+}
+
+// applyChange applies a logged change, reporting false when its operation is
+// unknown. The caller must hold the write lock.
+func (s *Searcher) applyChange(entry walEntry) bool {
+	switch entry.Op {
+	case walAdd:
+		s.addDocument(Document{ID: entry.ID, Fields: entry.Fields})
+	case walRemove:
+		s.removeDocument(entry.ID)
+	case walClear:
+		s.clear()
+	default:
+		return false
+	}
+	return true
+}
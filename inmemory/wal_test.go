@@ -0,0 +1,148 @@
+package inmemory
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReplayWAL(t *testing.T) {
+	var wal, snapshot bytes.Buffer
+	searcher := New(WithWAL(&wal))
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"make": "Toyota"}})
+	searcher.AddDocument(Document{ID: "2", Fields: map[string]interface{}{"make": "Ford"}})
+	if err := searcher.Snapshot(&snapshot); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	// Changes after the snapshot
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"make": "Lexus"}})
+	searcher.RemoveDocument("2")
+	searcher.RemoveDocument("missing")
+	searcher.AddDocument(Document{ID: "3", Fields: map[string]interface{}{"make": "Honda", "serial": int64(9007199254740993)}})
+	if err := searcher.WALErr(); err != nil {
+		t.Fatalf("WAL failed: %v", err)
+	}
+	log := wal.String()
+
+	tests := map[string]struct {
+		snapshot bool
+		wal      string
+		expected map[string]string // make by ID
+	}{
+		"snapshot_and_wal": {
+			snapshot: true,
+			wal:      log,
+			expected: map[string]string{"1": "Lexus", "3": "Honda"},
+		},
+		"wal_only": {
+			wal:      log,
+			expected: map[string]string{"1": "Lexus", "3": "Honda"},
+		},
+		"snapshot_only": {
+			snapshot: true,
+			expected: map[string]string{"1": "Toyota", "2": "Ford"},
+		},
+		"torn_last_entry": {
+			snapshot: true,
+			wal:      log[:len(log)-10],
+			expected: map[string]string{"1": "Lexus"},
+		},
+		"clear": {
+			snapshot: true,
+			wal:      log + `{"seq":6,"op":"clear"}` + "\n",
+			expected: map[string]string{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			restored := New()
+			if tc.snapshot {
+				var err error
+				if restored, err = Load(bytes.NewReader(snapshot.Bytes())); err != nil {
+					t.Fatalf("Load failed: %v", err)
+				}
+			}
+			if err := restored.ReplayWAL(strings.NewReader(tc.wal)); err != nil {
+				t.Fatalf("ReplayWAL failed: %v", err)
+			}
+
+			if restored.Size() != len(tc.expected) {
+				t.Fatalf("Expected %d documents, got %d", len(tc.expected), restored.Size())
+			}
+			for id, want := range tc.expected {
+				idx, ok := restored.idIndex.get(id)
+				if !ok {
+					t.Errorf("Expected document %s", id)
+					continue
+				}
+				if got := restored.documents.get(idx).Fields["make"]; got != want {
+					t.Errorf("Expected document %s to have make %s, got %v", id, want, got)
+				}
+			}
+			if idx, ok := restored.idIndex.get("3"); ok {
+				if serial := restored.documents.get(idx).Fields["serial"]; serial != int64(9007199254740993) {
+					t.Errorf("Expected serial to keep its digits, got %v (%T)", serial, serial)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayWALErrors(t *testing.T) {
+	tests := map[string]struct {
+		wal              string
+		expectedSize     int
+		expectedSequence uint64
+	}{
+		"corrupt_entry": {
+			wal:              `{"seq":1,"op":"add","id":"1"}` + "\n" + `{"seq":2,"op":}` + "\n" + `{"seq":3,"op":"clear"}`,
+			expectedSize:     1,
+			expectedSequence: 1,
+		},
+		"corrupt_last_entry": {
+			wal:              `{"seq":1,"op":"add","id":"1"}` + "\n" + `{"seq":2,"op":"add","id":"2"}` + "\n" + `{"seq":3,"op":"add","id":}` + "\n",
+			expectedSize:     2,
+			expectedSequence: 2,
+		},
+		"unknown_operation": {
+			wal: `{"seq":1,"op":"truncate"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			searcher := New()
+			if err := searcher.ReplayWAL(strings.NewReader(tc.wal)); err == nil {
+				t.Error("Expected error")
+			}
+
+			// The changes before the failing entry are kept and published
+			if searcher.Size() != tc.expectedSize || searcher.current().sequence != tc.expectedSequence {
+				t.Errorf("Expected %d documents up to change %d, got %d up to %d",
+					tc.expectedSize, tc.expectedSequence, searcher.Size(), searcher.current().sequence)
+			}
+		})
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWALWriteError(t *testing.T) {
+	searcher := New(WithWAL(failingWriter{}))
+	searcher.AddDocument(Document{ID: "1", Fields: map[string]interface{}{"make": "Toyota"}})
+
+	if searcher.Size() != 1 {
+		t.Error("Expected the change to be applied")
+	}
+	if err := searcher.WALErr(); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Expected the write error, got %v", err)
+	}
+}
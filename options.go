@@ -18,6 +18,34 @@ type SearchConfig struct {
 
 	// Filters contains filter expressions to apply.
 	Filters []Expression
+
+	// FieldWeights contains per-field weights applied to query matches.
+	FieldWeights map[string]float64
+
+	// Decays contains decay functions applied to the relevance score.
+	Decays []Decay
+
+	// BoostBy contains numeric fields whose values multiply the relevance score.
+	BoostBy []string
+
+	// TypoTolerance enables or disables typo-tolerant matching of query terms.
+	// A nil value leaves the backend's default behaviour in place.
+	TypoTolerance *bool
+
+	// Aggregations contains numeric aggregations computed over the matching documents.
+	Aggregations []AggregationRequest
+
+	// Distinct collapses results sharing a field value when set.
+	Distinct *Distinct
+
+	// Vector requests a nearest-neighbour search on an embedding field when set.
+	Vector *VectorQuery
+
+	// HybridAlpha fuses vector and lexical scores when set, see WithHybrid.
+	HybridAlpha *float64
+
+	// Tenant is the tenant the search runs on behalf of, see NewTenantSearcher.
+	Tenant string
 }
 
 // SortField represents a field to sort by.
@@ -56,3 +84,13 @@ func WithSort(field string, desc bool) SearchOption {
 		cfg.Sort = append(cfg.Sort, SortField{Field: field, Desc: desc})
 	})
 }
+
+// WithTypoTolerance enables or disables typo-tolerant matching of query terms.
+// When enabled, terms of 4 to 7 characters may contain one typo and longer terms two,
+// mirroring Algolia's defaults. Exact matches always score higher than fuzzy ones.
+// Like Algolia, the in-memory searcher tolerates typos unless disabled.
+func WithTypoTolerance(enabled bool) SearchOption {
+	return optionFunc(func(cfg *SearchConfig) {
+		cfg.TypoTolerance = &enabled
+	})
+}
@@ -0,0 +1,136 @@
+package searchx
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Occur describes how a query clause affects matching.
+type Occur string
+
+const (
+	// OccurShould marks an optional clause. Documents must match at least one
+	// optional clause unless the query has required clauses, in which case
+	// optional clauses only improve the ranking.
+	OccurShould Occur = "should"
+	// OccurMust marks a required clause, written +term.
+	OccurMust Occur = "must"
+	// OccurMustNot marks an excluded clause, written -term.
+	OccurMustNot Occur = "must_not"
+)
+
+// QueryClause is a single word or phrase of a parsed query.
+type QueryClause struct {
+	// Text is the word, or the words of a phrase, without syntax characters.
+	Text string
+	// Field restricts the clause to a field when written field:term.
+	// It is empty when the clause applies to all fields.
+	Field string
+	// Phrase is true when the clause was written "exact phrase".
+	Phrase bool
+	// Occur describes whether the clause is optional, required or excluded.
+	Occur Occur
+}
+
+// Query is a free-text query parsed into clauses.
+type Query struct {
+	Clauses []QueryClause
+}
+
+// HasRequired reports whether the query has a required clause.
+func (q Query) HasRequired() bool {
+	for _, clause := range q.Clauses {
+		if clause.Occur == OccurMust {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseQuery parses the free-text query syntax accepted by Searcher.Search.
+//
+// Words are separated by whitespace. Double quotes group words into an exact
+// phrase, and an unterminated quote runs to the end of the query. A leading +
+// marks a word or phrase as required and a leading - excludes it. A field name
+// followed by a colon, as in make:toyota or -model:"model s", restricts the
+// word or phrase to that field. A colon only introduces a field when the
+// field name is made of letters, digits, '_' or '.' and the value starts with
+// a letter, digit or quote, so text such as "https://" stays a plain word.
+// Parsing never fails: anything that is not syntax is kept as text.
+func ParseQuery(query string) Query {
+	var q Query
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		clause := QueryClause{Occur: OccurShould}
+
+		// Occurrence prefix, only when something follows it
+		if (runes[i] == '+' || runes[i] == '-') && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			if runes[i] == '+' {
+				clause.Occur = OccurMust
+			} else {
+				clause.Occur = OccurMustNot
+			}
+			i++
+		}
+
+		// Field prefix
+		if field, n := parseFieldPrefix(runes[i:]); n > 0 {
+			clause.Field = field
+			i += n
+		}
+
+		if runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			clause.Text = strings.Join(strings.Fields(string(runes[i+1:end])), " ")
+			clause.Phrase = true
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+			clause.Text = string(runes[i:end])
+			i = end
+		}
+
+		// Empty phrases such as "" or -"" carry no meaning
+		if clause.Text == "" {
+			continue
+		}
+		q.Clauses = append(q.Clauses, clause)
+	}
+
+	return q
+}
+
+// parseFieldPrefix parses a field name followed by a colon at the start of runes.
+// It returns the field and the number of runes consumed, or zero if there is none.
+func parseFieldPrefix(runes []rune) (string, int) {
+	for i, r := range runes {
+		switch {
+		case r == ':':
+			if i == 0 || i+1 >= len(runes) {
+				return "", 0
+			}
+			next := runes[i+1]
+			if next != '"' && !unicode.IsLetter(next) && !unicode.IsDigit(next) {
+				return "", 0
+			}
+			return string(runes[:i]), i + 1
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.':
+			continue
+		default:
+			return "", 0
+		}
+	}
+	return "", 0
+}
@@ -0,0 +1,45 @@
+package searchx
+
+// Decay describes a function that scales a document's relevance score by how
+// far a field's value lies from an origin, e.g. to favour recent listings.
+type Decay struct {
+	// Field is the name of the numeric or date field to decay on.
+	Field string
+	// Origin is the value at which the score is left untouched.
+	// It can be a number or a time.Time.
+	Origin interface{}
+	// Scale is the distance from Origin at which the score is halved.
+	// It can be a number or a time.Duration.
+	Scale interface{}
+}
+
+// WithFieldWeights sets per-field weights applied to query matches.
+// Fields that are not listed keep a weight of 1.0.
+// Calling it multiple times merges the weights, with later calls taking precedence.
+func WithFieldWeights(weights map[string]float64) SearchOption {
+	return optionFunc(func(cfg *SearchConfig) {
+		if cfg.FieldWeights == nil {
+			cfg.FieldWeights = make(map[string]float64, len(weights))
+		}
+		for field, weight := range weights {
+			cfg.FieldWeights[field] = weight
+		}
+	})
+}
+
+// WithDecay adds a decay function on the given field.
+// The origin can be a number or a time.Time and the scale a number or a time.Duration.
+// A document whose value lies one scale away from the origin has its score halved.
+func WithDecay(field string, origin, scale interface{}) SearchOption {
+	return optionFunc(func(cfg *SearchConfig) {
+		cfg.Decays = append(cfg.Decays, Decay{Field: field, Origin: origin, Scale: scale})
+	})
+}
+
+// WithBoostBy multiplies the relevance score by the value of a numeric field,
+// such as popularity.
+func WithBoostBy(field string) SearchOption {
+	return optionFunc(func(cfg *SearchConfig) {
+		cfg.BoostBy = append(cfg.BoostBy, field)
+	})
+}
@@ -31,4 +31,15 @@ type Results struct {
 
 	// NextOffset can be used for pagination.
 	NextOffset *int
+
+	// Suggestions contains corrected queries when the query matched nothing,
+	// best first. Backends without spelling correction leave it empty.
+	Suggestions []string
+
+	// Aggregations contains the requested numeric aggregations keyed by field.
+	Aggregations map[string]*Aggregation
+
+	// GroupCounts contains the number of matches per value of the distinct
+	// field before collapsing. It is only set when WithDistinct is used.
+	GroupCounts map[string]int64
 }
@@ -0,0 +1,84 @@
+package searchx
+
+// Anchoring describes how a rule pattern is matched against the query.
+type Anchoring string
+
+const (
+	// AnchoringIs matches queries equal to the pattern.
+	AnchoringIs Anchoring = "is"
+	// AnchoringStartsWith matches queries whose first words are the pattern.
+	AnchoringStartsWith Anchoring = "startsWith"
+	// AnchoringEndsWith matches queries whose last words are the pattern.
+	AnchoringEndsWith Anchoring = "endsWith"
+	// AnchoringContains matches queries containing the words of the pattern in order.
+	AnchoringContains Anchoring = "contains"
+)
+
+// Rule is a query rule: when a search meets its condition, its consequence is
+// applied, e.g. to feature sponsored listings for some queries without code changes.
+type Rule struct {
+	// ID is the unique identifier of the rule.
+	ID string
+	// Description explains the purpose of the rule.
+	Description string
+	// Condition decides which searches the rule applies to.
+	Condition RuleCondition
+	// Consequence is applied to the searches meeting the condition.
+	Consequence RuleConsequence
+}
+
+// RuleCondition decides which searches a rule applies to.
+// A condition with neither a pattern nor filters applies to every search.
+type RuleCondition struct {
+	// Pattern is matched word by word against the query, ignoring case.
+	// It is ignored when Anchoring is empty.
+	Pattern string
+	// Anchoring describes how Pattern is matched.
+	Anchoring Anchoring
+	// Filters must all be among the filters of the search for the rule to apply.
+	Filters []Expression
+}
+
+// RuleConsequence describes how a rule changes a search.
+type RuleConsequence struct {
+	// Promote pins documents to positions of the results.
+	Promote []Promotion
+	// Hide contains the IDs of documents removed from the results.
+	Hide []string
+	// Filters are added to the filters of the search.
+	Filters []Expression
+	// Query replaces the query when not nil. The pattern is matched against the
+	// original query.
+	Query *string
+}
+
+// Promotion pins a document to a position of the results.
+type Promotion struct {
+	// ID is the ID of the pinned document.
+	ID string
+	// Position is the zero-based position of the document in the results.
+	Position int
+}
+
+// PinRule creates a rule pinning documents to the first positions of the results
+// of queries containing pattern, in the given order.
+func PinRule(id, pattern string, ids ...string) Rule {
+	promote := make([]Promotion, len(ids))
+	for i, docID := range ids {
+		promote[i] = Promotion{ID: docID, Position: i}
+	}
+	return Rule{
+		ID:          id,
+		Condition:   RuleCondition{Pattern: pattern, Anchoring: AnchoringContains},
+		Consequence: RuleConsequence{Promote: promote},
+	}
+}
+
+// HideRule creates a rule hiding documents from the results of queries containing pattern.
+func HideRule(id, pattern string, ids ...string) Rule {
+	return Rule{
+		ID:          id,
+		Condition:   RuleCondition{Pattern: pattern, Anchoring: AnchoringContains},
+		Consequence: RuleConsequence{Hide: ids},
+	}
+}
@@ -0,0 +1,283 @@
+package searchx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// FieldType is the type of the values of a field.
+type FieldType string
+
+const (
+	// FieldString holds text.
+	FieldString FieldType = "string"
+	// FieldNumber holds integers or floating point numbers.
+	FieldNumber FieldType = "number"
+	// FieldBool holds true or false.
+	FieldBool FieldType = "bool"
+	// FieldTime holds times, see ParseTime.
+	FieldTime FieldType = "time"
+	// FieldGeo holds geographic coordinates. Geo fields cannot be compared in filters.
+	FieldGeo FieldType = "geo"
+)
+
+// Field declares a field of a Schema.
+type Field struct {
+	// Name is the name of the field.
+	Name string `json:"name"`
+	// Type is the type of the values of the field.
+	Type FieldType `json:"type"`
+	// Array is true when the field holds an array of values of Type.
+	Array bool `json:"array,omitempty"`
+	// Searchable fields are matched against the query.
+	Searchable bool `json:"searchable,omitempty"`
+	// Filterable fields can be used in filter expressions.
+	Filterable bool `json:"filterable,omitempty"`
+	// Sortable fields can be used in WithSort.
+	Sortable bool `json:"sortable,omitempty"`
+}
+
+// Schema declares the fields of an index. Searchers configured with a schema
+// reject filters and sorts on fields it does not allow, and coerce filter
+// values to the declared types, so that e.g. a "2018" filter value from a
+// command line matches a numeric year field.
+type Schema struct {
+	// Fields contains the declared fields.
+	Fields []Field `json:"fields"`
+}
+
+// NewSchema creates a schema declaring the given fields.
+func NewSchema(fields ...Field) *Schema {
+	return &Schema{Fields: fields}
+}
+
+// Field returns the declaration of a field.
+func (s *Schema) Field(name string) (Field, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Searchable reports whether a field is declared searchable.
+func (s *Schema) Searchable(name string) bool {
+	f, ok := s.Field(name)
+	return ok && f.Searchable
+}
+
+// Check validates a search configuration against the schema, coercing the
+// values of its filters to the declared field types in place.
+//
+// Filters on undeclared or non-filterable fields, and values that cannot be
+// coerced, return ErrInvalidExpression. Sorts on undeclared or non-sortable
+// fields return ErrInvalidOption.
+func (s *Schema) Check(cfg *SearchConfig) error {
+	filters, err := s.CoerceFilters(cfg.Filters)
+	if err != nil {
+		return err
+	}
+	cfg.Filters = filters
+
+	for _, sort := range cfg.Sort {
+		if sort.Field == "_score" {
+			continue
+		}
+		if f, ok := s.Field(sort.Field); !ok || !f.Sortable {
+			return errors.Wrapf(ErrInvalidOption, "field %q is not sortable", sort.Field)
+		}
+	}
+	return nil
+}
+
+// CoerceFilters returns the expressions with their values coerced to the
+// declared field types. See Check.
+func (s *Schema) CoerceFilters(exprs []Expression) ([]Expression, error) {
+	coerced := make([]Expression, len(exprs))
+	for i, expr := range exprs {
+		c, err := s.coerceExpression(expr)
+		if err != nil {
+			return nil, err
+		}
+		coerced[i] = c
+	}
+	return coerced, nil
+}
+
+// coerceExpression coerces the values of an expression and its children.
+func (s *Schema) coerceExpression(expr Expression) (Expression, error) {
+	switch e := expr.(type) {
+	case AndExpr:
+		exprs, err := s.CoerceFilters(e.Exprs)
+		return AndExpr{Exprs: exprs}, err
+	case OrExpr:
+		exprs, err := s.CoerceFilters(e.Exprs)
+		return OrExpr{Exprs: exprs}, err
+	case NotExpr:
+		inner, err := s.coerceExpression(e.Inner)
+		return NotExpr{Inner: inner}, err
+	case EqExpr:
+		value, err := s.coerceFilterValue(e.Field, e.Value, false)
+		return EqExpr{Field: e.Field, Value: value}, err
+	case NeExpr:
+		value, err := s.coerceFilterValue(e.Field, e.Value, false)
+		return NeExpr{Field: e.Field, Value: value}, err
+	case GtExpr:
+		value, err := s.coerceFilterValue(e.Field, e.Value, true)
+		return GtExpr{Field: e.Field, Value: value}, err
+	case GteExpr:
+		value, err := s.coerceFilterValue(e.Field, e.Value, true)
+		return GteExpr{Field: e.Field, Value: value}, err
+	case LtExpr:
+		value, err := s.coerceFilterValue(e.Field, e.Value, true)
+		return LtExpr{Field: e.Field, Value: value}, err
+	case LteExpr:
+		value, err := s.coerceFilterValue(e.Field, e.Value, true)
+		return LteExpr{Field: e.Field, Value: value}, err
+	case RangeExpr:
+		min, err := s.coerceFilterValue(e.Field, e.Min, true)
+		if err != nil {
+			return nil, err
+		}
+		max, err := s.coerceFilterValue(e.Field, e.Max, true)
+		return RangeExpr{Field: e.Field, Min: min, Max: max}, err
+	case ExistsExpr:
+		_, err := s.filterableField(e.Field)
+		return e, err
+	default:
+		return nil, errors.Wrapf(ErrInvalidExpression, "unsupported expression %T", expr)
+	}
+}
+
+// filterableField returns the declaration of a field used in a filter.
+func (s *Schema) filterableField(name string) (Field, error) {
+	f, ok := s.Field(name)
+	if !ok {
+		return f, errors.Wrapf(ErrInvalidExpression, "field %q is not declared in the schema", name)
+	}
+	if !f.Filterable {
+		return f, errors.Wrapf(ErrInvalidExpression, "field %q is not filterable", name)
+	}
+	return f, nil
+}
+
+// coerceFilterValue coerces a filter value to the type of a field. Ordered
+// comparisons are only allowed on strings, numbers and times.
+func (s *Schema) coerceFilterValue(name string, value interface{}, ordered bool) (interface{}, error) {
+	f, err := s.filterableField(name)
+	if err != nil {
+		return nil, err
+	}
+	if ordered && (f.Type == FieldBool || f.Type == FieldGeo) {
+		return nil, errors.Wrapf(ErrInvalidExpression, "%s field %q cannot be compared", f.Type, name)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	coerced, err := CoerceValue(f.Type, value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "field %q", name)
+	}
+	return coerced, nil
+}
+
+// CoerceValue converts a value to a field type. Strings are parsed for numbers,
+// bools and times, and numbers are taken as Unix epoch seconds for times.
+// Values that cannot be converted return ErrInvalidExpression.
+func CoerceValue(fieldType FieldType, value interface{}) (interface{}, error) {
+	switch fieldType {
+	case FieldString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+		if _, ok := numberValue(value); ok {
+			return fmt.Sprint(value), nil
+		}
+
+	case FieldNumber:
+		if _, ok := numberValue(value); ok {
+			return value, nil
+		}
+		if v, ok := value.(string); ok {
+			v = strings.TrimSpace(v)
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return i, nil
+			}
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f, nil
+			}
+		}
+
+	case FieldBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+
+	case FieldTime:
+		switch v := value.(type) {
+		case time.Time, RelativeTime:
+			return v, nil
+		case string:
+			if t, ok := parseTimeString(strings.TrimSpace(v)); ok {
+				return t, nil
+			}
+		}
+		if seconds, ok := numberValue(value); ok {
+			return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), nil
+		}
+
+	case FieldGeo:
+		return nil, errors.Wrap(ErrInvalidExpression, "geo fields cannot be compared")
+
+	default:
+		return nil, errors.Wrapf(ErrInvalidOption, "unknown field type %q", fieldType)
+	}
+
+	return nil, errors.Wrapf(ErrInvalidExpression, "cannot use %T value %v as %s", value, value, fieldType)
+}
+
+// numberValue converts a value of a Go numeric type to float64.
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,88 @@
+package searchx
+
+import "context"
+
+// Suggestion represents a completion returned by a Suggester.
+type Suggestion struct {
+	// Text is the completed query.
+	Text string
+
+	// Frequency indicates how common the completion is, such as the number of
+	// documents containing it or how often it was searched.
+	Frequency int64
+}
+
+// Suggester defines the autocomplete interface used for type-ahead.
+type Suggester interface {
+	// Suggest returns completions for a prefix, most frequent first.
+	Suggest(ctx context.Context, prefix string, opts ...SuggestOption) ([]Suggestion, error)
+}
+
+// SuggesterFunc is a function type that implements the Suggester interface.
+type SuggesterFunc func(context.Context, string, ...SuggestOption) ([]Suggestion, error)
+
+// Suggest implements the Suggester interface for SuggesterFunc.
+func (f SuggesterFunc) Suggest(ctx context.Context, prefix string, opts ...SuggestOption) ([]Suggestion, error) {
+	return f(ctx, prefix, opts...)
+}
+
+// SuggestOption represents a suggestion configuration option.
+type SuggestOption interface {
+	Apply(*SuggestConfig)
+}
+
+// SuggestConfig holds all suggestion configuration parameters.
+type SuggestConfig struct {
+	// Limit specifies the maximum number of suggestions to return.
+	Limit int
+
+	// Fields restricts suggestions to values of the given fields.
+	// All fields are used when empty.
+	Fields []string
+
+	// Filters restricts suggestions to the values of the documents matching
+	// all the expressions, as the filters of a search do.
+	Filters []Expression
+
+	// Tenant is the tenant suggestions are made on behalf of, see WithSuggestTenant.
+	Tenant string
+}
+
+// suggestOptionFunc is a function that implements SuggestOption.
+type suggestOptionFunc func(*SuggestConfig)
+
+// Apply implements the SuggestOption interface for suggestOptionFunc.
+func (f suggestOptionFunc) Apply(cfg *SuggestConfig) {
+	f(cfg)
+}
+
+// WithSuggestLimit sets the maximum number of suggestions to return.
+func WithSuggestLimit(n int) SuggestOption {
+	return suggestOptionFunc(func(cfg *SuggestConfig) {
+		cfg.Limit = n
+	})
+}
+
+// WithSuggestFields restricts suggestions to values of the given fields.
+func WithSuggestFields(fields ...string) SuggestOption {
+	return suggestOptionFunc(func(cfg *SuggestConfig) {
+		cfg.Fields = append(cfg.Fields, fields...)
+	})
+}
+
+// WithSuggestFilters restricts suggestions to the values of the documents
+// matching all the expressions.
+func WithSuggestFilters(exprs ...Expression) SuggestOption {
+	return suggestOptionFunc(func(cfg *SuggestConfig) {
+		cfg.Filters = append(cfg.Filters, exprs...)
+	})
+}
+
+// WithSuggestTenant sets the tenant suggestions are made on behalf of.
+// It only restricts suggestions when the suggester is wrapped with
+// NewTenantSearcher.
+func WithSuggestTenant(id string) SuggestOption {
+	return suggestOptionFunc(func(cfg *SuggestConfig) {
+		cfg.Tenant = id
+	})
+}
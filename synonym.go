@@ -0,0 +1,46 @@
+package searchx
+
+// SynonymType represents the kind of synonym.
+type SynonymType string
+
+const (
+	// SynonymTwoWay makes all words of the group interchangeable.
+	SynonymTwoWay SynonymType = "synonym"
+	// SynonymOneWay makes the input word also match its synonyms, but not the other way round.
+	SynonymOneWay SynonymType = "oneWaySynonym"
+	// SynonymPlaceholder makes a placeholder token in documents match any of its replacements.
+	SynonymPlaceholder SynonymType = "placeholder"
+)
+
+// Synonym represents an entry of a synonym dictionary applied at query time.
+type Synonym struct {
+	// ID is the unique identifier of the synonym.
+	ID string
+	// Type is the kind of synonym.
+	Type SynonymType
+	// Input is the word expanded by a one-way synonym.
+	Input string
+	// Placeholder is the token that stands in for the replacements in documents,
+	// e.g. "<trim>".
+	Placeholder string
+	// Synonyms contains the interchangeable words for a two-way synonym,
+	// the replacements for a one-way synonym, or the replacements for a placeholder.
+	Synonyms []string
+}
+
+// TwoWaySynonym creates a synonym where all the given words are interchangeable.
+func TwoWaySynonym(id string, words ...string) Synonym {
+	return Synonym{ID: id, Type: SynonymTwoWay, Synonyms: words}
+}
+
+// OneWaySynonym creates a synonym where input also matches the given synonyms,
+// but the synonyms do not match input.
+func OneWaySynonym(id, input string, synonyms ...string) Synonym {
+	return Synonym{ID: id, Type: SynonymOneWay, Input: input, Synonyms: synonyms}
+}
+
+// PlaceholderSynonym creates a synonym where a query for any of the replacements
+// matches documents containing the placeholder token.
+func PlaceholderSynonym(id, placeholder string, replacements ...string) Synonym {
+	return Synonym{ID: id, Type: SynonymPlaceholder, Placeholder: placeholder, Synonyms: replacements}
+}
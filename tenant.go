@@ -0,0 +1,76 @@
+package searchx
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+)
+
+// WithTenant sets the tenant a search runs on behalf of.
+// It only restricts results when the searcher is wrapped with NewTenantSearcher.
+func WithTenant(id string) SearchOption {
+	return optionFunc(func(cfg *SearchConfig) {
+		cfg.Tenant = id
+	})
+}
+
+// TenantSearcher restricts every search and suggestion to the documents of a
+// single tenant.
+type TenantSearcher struct {
+	searcher Searcher
+	field    string
+}
+
+// NewTenantSearcher wraps a searcher so that every search must name its tenant
+// with WithTenant, and only returns documents whose field equals that tenant.
+//
+// The tenant filter is added after the caller's options and combined with their
+// filters using AND, so no caller-supplied expression, query syntax or query
+// rule can widen the results to other tenants.
+//
+// When the searcher also implements Suggester, suggestions must name their
+// tenant with WithSuggestTenant, and only complete values of that tenant's
+// documents, filtered the same way.
+func NewTenantSearcher(searcher Searcher, field string) *TenantSearcher {
+	return &TenantSearcher{searcher: searcher, field: field}
+}
+
+// Search implements the Searcher interface. It returns ErrInvalidOption when
+// the search has no tenant.
+func (t *TenantSearcher) Search(ctx context.Context, query string, opts ...SearchOption) (*Results, error) {
+	cfg := &SearchConfig{}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+	if cfg.Tenant == "" {
+		return nil, errors.Wrap(ErrInvalidOption, "search on a tenant-scoped searcher requires WithTenant")
+	}
+
+	scoped := make([]SearchOption, 0, len(opts)+1)
+	scoped = append(scoped, opts...)
+	scoped = append(scoped, Eq(t.field, cfg.Tenant))
+	return t.searcher.Search(ctx, query, scoped...)
+}
+
+// Suggest implements the Suggester interface. It returns ErrInvalidOption when
+// the suggestion has no tenant, and ErrNotImplemented when the wrapped searcher
+// is not a Suggester.
+func (t *TenantSearcher) Suggest(ctx context.Context, prefix string, opts ...SuggestOption) ([]Suggestion, error) {
+	suggester, ok := t.searcher.(Suggester)
+	if !ok {
+		return nil, errors.Wrapf(ErrNotImplemented, "%T does not implement Suggester", t.searcher)
+	}
+
+	cfg := &SuggestConfig{}
+	for _, opt := range opts {
+		opt.Apply(cfg)
+	}
+	if cfg.Tenant == "" {
+		return nil, errors.Wrap(ErrInvalidOption, "suggestion on a tenant-scoped searcher requires WithSuggestTenant")
+	}
+
+	scoped := make([]SuggestOption, 0, len(opts)+1)
+	scoped = append(scoped, opts...)
+	scoped = append(scoped, WithSuggestFilters(Eq(t.field, cfg.Tenant)))
+	return suggester.Suggest(ctx, prefix, scoped...)
+}
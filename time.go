@@ -0,0 +1,118 @@
+package searchx
+
+import "time"
+
+// RelativeTime is a filter value standing for a time relative to when the search
+// runs, so that saved filters such as "listed in the last 7 days" stay current.
+type RelativeTime struct {
+	// Offset is added to the time of the search. It is negative for past times.
+	Offset time.Duration
+}
+
+// Ago returns the time d before the search runs, e.g.
+// Gte("listed_at", Ago(7*24*time.Hour)) for documents listed in the last 7 days.
+func Ago(d time.Duration) RelativeTime {
+	return RelativeTime{Offset: -d}
+}
+
+// FromNow returns the time d after the search runs.
+func FromNow(d time.Duration) RelativeTime {
+	return RelativeTime{Offset: d}
+}
+
+// Time returns the time relative to now.
+func (r RelativeTime) Time(now time.Time) time.Time {
+	return now.Add(r.Offset)
+}
+
+// InLast creates an expression matching documents whose time field is within
+// the last d, e.g. InLast("listed_at", 7*24*time.Hour).
+func InLast(field string, d time.Duration) Expression {
+	return Gte(field, Ago(d))
+}
+
+// timeLayouts are the layouts of strings holding times: RFC 3339 as found in
+// JSON documents, and dates alone, which stand for midnight UTC.
+var timeLayouts = []string{time.RFC3339Nano, time.DateOnly}
+
+// ParseTime converts a time value to a time.Time. It accepts time.Time,
+// strings in the layouts of timeLayouts, and RelativeTime resolved against now.
+// Other values return false.
+func ParseTime(value interface{}, now time.Time) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, true
+	case RelativeTime:
+		return v.Time(now), true
+	case string:
+		return parseTimeString(v)
+	default:
+		return time.Time{}, false
+	}
+}
+
+// parseTimeString parses a string in one of the layouts of timeLayouts.
+// Every layout starts with a date, which is checked first so that other
+// strings are rejected without parsing.
+func parseTimeString(s string) (time.Time, bool) {
+	if len(s) < len(time.DateOnly) || s[4] != '-' || s[7] != '-' {
+		return time.Time{}, false
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ResolveTimes returns the expressions with every RelativeTime value replaced
+// by the time it stands for at now. Backends call it once per search so that
+// all documents are compared against the same instant.
+func ResolveTimes(exprs []Expression, now time.Time) []Expression {
+	resolved := make([]Expression, len(exprs))
+	for i, expr := range exprs {
+		resolved[i] = resolveTime(expr, now)
+	}
+	return resolved
+}
+
+// resolveTime replaces the RelativeTime values of an expression.
+func resolveTime(expr Expression, now time.Time) Expression {
+	value := func(v interface{}) interface{} {
+		if r, ok := v.(RelativeTime); ok {
+			return r.Time(now)
+		}
+		return v
+	}
+
+	switch e := expr.(type) {
+	case AndExpr:
+		return AndExpr{Exprs: ResolveTimes(e.Exprs, now)}
+	case OrExpr:
+		return OrExpr{Exprs: ResolveTimes(e.Exprs, now)}
+	case NotExpr:
+		return NotExpr{Inner: resolveTime(e.Inner, now)}
+	case EqExpr:
+		return EqExpr{Field: e.Field, Value: value(e.Value)}
+	case NeExpr:
+		return NeExpr{Field: e.Field, Value: value(e.Value)}
+	case GtExpr:
+		return GtExpr{Field: e.Field, Value: value(e.Value)}
+	case GteExpr:
+		return GteExpr{Field: e.Field, Value: value(e.Value)}
+	case LtExpr:
+		return LtExpr{Field: e.Field, Value: value(e.Value)}
+	case LteExpr:
+		return LteExpr{Field: e.Field, Value: value(e.Value)}
+	case RangeExpr:
+		return RangeExpr{Field: e.Field, Min: value(e.Min), Max: value(e.Max)}
+	default:
+		return expr
+	}
+}
@@ -0,0 +1,57 @@
+package searchx
+
+import "context"
+
+// Similarity identifies how vectors are compared.
+type Similarity string
+
+const (
+	// SimilarityCosine compares the angle between vectors, ignoring their length.
+	SimilarityCosine Similarity = "cosine"
+	// SimilarityDotProduct compares vectors by their dot product, for embeddings
+	// that are already normalized or whose length carries meaning.
+	SimilarityDotProduct Similarity = "dot_product"
+)
+
+// VectorSearcher is implemented by backends supporting nearest-neighbour search
+// over embedding fields.
+type VectorSearcher interface {
+	Searcher
+
+	// SearchVector returns the documents nearest to the query vector.
+	// It is equivalent to Search with an empty query and the vector query as an option.
+	SearchVector(ctx context.Context, query VectorQuery, opts ...SearchOption) (*Results, error)
+}
+
+// VectorQuery requests the K documents whose embedding in Field is most similar to Vector.
+// It can be passed directly as a SearchOption.
+type VectorQuery struct {
+	// Field is the name of the embedding field.
+	Field string
+	// Vector is the query embedding.
+	Vector []float32
+	// K is the number of nearest documents to return.
+	K int
+}
+
+// Apply implements the SearchOption interface for VectorQuery.
+func (v VectorQuery) Apply(cfg *SearchConfig) {
+	cfg.Vector = &v
+}
+
+// WithVector performs a k-nearest-neighbour search on an embedding field.
+// Filters and a non-empty query restrict the candidates, and results are ranked
+// by similarity unless WithHybrid is also given.
+func WithVector(field string, vector []float32, k int) SearchOption {
+	return VectorQuery{Field: field, Vector: vector, K: k}
+}
+
+// WithHybrid fuses the vector similarity of WithVector with the lexical score of
+// the query. Results are the union of the k nearest documents and the lexical
+// matches, scored alpha*vector + (1-alpha)*lexical after normalizing both scores
+// to [0, 1]. An alpha of 1 ranks by similarity only and 0 by the query only.
+func WithHybrid(alpha float64) SearchOption {
+	return optionFunc(func(cfg *SearchConfig) {
+		cfg.HybridAlpha = &alpha
+	})
+}